@@ -13,16 +13,38 @@ Usage:
 	giticket -version         will print the version of giticket
 
 	Available Actions:
+	-  assign
+	-  changelog
+	-  check
 	-  comment
+	-  config
 	-  create
 	-  delete
+	-  due
+	-  export
+	-  export-site
+	-  filter
+	-  finish
+	-  format
+	-  hooks
+	-  import
 	-  init
 	-  label
+	-  link
 	-  list
+	-  milestone
 	-  priority
+	-  ref
+	-  scan-commits
+	-  scan-todos
+	-  serve
 	-  severity
 	-  show
+	-  start
+	-  stats
 	-  status
+	-  time
+	-  tui
 */
 package main
 
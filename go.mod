@@ -2,14 +2,17 @@ module github.com/jeffwelling/giticket
 
 go 1.21.6
 
-require github.com/jeffwelling/git2go/v37 v37.0.4
+require (
+	github.com/itchyny/gojq v0.12.16
+	github.com/jeffwelling/git2go/v37 v37.0.4
+	golang.org/x/term v0.20.0
+	gopkg.in/yaml.v2 v2.4.0
+)
 
 require (
-	github.com/itchyny/gojq v0.12.16 // indirect
 	github.com/itchyny/timefmt-go v0.1.6 // indirect
 	golang.org/x/crypto v0.0.0-20201203163018-be400aefbc4c // indirect
 	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/tools v0.5.1-0.20230111220935-a7f7db3f17fc // indirect
 	golang.org/x/tools/cmd/cover v0.1.0-deprecated // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -9,12 +9,17 @@ package cli
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/jeffwelling/giticket/internal/cli/subcommands"
 	"github.com/jeffwelling/giticket/pkg/common"
 )
 
+// noParameterSubcommands are the subcommands that do something useful when
+// given no parameters, rather than printing their help
+var noParameterSubcommands = []string{"init", "list", "due", "scan-commits", "scan-todos", "finish", "serve", "tui", "export", "stats"}
+
 // Exec is the main entry point for giticket CLI. It parses the subcommand name,
 // validates the subcommand, parses the remaining arguments, and calls the
 // subcommand. Help information is printed if --help is passed in as argumnet.
@@ -52,8 +57,8 @@ func Exec() {
 	}
 
 	subcommand := subcommands.Use(subcommand_name)
-	if len(os.Args) <= 2 && subcommand_name != "init" && subcommand_name != "list" {
-		// Every subcommand except init requires one or more parameters
+	if len(os.Args) <= 2 && !slices.Contains(noParameterSubcommands, subcommand_name) {
+		// Every other subcommand requires one or more parameters
 		subcommand.Help()
 		return
 	}
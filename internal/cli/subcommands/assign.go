@@ -0,0 +1,127 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the assign subcommand
+func init() {
+	subcommand := new(SubcommandAssign)
+	registerSubcommand("assign", subcommand)
+}
+
+// SubcommandAssign implements SubcommandInterface and extends it with
+// attributes specific to the assign subcommand
+type SubcommandAssign struct {
+	debugFlag    bool
+	flagset      *flag.FlagSet
+	helpFlag     bool
+	parameters   map[string]interface{}
+	ticketID     int
+	to           string
+	unassignFlag bool
+}
+
+// InitFlags sets up the flags specific to the assign subcommand, parses the
+// flags, and returns any errors
+func (subcommand *SubcommandAssign) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("assign", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "Ticket ID")
+	subcommand.flagset.StringVar(&subcommand.to, "to", "", "Comma separated list of people to assign, as \"Name <email>\", email, or name")
+	subcommand.flagset.BoolVar(&subcommand.unassignFlag, "unassign", false, "Unassign the people given with --to instead")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["to"] = subcommand.to
+	subcommand.parameters["unassignFlag"] = subcommand.unassignFlag
+
+	// Sanity checks
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
+	if len(subcommand.people()) == 0 {
+		return fmt.Errorf("--to must be given with at least one person")
+	}
+
+	return nil
+}
+
+// people returns the people given with --to
+func (subcommand *SubcommandAssign) people() []string {
+	var people []string
+	for _, person := range strings.Split(subcommand.to, ",") {
+		if strings.TrimSpace(person) != "" {
+			people = append(people, strings.TrimSpace(person))
+		}
+	}
+	return people
+}
+
+// Execute assigns or unassigns people when the assign subcommand is used from
+// the CLI
+func (subcommand *SubcommandAssign) Execute() {
+	err := ticket.HandleAssign(
+		common.BranchName,
+		subcommand.people(),
+		subcommand.unassignFlag,
+		subcommand.ticketID,
+		subcommand.debugFlag,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the assign subcommand
+func (subcommand *SubcommandAssign) Help() {
+	fmt.Println("  assign - Assign people to a ticket, or unassign them")
+	fmt.Println("    eg: giticket assign [params]")
+	fmt.Println("    People are given as \"Name <email>\", or as the email address or name of")
+	fmt.Println("    someone who has already committed to the giticket branch, commented,")
+	fmt.Println("    or been assigned a ticket.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --to       \"Name <email>,email,...\"")
+	fmt.Println("      --unassign")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Assign Alice to ticket with ID #1")
+	fmt.Println("        example: giticket assign --id 1 --to \"Alice Smith <alice@example.com>\"")
+	fmt.Println("      - name: Assign Alice to ticket with ID #2 now that giticket knows her")
+	fmt.Println("        example: giticket assign --id 2 --to alice@example.com")
+	fmt.Println("      - name: Unassign Alice from ticket with ID #1")
+	fmt.Println("        example: giticket assign --id 1 --to alice@example.com --unassign")
+	fmt.Println("      - name: List the tickets assigned to you")
+	fmt.Println("        example: giticket list --mine")
+}
+
+// Parameters
+func (subcommand *SubcommandAssign) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandAssign) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,116 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the changelog subcommand
+func init() {
+	subcommand := new(SubcommandChangelog)
+	registerSubcommand("changelog", subcommand)
+}
+
+// SubcommandChangelog implements SubcommandInterface and extends it with
+// attributes specific to the changelog subcommand
+type SubcommandChangelog struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	from       string
+	helpFlag   bool
+	labels     []string
+	parameters map[string]interface{}
+	template   string
+	to         string
+}
+
+// InitFlags sets up the flags specific to the changelog subcommand, parses
+// flags, and returns any errors
+func (subcommand *SubcommandChangelog) InitFlags(args []string) error {
+	var labels string
+	subcommand.flagset = flag.NewFlagSet("changelog", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.from, "from", "", "Tag or commit of the previous release")
+	subcommand.flagset.StringVar(&subcommand.to, "to", "", "Tag or commit of the release, the current commit if not given")
+	subcommand.flagset.StringVar(&labels, "labels", "", "Comma separated labels to make sections for, in order, eg: feature,bugfix,ux")
+	subcommand.flagset.StringVar(&subcommand.template, "template", "", "Go template to render the changelog with, or @name of a stored format")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["from"] = subcommand.from
+	subcommand.parameters["to"] = subcommand.to
+	subcommand.parameters["labels"] = labels
+	subcommand.parameters["template"] = subcommand.template
+
+	// Sanity checks
+	if subcommand.from == "" {
+		return fmt.Errorf("--from is required")
+	}
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			subcommand.labels = append(subcommand.labels, label)
+		}
+	}
+
+	return nil
+}
+
+// Execute writes the changelog when the changelog subcommand is used from the
+// CLI
+func (subcommand *SubcommandChangelog) Execute() {
+	err := ticket.HandleChangelog(os.Stdout, common.BranchName, subcommand.from, subcommand.to, subcommand.labels, subcommand.template, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the changelog subcommand
+func (subcommand *SubcommandChangelog) Help() {
+	fmt.Println("  changelog - Write release notes from the tickets closed in a release")
+	fmt.Println("    eg: giticket changelog [params]")
+	fmt.Println("    Lists the tickets closed between the times of the --from and --to")
+	fmt.Println("    commits, and the tickets referred to by the commits after --from up to")
+	fmt.Println("    --to, grouped by label. The changelog is Markdown, or rendered with a Go")
+	fmt.Println("    template given the fields From, To, FromTime, ToTime, and Sections, each")
+	fmt.Println("    with a Label and Tickets. The functions of list --format can be used,")
+	fmt.Println("    and closed tells whether a ticket is closed.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --from v1.2.0")
+	fmt.Println("      --to v1.3.0")
+	fmt.Println("      --labels feature,bugfix,ux")
+	fmt.Println("      --template '{{range .Sections}}{{range .Tickets}}* {{.Title}}\\n{{end}}{{end}}'")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Write the release notes for v1.3.0")
+	fmt.Println("        example: giticket changelog --from v1.2.0 --to v1.3.0")
+	fmt.Println("      - name: Only make sections for features and bug fixes, in that order")
+	fmt.Println("        example: giticket changelog --from v1.2.0 --labels feature,bugfix")
+}
+
+// Parameters
+func (subcommand *SubcommandChangelog) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandChangelog) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,137 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the check subcommand
+func init() {
+	subcommand := new(SubcommandCheck)
+	registerSubcommand("check", subcommand)
+}
+
+// SubcommandCheck implements SubcommandInterface and extends it with
+// attributes specific to the check subcommand
+type SubcommandCheck struct {
+	action     string
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	item       int
+	parameters map[string]interface{}
+	text       string
+	ticketID   int
+}
+
+// InitFlags sets up the flags specific to the check subcommand, parses the
+// action and flags, and returns any errors
+func (subcommand *SubcommandCheck) InitFlags(args []string) error {
+	// The action comes before the flags, eg: giticket check add --id 1
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.action = args[0]
+		args = args[1:]
+	}
+
+	subcommand.flagset = flag.NewFlagSet("check", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.item, "item", 0, "Position of the checklist item, counting from 1")
+	subcommand.flagset.IntVar(&subcommand.item, "i", 0, "Position of the checklist item, counting from 1")
+	subcommand.flagset.StringVar(&subcommand.text, "text", "", "Text of the checklist item to add")
+	subcommand.flagset.StringVar(&subcommand.text, "t", "", "Text of the checklist item to add")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["action"] = subcommand.action
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["item"] = subcommand.item
+	subcommand.parameters["text"] = subcommand.text
+
+	// Sanity checks
+	if !slices.Contains(ticket.ChecklistActions, subcommand.action) {
+		return fmt.Errorf("an action must be given first, one of: %s", strings.Join(ticket.ChecklistActions, ", "))
+	}
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
+	if subcommand.action == "add" && subcommand.text == "" {
+		return fmt.Errorf("--text must be given when adding a checklist item")
+	}
+	if subcommand.action != "add" && subcommand.item == 0 {
+		return fmt.Errorf("--item must be given to %s a checklist item", subcommand.action)
+	}
+
+	return nil
+}
+
+// Execute changes a ticket's checklist when the check subcommand is used from
+// the CLI
+func (subcommand *SubcommandCheck) Execute() {
+	err := ticket.HandleChecklist(
+		common.BranchName,
+		subcommand.action,
+		subcommand.ticketID,
+		subcommand.item,
+		subcommand.text,
+		subcommand.debugFlag,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the check subcommand
+func (subcommand *SubcommandCheck) Help() {
+	fmt.Println("  check - Manage a ticket's checklist")
+	fmt.Println("    eg: giticket check add|done|undo|remove|promote [params]")
+	fmt.Println("    Items are numbered from 1 in the order shown by giticket show. Promoting")
+	fmt.Println("    an item creates a child ticket for it, and the item is done when that")
+	fmt.Println("    ticket is closed.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --item     | -i 2")
+	fmt.Println("      --text     | -t \"Write the docs\"")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Add an item to the end of ticket 1's checklist")
+	fmt.Println("        example: giticket check add --id 1 --text \"Write the docs\"")
+	fmt.Println("      - name: Insert an item at the top of the checklist")
+	fmt.Println("        example: giticket check add --id 1 --item 1 --text \"Write the tests\"")
+	fmt.Println("      - name: Mark the second item as done, then not done")
+	fmt.Println("        example: giticket check done --id 1 --item 2")
+	fmt.Println("        example: giticket check undo --id 1 --item 2")
+	fmt.Println("      - name: Remove the second item")
+	fmt.Println("        example: giticket check remove --id 1 --item 2")
+	fmt.Println("      - name: Turn the first item into a child ticket")
+	fmt.Println("        example: giticket check promote --id 1 --item 1")
+}
+
+// Parameters
+func (subcommand *SubcommandCheck) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandCheck) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -3,6 +3,7 @@ package subcommands
 import (
 	"flag"
 	"fmt"
+	"strconv"
 
 	"github.com/jeffwelling/giticket/pkg/common"
 	"github.com/jeffwelling/giticket/pkg/ticket"
@@ -60,9 +61,13 @@ func (subcommand *SubcommandComment) InitFlags(args []string) error {
 		common.PrintVersion()
 		fmt.Println("giticket")
 		subcommand.Help()
+		return nil
 	}
 
 	// Sanity check of args
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
 	if subcommand.delete && subcommand.commentID == 0 {
 		fmt.Println("Error: When deleting a comment, the commment ID must be specified")
 		// Print usage
@@ -75,6 +80,20 @@ func (subcommand *SubcommandComment) InitFlags(args []string) error {
 // Execute is used to add a comment when the comment subcommand is used from the
 // CLI
 func (subcommand *SubcommandComment) Execute() {
+	if subcommand.helpFlag {
+		return
+	}
+
+	// Write the comment in the user's editor if it wasn't given as a flag
+	if !subcommand.delete && subcommand.comment == "" {
+		comment, err := ticket.EditText("", "Write your comment for ticket "+strconv.Itoa(subcommand.ticketID)+" above.\nAn empty comment aborts.", subcommand.debugFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		subcommand.comment = comment
+	}
+
 	_, err := ticket.HandleComment(
 		common.BranchName,
 		subcommand.comment,
@@ -108,6 +127,8 @@ func (subcommand *SubcommandComment) Help() {
 	fmt.Println("        example: giticket comment --ticketid 1 --commentid 1 --delete")
 	fmt.Println("      - name: Add a multi-line comment to ticket with ID #1")
 	fmt.Println("        example: giticket comment --ticketid 1 --comment \"This is a multi-line comment. \n        This is a new line in the same comment\"")
+	fmt.Println("      - name: Write a comment on ticket with ID #1 in your editor, see the editor setting")
+	fmt.Println("        example: giticket comment --ticketid 1")
 }
 
 func (subcommand *SubcommandComment) Parameters() map[string]interface{} {
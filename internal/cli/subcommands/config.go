@@ -0,0 +1,143 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the config subcommand
+func init() {
+	subcommand := new(SubcommandConfig)
+	registerSubcommand("config", subcommand)
+}
+
+// SubcommandConfig implements SubcommandInterface and extends it with
+// attributes specific to the config subcommand
+type SubcommandConfig struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	get        string
+	helpFlag   bool
+	listFlag   bool
+	parameters map[string]interface{}
+	scope      string
+	set        string
+	unset      string
+	value      string
+}
+
+// InitFlags sets up the flags for the config subcommand, parses flags, and
+// returns any errors
+func (subcommand *SubcommandConfig) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("config", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.BoolVar(&subcommand.listFlag, "list", false, "List every setting, its value, and where it was set")
+	subcommand.flagset.BoolVar(&subcommand.listFlag, "l", false, "List every setting, its value, and where it was set")
+	subcommand.flagset.StringVar(&subcommand.get, "get", "", "Name of the setting to print")
+	subcommand.flagset.StringVar(&subcommand.set, "set", "", "Name of the setting to set, requires --value")
+	subcommand.flagset.StringVar(&subcommand.unset, "unset", "", "Name of the setting to remove")
+	subcommand.flagset.StringVar(&subcommand.value, "value", "", "Value of the setting to set")
+	subcommand.flagset.StringVar(&subcommand.scope, "scope", ticket.SettingsScopeLocal, "Where to write the setting: local (.git/config), global ($XDG_CONFIG_HOME/giticket/config), or shared (the giticket branch)")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["listFlag"] = subcommand.listFlag
+	subcommand.parameters["get"] = subcommand.get
+	subcommand.parameters["set"] = subcommand.set
+	subcommand.parameters["unset"] = subcommand.unset
+	subcommand.parameters["value"] = subcommand.value
+	subcommand.parameters["scope"] = subcommand.scope
+
+	// Sanity checks
+	actions := 0
+	for _, action := range []bool{subcommand.listFlag, subcommand.get != "", subcommand.set != "", subcommand.unset != ""} {
+		if action {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("exactly one of --list, --get, --set, or --unset must be given")
+	}
+	if subcommand.set != "" && subcommand.value == "" {
+		return fmt.Errorf("--value must be given when using --set, use --unset to remove a setting")
+	}
+	if !slices.Contains(ticket.SettingsScopes, subcommand.scope) {
+		return fmt.Errorf("scope must be one of: %s", strings.Join(ticket.SettingsScopes, ", "))
+	}
+
+	return nil
+}
+
+// Execute lists, prints, sets or removes settings when the config subcommand
+// is used from the CLI
+func (subcommand *SubcommandConfig) Execute() {
+	var err error
+	switch {
+	case subcommand.listFlag:
+		err = ticket.HandleConfigList(os.Stdout, subcommand.debugFlag)
+	case subcommand.get != "":
+		err = ticket.HandleConfigGet(os.Stdout, subcommand.get, subcommand.debugFlag)
+	case subcommand.set != "":
+		err = ticket.HandleConfigSet(subcommand.scope, subcommand.set, subcommand.value, subcommand.debugFlag)
+	case subcommand.unset != "":
+		err = ticket.HandleConfigSet(subcommand.scope, subcommand.unset, "", subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the config subcommand
+func (subcommand *SubcommandConfig) Help() {
+	fmt.Println("  config - View and change settings")
+	fmt.Println("    eg: giticket config [parameters]")
+	fmt.Println("    settings: " + strings.Join(ticket.SettingKeys, ", "))
+	fmt.Println("    Settings are read from, in order of precedence: command line flags,")
+	fmt.Println("    local (.git/config), global ($XDG_CONFIG_HOME/giticket/config), and")
+	fmt.Println("    shared (.giticket/settings.json and .giticket/filters.json on the giticket branch).")
+	fmt.Println("    parameters:")
+	fmt.Println("      --list  | -l")
+	fmt.Println("      --get   \"setting\"")
+	fmt.Println("      --set   \"setting\" --value \"value\"")
+	fmt.Println("      --unset \"setting\"")
+	fmt.Println("      --scope local|global|shared")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: List every setting and where it was set")
+	fmt.Println("        example: giticket config --list")
+	fmt.Println("      - name: List tickets as markdown by default in this repository")
+	fmt.Println("        example: giticket config --set output --value markdown")
+	fmt.Println("      - name: Use vim to write comments in every repository")
+	fmt.Println("        example: giticket config --set editor --value vim --scope global")
+	fmt.Println("      - name: Set the default columns for everyone using this repository")
+	fmt.Println("        example: giticket config --set columns --value \"id,title,priority,status\" --scope shared")
+}
+
+// Parameters
+func (subcommand *SubcommandConfig) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandConfig) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,140 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the due subcommand
+func init() {
+	subcommand := new(SubcommandDue)
+	registerSubcommand("due", subcommand)
+}
+
+// SubcommandDue implements SubcommandInterface and extends it with attributes
+// specific to the due subcommand
+type SubcommandDue struct {
+	clearFlag  bool
+	debugFlag  bool
+	due        int64
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+	ticketID   int
+	within     time.Duration
+}
+
+// InitFlags sets up the flags specific to the due subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandDue) InitFlags(args []string) error {
+	var set, within string
+	subcommand.flagset = flag.NewFlagSet("due", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "Ticket ID")
+	subcommand.flagset.StringVar(&set, "set", "", "Due date to set, eg: 2026-11-01, +3d or friday")
+	subcommand.flagset.BoolVar(&subcommand.clearFlag, "clear", false, "Remove the ticket's due date")
+	subcommand.flagset.StringVar(&within, "within", "", "Only report tickets due within this long, eg: 7d")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["set"] = set
+	subcommand.parameters["clearFlag"] = subcommand.clearFlag
+	subcommand.parameters["within"] = within
+
+	// Sanity checks
+	if subcommand.ticketID == 0 && (set != "" || subcommand.clearFlag) {
+		return fmt.Errorf("ticket ID must be specified to set or clear a due date")
+	}
+	if subcommand.ticketID != 0 && (set == "") == !subcommand.clearFlag {
+		return fmt.Errorf("exactly one of --set or --clear must be given with a ticket ID")
+	}
+	if subcommand.ticketID != 0 && within != "" {
+		return fmt.Errorf("--within cannot be combined with a ticket ID")
+	}
+	if set != "" {
+		var err error
+		subcommand.due, err = ticket.ParseDue(set, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	if within != "" {
+		var err error
+		subcommand.within, err = ticket.ParseDuration(within)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute sets or clears a ticket's due date, or reports the tickets that are
+// due, when the due subcommand is used from the CLI
+func (subcommand *SubcommandDue) Execute() {
+	var err error
+	if subcommand.ticketID != 0 {
+		err = ticket.HandleDue(common.BranchName, subcommand.ticketID, subcommand.due, subcommand.debugFlag)
+	} else {
+		err = ticket.HandleDueReport(os.Stdout, common.BranchName, subcommand.within, subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the due subcommand
+func (subcommand *SubcommandDue) Help() {
+	fmt.Println("  due - Set due dates and report the tickets that are due")
+	fmt.Println("    eg: giticket due [params]")
+	fmt.Println("    Without a ticket ID, lists the open tickets with a due date, soonest first.")
+	fmt.Println("    Due dates given as a day are due at the end of that day. A weekday means")
+	fmt.Println("    the next one after today.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --set 2026-11-01|today|tomorrow|friday|+3d|+2w|+4h")
+	fmt.Println("      --clear")
+	fmt.Println("      --within 7d")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Ticket 1 is due on the first of November")
+	fmt.Println("        example: giticket due --id 1 --set 2026-11-01")
+	fmt.Println("      - name: Ticket 2 is due in three days")
+	fmt.Println("        example: giticket due --id 2 --set +3d")
+	fmt.Println("      - name: Remove ticket 2's due date")
+	fmt.Println("        example: giticket due --id 2 --clear")
+	fmt.Println("      - name: List the tickets that are overdue or due in the next week")
+	fmt.Println("        example: giticket due --within 7d")
+	fmt.Println("      - name: Find overdue tickets with a filter")
+	fmt.Println("        example: giticket filter --name overdue --filter \".[] | select(.Due > 0 and .Due < now)\"")
+}
+
+// Parameters
+func (subcommand *SubcommandDue) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandDue) DebugFlag() bool {
+	return subcommand.debugFlag
+}
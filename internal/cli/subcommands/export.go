@@ -0,0 +1,100 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the export subcommand
+func init() {
+	subcommand := new(SubcommandExport)
+	registerSubcommand("export", subcommand)
+}
+
+// SubcommandExport implements SubcommandInterface and extends it with
+// attributes specific to the export subcommand
+type SubcommandExport struct {
+	debugFlag  bool
+	filter     string
+	flagset    *flag.FlagSet
+	format     string
+	helpFlag   bool
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the export subcommand, parses
+// flags, and returns any errors
+func (subcommand *SubcommandExport) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("export", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.format, "format", "csv", "Format to export as, one of: "+strings.Join(ticket.ExportFormats, ", "))
+	subcommand.flagset.StringVar(&subcommand.filter, "filter", "", "Name of the filter to export the tickets of")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["format"] = subcommand.format
+	subcommand.parameters["filter"] = subcommand.filter
+
+	// Sanity checks
+	if !slices.Contains(ticket.ExportFormats, subcommand.format) {
+		return fmt.Errorf("unknown format '%s', valid formats are: %s", subcommand.format, strings.Join(ticket.ExportFormats, ", "))
+	}
+
+	return nil
+}
+
+// Execute writes the tickets to stdout when the export subcommand is used
+// from the CLI
+func (subcommand *SubcommandExport) Execute() {
+	err := ticket.HandleExport(os.Stdout, common.BranchName, subcommand.format, subcommand.filter, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the export subcommand
+func (subcommand *SubcommandExport) Help() {
+	fmt.Println("  export - Export the tickets as CSV, JSON Lines, or YAML")
+	fmt.Println("    eg: giticket export [params]")
+	fmt.Println("    CSV has the columns that can be imported with giticket import --format csv,")
+	fmt.Println("    so a spreadsheet can be edited and imported again. JSON Lines and YAML")
+	fmt.Println("    have every field of the tickets.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --format csv|jsonl|yaml")
+	fmt.Println("      --filter open")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Export every ticket to a spreadsheet")
+	fmt.Println("        example: giticket export --format csv > tickets.csv")
+	fmt.Println("      - name: Export the tickets of the filter named open as JSON Lines")
+	fmt.Println("        example: giticket export --format jsonl --filter open > open.jsonl")
+}
+
+// Parameters
+func (subcommand *SubcommandExport) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandExport) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,93 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the export-site subcommand
+func init() {
+	subcommand := new(SubcommandExportSite)
+	registerSubcommand("export-site", subcommand)
+}
+
+// SubcommandExportSite implements SubcommandInterface and extends it with
+// attributes specific to the export-site subcommand
+type SubcommandExportSite struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	outDir     string
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the export-site subcommand, parses
+// flags, and returns any errors
+func (subcommand *SubcommandExportSite) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("export-site", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.outDir, "out", "", "Directory to write the site to")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["outDir"] = subcommand.outDir
+
+	return nil
+}
+
+// Execute writes the static site when the export-site subcommand is used from
+// the CLI
+func (subcommand *SubcommandExportSite) Execute() {
+	if subcommand.outDir == "" {
+		fmt.Println("--out is required")
+		subcommand.Help()
+		return
+	}
+	err := ticket.HandleExportSite(os.Stdout, common.BranchName, subcommand.outDir, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the export-site subcommand
+func (subcommand *SubcommandExportSite) Help() {
+	fmt.Println("  export-site - Export the tickets as a static HTML site")
+	fmt.Println("    eg: giticket export-site [params]")
+	fmt.Println("    Writes an index of the tickets, a page for each saved filter, label,")
+	fmt.Println("    milestone, and ticket, and a search page. The same tickets always produce")
+	fmt.Println("    the same files, so the site can be committed and diffed. The directory")
+	fmt.Println("    must be empty, or hold a site exported before, which is replaced.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --out ./site")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Export the tickets to ./site")
+	fmt.Println("        example: giticket export-site --out ./site")
+}
+
+// Parameters
+func (subcommand *SubcommandExportSite) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandExportSite) DebugFlag() bool {
+	return subcommand.debugFlag
+}
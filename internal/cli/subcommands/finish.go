@@ -0,0 +1,98 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the finish subcommand
+func init() {
+	subcommand := new(SubcommandFinish)
+	registerSubcommand("finish", subcommand)
+}
+
+// SubcommandFinish implements SubcommandInterface and extends it with
+// attributes specific to the finish subcommand
+type SubcommandFinish struct {
+	closeFlag  bool
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+	ticketID   int
+}
+
+// InitFlags sets up the flags specific to the finish subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandFinish) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("finish", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "ID of the ticket to finish, default is the ticket the current branch is named after")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "ID of the ticket to finish, default is the ticket the current branch is named after")
+	subcommand.flagset.BoolVar(&subcommand.closeFlag, "close", false, "Close the ticket rather than moving it to review")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["closeFlag"] = subcommand.closeFlag
+
+	return nil
+}
+
+// Execute moves the ticket being worked on to review, or closes it, when the
+// finish subcommand is used from the CLI
+func (subcommand *SubcommandFinish) Execute() {
+	if subcommand.helpFlag {
+		return
+	}
+
+	err := ticket.HandleFinish(os.Stdout, common.BranchName, subcommand.ticketID, subcommand.closeFlag, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the finish subcommand
+func (subcommand *SubcommandFinish) Help() {
+	fmt.Println("  finish - Finish work on a ticket")
+	fmt.Println("    eg: giticket finish [params]")
+	fmt.Println("    Moves the ticket the current branch is named after, such as 12 for")
+	fmt.Println("    12-fix-the-login-page, to the reviewStatus setting, \"" + ticket.DefaultReviewStatus + "\" by default.")
+	fmt.Println("    See also: giticket start")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 12")
+	fmt.Println("      --close")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Move the ticket for the current branch to review")
+	fmt.Println("        example: giticket finish")
+	fmt.Println("      - name: Close ticket 12")
+	fmt.Println("        example: giticket finish --id 12 --close")
+}
+
+// Parameters
+func (subcommand *SubcommandFinish) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandFinish) DebugFlag() bool {
+	return subcommand.debugFlag
+}
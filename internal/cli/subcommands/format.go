@@ -0,0 +1,132 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the format subcommand
+func init() {
+	subcommand := new(SubcommandFormat)
+	registerSubcommand("format", subcommand)
+}
+
+// SubcommandFormat implements SubcommandInterface and extends it with
+// attributes specific to the format subcommand
+type SubcommandFormat struct {
+	debugFlag  bool
+	deleteFlag bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	listFlag   bool
+	name       string
+	parameters map[string]interface{}
+	template   string
+}
+
+// InitFlags sets up the flags for the format subcommand, parses flags, and
+// returns any errors
+func (subcommand *SubcommandFormat) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("format", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.BoolVar(&subcommand.deleteFlag, "delete", false, "Delete the named format")
+	subcommand.flagset.BoolVar(&subcommand.deleteFlag, "d", false, "Delete the named format")
+	subcommand.flagset.BoolVar(&subcommand.listFlag, "list", false, "List stored formats")
+	subcommand.flagset.BoolVar(&subcommand.listFlag, "l", false, "List stored formats")
+	subcommand.flagset.StringVar(&subcommand.name, "name", "", "Name of the format")
+	subcommand.flagset.StringVar(&subcommand.template, "template", "", "Go template to store")
+	subcommand.flagset.StringVar(&subcommand.template, "t", "", "Go template to store")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["deleteFlag"] = subcommand.deleteFlag
+	subcommand.parameters["listFlag"] = subcommand.listFlag
+	subcommand.parameters["name"] = subcommand.name
+	subcommand.parameters["template"] = subcommand.template
+
+	// Sanity checks
+	if subcommand.listFlag && (subcommand.deleteFlag || subcommand.name != "" || subcommand.template != "") {
+		return fmt.Errorf("--list cannot be combined with other parameters")
+	}
+	if subcommand.deleteFlag && (subcommand.name == "" || subcommand.template != "") {
+		return fmt.Errorf("--delete requires --name and cannot be combined with --template")
+	}
+	if !subcommand.listFlag && !subcommand.deleteFlag && (subcommand.name == "" || subcommand.template == "") {
+		return fmt.Errorf("--name and --template must be set if not deleting or listing formats")
+	}
+
+	return nil
+}
+
+// Execute lists, saves or deletes stored formats when the format subcommand is
+// used from the CLI
+func (subcommand *SubcommandFormat) Execute() {
+	var err error
+	switch {
+	case subcommand.listFlag:
+		err = ticket.HandleFormatList(os.Stdout, subcommand.debugFlag)
+	case subcommand.deleteFlag:
+		err = ticket.HandleFormatDelete(subcommand.name, subcommand.debugFlag)
+	default:
+		err = ticket.HandleFormatSave(subcommand.name, subcommand.template, subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the format subcommand
+func (subcommand *SubcommandFormat) Help() {
+	fmt.Println("  format - Store Go templates for use with list --format and show --format")
+	fmt.Println("    eg: giticket format [parameters]")
+	fmt.Println("    Templates are Go text/templates executed once for each ticket, with the")
+	fmt.Println("    ticket's fields such as .ID, .Title, .Status, .Labels and .Comments.")
+	fmt.Println("    \\t and \\n outside of {{ }} are printed as a tab and a newline.")
+	fmt.Println("    functions:")
+	fmt.Println("      join .Labels \",\"        truncate .Title 20     pad .Status 10")
+	fmt.Println("      upper .Title            lower .Title           default .Status \"-\"")
+	fmt.Println("      date .Created           datetime .Created      timeFormat .Created \"Jan 2\"")
+	fmt.Println("      ago .Created            json .Labels")
+	fmt.Println("    parameters:")
+	fmt.Println("      --name     \"name\"")
+	fmt.Println("      --template | -t '{{.ID}}\\t{{.Title}}'")
+	fmt.Println("      --delete   | -d")
+	fmt.Println("      --list     | -l")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Store a format named \"short\"")
+	fmt.Println("        example: giticket format --name short --template '{{.ID}}\\tP{{.Priority}}\\t{{truncate .Title 40}}'")
+	fmt.Println("      - name: List tickets with the stored format")
+	fmt.Println("        example: giticket list --format @short")
+	fmt.Println("      - name: List stored formats")
+	fmt.Println("        example: giticket format --list")
+	fmt.Println("      - name: Delete the stored format named \"short\"")
+	fmt.Println("        example: giticket format --delete --name short")
+}
+
+// Parameters
+func (subcommand *SubcommandFormat) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandFormat) DebugFlag() bool {
+	return subcommand.debugFlag
+}
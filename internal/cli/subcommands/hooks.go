@@ -0,0 +1,152 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the hooks subcommand
+func init() {
+	subcommand := new(SubcommandHooks)
+	registerSubcommand("hooks", subcommand)
+}
+
+// SubcommandHooks implements SubcommandInterface and extends it with
+// attributes specific to the hooks subcommand
+type SubcommandHooks struct {
+	action     string
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	hook       string
+	hookArgs   []string
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the hooks subcommand, parses the
+// action, flags, and the hook name and arguments given to run, and returns any
+// errors
+func (subcommand *SubcommandHooks) InitFlags(args []string) error {
+	// The action comes before the flags, eg: giticket hooks install --debug
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.action = args[0]
+		args = args[1:]
+	}
+
+	subcommand.flagset = flag.NewFlagSet("hooks", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	// run is followed by the name of the hook and the arguments git gave it
+	rest := subcommand.flagset.Args()
+	if len(rest) > 0 {
+		subcommand.hook = rest[0]
+		subcommand.hookArgs = rest[1:]
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["action"] = subcommand.action
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["hook"] = subcommand.hook
+	subcommand.parameters["hookArgs"] = subcommand.hookArgs
+
+	// Sanity checks
+	if !slices.Contains(ticket.HookActions, subcommand.action) {
+		return fmt.Errorf("an action must be given first, one of: %s", strings.Join(ticket.HookActions, ", "))
+	}
+	if subcommand.action != "run" && len(rest) > 0 {
+		return fmt.Errorf("%s doesn't accept arguments", subcommand.action)
+	}
+	if subcommand.action == "run" {
+		if !slices.Contains(ticket.HookNames, subcommand.hook) {
+			return fmt.Errorf("the name of a hook must follow run, one of: %s", strings.Join(ticket.HookNames, ", "))
+		}
+		if subcommand.hook != "post-commit" && len(subcommand.hookArgs) == 0 {
+			return fmt.Errorf("%s must be given the path of the commit message file", subcommand.hook)
+		}
+	}
+
+	return nil
+}
+
+// Execute installs, uninstalls, or runs the giticket git hooks when the hooks
+// subcommand is used from the CLI. When running a hook, errors are printed to
+// stderr and giticket exits with a non-zero status so git stops the commit.
+func (subcommand *SubcommandHooks) Execute() {
+	var err error
+	switch subcommand.action {
+	case "install":
+		err = ticket.HandleHooksInstall(os.Stdout, subcommand.debugFlag)
+	case "uninstall":
+		err = ticket.HandleHooksUninstall(os.Stdout, subcommand.debugFlag)
+	case "run":
+		switch subcommand.hook {
+		case "commit-msg":
+			err = ticket.HandleHookCommitMsg(common.BranchName, subcommand.hookArgs[0], subcommand.debugFlag)
+		case "prepare-commit-msg":
+			source := ""
+			if len(subcommand.hookArgs) > 1 {
+				source = subcommand.hookArgs[1]
+			}
+			err = ticket.HandleHookPrepareCommitMsg(subcommand.hookArgs[0], source, subcommand.debugFlag)
+		case "post-commit":
+			err = ticket.HandleHookPostCommit(os.Stdout, common.BranchName, subcommand.debugFlag)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "giticket: "+err.Error())
+			os.Exit(1)
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the hooks subcommand
+func (subcommand *SubcommandHooks) Help() {
+	fmt.Println("  hooks - Install git hooks that connect commits to tickets")
+	fmt.Println("    eg: giticket hooks install|uninstall [params]")
+	fmt.Println("    The hooks are installed in .git/hooks, or core.hooksPath if it is set:")
+	fmt.Println("      commit-msg          stops commits that refer to tickets that don't exist")
+	fmt.Println("      prepare-commit-msg  adds \"Ticket: 12\" to the message on a branch named")
+	fmt.Println("                          after a ticket, such as feature/12-login")
+	fmt.Println("      post-commit         comments on the tickets the commit refers to")
+	fmt.Println("    Hooks that already exist are kept and run before giticket's, and are put")
+	fmt.Println("    back by uninstall. The hooks call giticket hooks run, which is not meant to")
+	fmt.Println("    be used directly. See scan-commits for how commits refer to tickets.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Install the hooks")
+	fmt.Println("        example: giticket hooks install")
+	fmt.Println("      - name: Remove the hooks")
+	fmt.Println("        example: giticket hooks uninstall")
+}
+
+// Parameters
+func (subcommand *SubcommandHooks) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandHooks) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,185 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the import subcommand
+func init() {
+	subcommand := new(SubcommandImport)
+	registerSubcommand("import", subcommand)
+}
+
+// SubcommandImport implements SubcommandInterface and extends it with
+// attributes specific to the import subcommand
+type SubcommandImport struct {
+	branch     string
+	columnMap  map[string]string
+	debugFlag  bool
+	dryRun     bool
+	file       string
+	flagset    *flag.FlagSet
+	format     string
+	helpFlag   bool
+	parameters map[string]interface{}
+	source     string
+}
+
+// InitFlags sets up the flags specific to the import subcommand, parses the
+// source or format and flags, and returns any errors
+func (subcommand *SubcommandImport) InitFlags(args []string) error {
+	// The source comes before the flags, eg: giticket import github --file issues.json
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.source = args[0]
+		args = args[1:]
+	}
+
+	subcommand.flagset = flag.NewFlagSet("import", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.file, "file", "", "File exported from the tracker to import")
+	subcommand.flagset.StringVar(&subcommand.branch, "branch", "", "Branch ticgit keeps its tickets in, found if not given")
+	subcommand.flagset.StringVar(&subcommand.format, "format", "", "Format of a file to import in bulk, one of: "+strings.Join(ticket.BulkImportFormats, ", "))
+	var mappings string
+	subcommand.flagset.StringVar(&mappings, "map", "", "Comma separated mappings of the file's columns to giticket's, eg: Summary=title,Owner=assignees")
+	subcommand.flagset.BoolVar(&subcommand.dryRun, "dry-run", false, "Print what would change without committing it")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["source"] = subcommand.source
+	subcommand.parameters["file"] = subcommand.file
+	subcommand.parameters["branch"] = subcommand.branch
+	subcommand.parameters["format"] = subcommand.format
+	subcommand.parameters["map"] = mappings
+	subcommand.parameters["dryRun"] = subcommand.dryRun
+
+	// Sanity checks
+	var err error
+	subcommand.columnMap, err = ticket.ParseColumnMap(mappings)
+	if err != nil {
+		return err
+	}
+	if subcommand.format != "" {
+		if subcommand.source != "" {
+			return fmt.Errorf("give either a source or --format, not both")
+		}
+		if !slices.Contains(ticket.BulkImportFormats, subcommand.format) {
+			return fmt.Errorf("unknown format '%s', valid formats are: %s", subcommand.format, strings.Join(ticket.BulkImportFormats, ", "))
+		}
+		if subcommand.file == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if subcommand.branch != "" {
+			return fmt.Errorf("--branch is only used for sources in the repository")
+		}
+		return nil
+	}
+	if mappings != "" {
+		return fmt.Errorf("--map is only used with --format")
+	}
+	if !slices.Contains(ticket.ImportSources(), subcommand.source) {
+		return fmt.Errorf("a source must be given first, one of: %s, or a format with --format", strings.Join(ticket.ImportSources(), ", "))
+	}
+	if ticket.ImportFromRepository(subcommand.source) {
+		if subcommand.file != "" {
+			return fmt.Errorf("%s tickets are read from the repository, use --branch instead of --file", subcommand.source)
+		}
+	} else {
+		if subcommand.file == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if subcommand.branch != "" {
+			return fmt.Errorf("--branch is only used for sources in the repository")
+		}
+	}
+
+	return nil
+}
+
+// Execute imports the tickets when the import subcommand is used from the CLI
+func (subcommand *SubcommandImport) Execute() {
+	if subcommand.format != "" {
+		err := ticket.HandleBulkImport(os.Stdout, common.BranchName, subcommand.format, subcommand.file, subcommand.columnMap, subcommand.dryRun, subcommand.debugFlag)
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	from := subcommand.file
+	if ticket.ImportFromRepository(subcommand.source) {
+		from = subcommand.branch
+	}
+	err := ticket.HandleImport(os.Stdout, common.BranchName, subcommand.source, from, subcommand.dryRun, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the import subcommand
+func (subcommand *SubcommandImport) Help() {
+	fmt.Println("  import - Import tickets from another tracker")
+	fmt.Println("    eg: giticket import github|ticgit [params]")
+	fmt.Println("        giticket import --format csv|jsonl [params]")
+	fmt.Println("    Creates a ticket for each ticket in the other tracker, and records the ID")
+	fmt.Println("    it had there so importing again updates the tickets that changed instead")
+	fmt.Println("    of creating them again. Milestones the tickets are planned for are created")
+	fmt.Println("    if they don't exist. The whole import is a single commit. Anything that")
+	fmt.Println("    couldn't be imported is reported.")
+	fmt.Println("    With --format, each row of a CSV or JSON Lines file, such as one written")
+	fmt.Println("    by export, updates the ticket with its id, or its external_id if a ticket")
+	fmt.Println("    has it, or creates a ticket. Only the columns in the file are changed.")
+	fmt.Println("    Empty title, status, priority, severity, and created cells are ignored.")
+	fmt.Println("    JSON Lines keys are the same columns, and the other fields of an export,")
+	fmt.Println("    such as comments, are ignored. Each ticket can only be in one row. If any")
+	fmt.Println("    row is invalid, the errors in every row are printed and nothing is")
+	fmt.Println("    imported.")
+	fmt.Println("    sources:")
+	fmt.Println("      github - a JSON array of issues, from the REST API or from")
+	fmt.Println("               gh issue list --state all --json number,title,body,state,author,labels,assignees,milestone,createdAt,comments")
+	fmt.Println("      ticgit - the tickets of ticgit or ticgit-ng in this repository, with their")
+	fmt.Println("               states, tags, comments, and assignments")
+	fmt.Println("    parameters:")
+	fmt.Println("      --file issues.json")
+	fmt.Println("      --branch ticgit")
+	fmt.Println("      --format csv")
+	fmt.Println("      --map Summary=title,Owner=assignees,Notes=")
+	fmt.Println("      --dry-run")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Import the issues in issues.json from GitHub")
+	fmt.Println("        example: giticket import github --file issues.json")
+	fmt.Println("      - name: Migrate from ticgit or ticgit-ng")
+	fmt.Println("        example: giticket import ticgit")
+	fmt.Println("      - name: See what a spreadsheet would change, with its Summary column as the titles")
+	fmt.Println("        example: giticket import --format csv --file work.csv --map Summary=title --dry-run")
+}
+
+// Parameters
+func (subcommand *SubcommandImport) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandImport) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,147 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the link subcommand
+func init() {
+	subcommand := new(SubcommandLink)
+	registerSubcommand("link", subcommand)
+}
+
+// SubcommandLink implements SubcommandInterface and extends it with
+// attributes specific to the link subcommand
+type SubcommandLink struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	linkType   string
+	links      map[string]*string
+	parameters map[string]interface{}
+	targetIDs  []int
+	ticketID   int
+	unlinkFlag bool
+}
+
+// InitFlags sets up the flags specific to the link subcommand, parses the
+// flags, and returns any errors
+func (subcommand *SubcommandLink) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("link", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "Ticket ID")
+	subcommand.flagset.BoolVar(&subcommand.unlinkFlag, "unlink", false, "Remove the link instead of adding it")
+
+	// One flag for each type of link, each taking the IDs of the tickets to
+	// link to
+	subcommand.links = make(map[string]*string)
+	for _, linkType := range ticket.LinkTypeNames {
+		subcommand.links[linkType] = subcommand.flagset.String(linkType, "", "IDs of the tickets to link to as "+linkType)
+	}
+	// --parent and --child read more naturally than --child-of and --parent-of
+	subcommand.flagset.StringVar(subcommand.links["child-of"], "parent", "", "ID of this ticket's parent")
+	subcommand.flagset.StringVar(subcommand.links["parent-of"], "child", "", "IDs of this ticket's children")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["unlinkFlag"] = subcommand.unlinkFlag
+
+	// Sanity checks
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
+	for _, linkType := range ticket.LinkTypeNames {
+		if *subcommand.links[linkType] == "" {
+			continue
+		}
+		if subcommand.linkType != "" {
+			return fmt.Errorf("only one type of link can be given at a time")
+		}
+		targetIDs, err := ticket.ParseTicketIDs(*subcommand.links[linkType])
+		if err != nil {
+			return err
+		}
+		subcommand.linkType = linkType
+		subcommand.targetIDs = targetIDs
+		subcommand.parameters[linkType] = *subcommand.links[linkType]
+	}
+	if subcommand.linkType == "" {
+		return fmt.Errorf("a type of link must be given, eg: --blocks 9")
+	}
+
+	return nil
+}
+
+// Execute links or unlinks tickets when the link subcommand is used from the
+// CLI
+func (subcommand *SubcommandLink) Execute() {
+	err := ticket.HandleLink(
+		common.BranchName,
+		subcommand.ticketID,
+		subcommand.linkType,
+		subcommand.targetIDs,
+		subcommand.unlinkFlag,
+		subcommand.debugFlag,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the link subcommand
+func (subcommand *SubcommandLink) Help() {
+	fmt.Println("  link - Link tickets to each other, or unlink them")
+	fmt.Println("    eg: giticket link --id N --{type} N[,N-M...] [params]")
+	fmt.Println("    The reverse link is added to the other ticket in the same commit, so")
+	fmt.Println("    linking 12 --blocks 9 also marks ticket 9 as blocked-by 12. Links that")
+	fmt.Println("    would make a ticket block itself, or be its own ancestor, are refused.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --blocks        | --blocked-by    N")
+	fmt.Println("      --depends-on    | --required-by   N")
+	fmt.Println("      --duplicates    | --duplicated-by N")
+	fmt.Println("      --parent-of     | --child         N")
+	fmt.Println("      --child-of      | --parent        N")
+	fmt.Println("      --unlink")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Ticket 12 blocks ticket 9")
+	fmt.Println("        example: giticket link --id 12 --blocks 9")
+	fmt.Println("      - name: Ticket 15 is a duplicate of ticket 3")
+	fmt.Println("        example: giticket link --id 15 --duplicates 3")
+	fmt.Println("      - name: Tickets 4 to 6 are children of ticket 2")
+	fmt.Println("        example: giticket link --id 2 --child 4-6")
+	fmt.Println("      - name: Ticket 12 no longer blocks ticket 9")
+	fmt.Println("        example: giticket link --id 12 --blocks 9 --unlink")
+	fmt.Println("      - name: List the tickets that aren't blocked")
+	fmt.Println("        example: giticket list --unblocked")
+}
+
+// Parameters
+func (subcommand *SubcommandLink) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandLink) DebugFlag() bool {
+	return subcommand.debugFlag
+}
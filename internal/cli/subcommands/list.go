@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/jeffwelling/giticket/pkg/common"
 	"github.com/jeffwelling/giticket/pkg/ticket"
@@ -18,12 +20,22 @@ func init() {
 // SubcommandList implements SubcommandInterface and extends it with
 // attributes common to the list subcommand
 type SubcommandList struct {
+	columns     string
 	debugFlag   bool
 	flagset     *flag.FlagSet
 	filter      string
+	format      string
 	filterSet   bool
+	groupBy     string
 	helpFlag    bool
+	limit       int
+	listColumns []string
+	mineFlag    bool
+	unblocked   bool
+	offset      int
+	output      string
 	parameters  map[string]interface{}
+	sortBy      string
 	windowWidth int
 }
 
@@ -39,11 +51,21 @@ func (subcommand *SubcommandList) InitFlags(args []string) error {
 
 	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
 	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
-	subcommand.flagset.IntVar(&subcommand.windowWidth, "window", 0, "Window width")
-	subcommand.flagset.IntVar(&subcommand.windowWidth, "w", 0, "Window width")
+	subcommand.flagset.IntVar(&subcommand.windowWidth, "window", 0, "Window width, defaults to the width of the terminal")
+	subcommand.flagset.IntVar(&subcommand.windowWidth, "w", 0, "Window width, defaults to the width of the terminal")
 	subcommand.flagset.StringVar(&subcommand.filter, "filter", "", "The filter name to use for listing tickets with")
 	subcommand.flagset.StringVar(&subcommand.filter, "f", "", "The filter name to use for listing tickets with")
-	subcommand.flagset.BoolVar(&subcommand.filterSet, "set-filter", false, "Requires the filter name parameter. If true, save the name of the filter as the default filter to use for future list operations.")
+	subcommand.flagset.BoolVar(&subcommand.filterSet, "set-filter", false, "Requires the filter name parameter. If true, save the name of the filter as your default filter to use for future list operations in this repository.")
+	subcommand.flagset.StringVar(&subcommand.sortBy, "sort", "", "Comma separated list of keys to sort by, prefix a key with '-' to sort descending")
+	subcommand.flagset.StringVar(&subcommand.groupBy, "group-by", "", "Group tickets by status, label, severity, assignee, or milestone")
+	subcommand.flagset.BoolVar(&subcommand.mineFlag, "mine", false, "Only list tickets assigned to you")
+	subcommand.flagset.BoolVar(&subcommand.unblocked, "unblocked", false, "Only list tickets that aren't blocked by an open ticket")
+	subcommand.flagset.IntVar(&subcommand.limit, "limit", 0, "Maximum number of tickets to list")
+	subcommand.flagset.IntVar(&subcommand.offset, "offset", 0, "Number of tickets to skip before listing")
+	subcommand.flagset.StringVar(&subcommand.columns, "columns", "", "Comma separated list of columns to print, defaults to the columns setting")
+	subcommand.flagset.StringVar(&subcommand.output, "output", "", "Output format: table, json, yaml, csv, tsv, or markdown, defaults to the output setting or table")
+	subcommand.flagset.StringVar(&subcommand.output, "o", "", "Output format: table, json, yaml, csv, tsv, or markdown, defaults to the output setting or table")
+	subcommand.flagset.StringVar(&subcommand.format, "format", "", "Go template to print each ticket with, or @name of a stored format")
 	if err := subcommand.flagset.Parse(args); err != nil {
 		return err
 	}
@@ -53,15 +75,67 @@ func (subcommand *SubcommandList) InitFlags(args []string) error {
 		return fmt.Errorf("filter name is required when using the --set-filter flag")
 	}
 
+	if subcommand.limit < 0 || subcommand.offset < 0 {
+		return fmt.Errorf("limit and offset cannot be negative")
+	}
+
+	// Sanity check the columns and output format before touching the repo,
+	// when they aren't given the user's settings are used instead
+	if subcommand.columns != "" {
+		var err error
+		subcommand.listColumns, err = ticket.ParseListColumns(subcommand.columns)
+		if err != nil {
+			return err
+		}
+	}
+	if subcommand.output != "" && !slices.Contains(ticket.ListOutputFormats, subcommand.output) {
+		return fmt.Errorf("output format must be one of: %s", strings.Join(ticket.ListOutputFormats, ", "))
+	}
+
+	if subcommand.format != "" && (subcommand.columns != "" || subcommand.output != "" || subcommand.groupBy != "") {
+		return fmt.Errorf("--format cannot be combined with --columns, --output, or --group-by")
+	}
+	if subcommand.format != "" && !strings.HasPrefix(subcommand.format, "@") {
+		_, err := ticket.ParseFormat(subcommand.format)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Fit the table to the terminal unless told otherwise
+	if subcommand.windowWidth == 0 {
+		subcommand.windowWidth = common.TerminalWidth()
+	}
+
 	subcommand.parameters["debugFlag"] = debugFlag
 	subcommand.parameters["helpFlag"] = helpFlag
 	subcommand.parameters["windowWidth"] = window
+	subcommand.parameters["sortBy"] = subcommand.sortBy
+	subcommand.parameters["groupBy"] = subcommand.groupBy
+	subcommand.parameters["mineFlag"] = subcommand.mineFlag
+	subcommand.parameters["unblocked"] = subcommand.unblocked
+	subcommand.parameters["limit"] = subcommand.limit
+	subcommand.parameters["offset"] = subcommand.offset
+	subcommand.parameters["columns"] = subcommand.listColumns
+	subcommand.parameters["output"] = subcommand.output
+	subcommand.parameters["format"] = subcommand.format
 	return nil
 }
 
 // Execute is used to list tickets when the user uses the list subcommand from the CLI
 func (subcommand *SubcommandList) Execute() {
-	err := ticket.HandleList(os.Stdout, subcommand.windowWidth, common.BranchName, subcommand.filter, subcommand.filterSet, subcommand.debugFlag)
+	listOptions := ticket.ListOptions{
+		SortBy:    subcommand.sortBy,
+		GroupBy:   subcommand.groupBy,
+		Mine:      subcommand.mineFlag,
+		Unblocked: subcommand.unblocked,
+		Limit:     subcommand.limit,
+		Offset:    subcommand.offset,
+		Columns:   subcommand.listColumns,
+		Output:    subcommand.output,
+		Format:    subcommand.format,
+	}
+	err := ticket.HandleList(os.Stdout, subcommand.windowWidth, common.BranchName, subcommand.filter, subcommand.filterSet, listOptions, subcommand.debugFlag)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -72,6 +146,40 @@ func (subcommand *SubcommandList) Execute() {
 func (subcommand *SubcommandList) Help() {
 	fmt.Println("  list - List tickets")
 	fmt.Println("    eg: giticket list [params]")
+	fmt.Println("    parameters:")
+	fmt.Println("      --filter   | -f \"my filter name\"")
+	fmt.Println("      --set-filter")
+	fmt.Println("      --sort \"priority,-created\"")
+	fmt.Println("      --group-by status|label|severity|assignee|milestone")
+	fmt.Println("      --mine")
+	fmt.Println("      --unblocked")
+	fmt.Println("      --limit N")
+	fmt.Println("      --offset N")
+	fmt.Println("      --columns \"id,title,priority,severity,status,labels,assignees,milestone,due,links,checklist,estimate,logged,created,comments\"")
+	fmt.Println("      --output   | -o table|json|yaml|csv|tsv|markdown")
+	fmt.Println("      --format   '{{.ID}}\\t{{.Title}}' | @name")
+	fmt.Println("      --window   | -w N")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: List tickets by priority, newest first within each priority")
+	fmt.Println("        example: giticket list --sort \"priority,-created\"")
+	fmt.Println("      - name: List tickets grouped by status")
+	fmt.Println("        example: giticket list --group-by status")
+	fmt.Println("      - name: List the tickets assigned to you, most urgent first")
+	fmt.Println("        example: giticket list --mine --sort priority")
+	fmt.Println("      - name: List the tickets that are ready to be worked on")
+	fmt.Println("        example: giticket list --unblocked")
+	fmt.Println("      - name: List tickets with their due dates, soonest first")
+	fmt.Println("        example: giticket list --sort due --columns id,title,status,due")
+	fmt.Println("      - name: List the second page of 20 tickets")
+	fmt.Println("        example: giticket list --limit 20 --offset 20")
+	fmt.Println("      - name: List the ID, title and labels of tickets as CSV")
+	fmt.Println("        example: giticket list --columns id,title,labels --output csv")
+	fmt.Println("      - name: List tickets with a Go template, see giticket format --help")
+	fmt.Println("        example: giticket list --format '{{.ID}}\\t{{.Title}}\\t{{join .Labels \",\"}}'")
+	fmt.Println("      - name: List tickets with the stored format named \"short\"")
+	fmt.Println("        example: giticket list --format @short")
 }
 
 // Parameters
@@ -0,0 +1,172 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the milestone subcommand
+func init() {
+	subcommand := new(SubcommandMilestone)
+	registerSubcommand("milestone", subcommand)
+}
+
+// SubcommandMilestone implements SubcommandInterface and extends it with
+// attributes specific to the milestone subcommand
+type SubcommandMilestone struct {
+	action      string
+	debugFlag   bool
+	description string
+	due         int64
+	flagset     *flag.FlagSet
+	helpFlag    bool
+	name        string
+	parameters  map[string]interface{}
+	ticketIDs   []int
+	unsetFlag   bool
+}
+
+// InitFlags sets up the flags specific to the milestone subcommand, parses the
+// action and flags, and returns any errors
+func (subcommand *SubcommandMilestone) InitFlags(args []string) error {
+	// The action comes before the flags, eg: giticket milestone create --name v1.0
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.action = args[0]
+		args = args[1:]
+	}
+
+	var due, ids string
+	subcommand.flagset = flag.NewFlagSet("milestone", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.name, "name", "", "Name of the milestone")
+	subcommand.flagset.StringVar(&subcommand.name, "n", "", "Name of the milestone")
+	subcommand.flagset.StringVar(&subcommand.description, "description", "", "Description of the milestone")
+	subcommand.flagset.StringVar(&subcommand.description, "d", "", "Description of the milestone")
+	subcommand.flagset.StringVar(&due, "due", "", "Due date of the milestone, eg: 2024-07-01, +2w or friday")
+	subcommand.flagset.StringVar(&ids, "ticketid", "", "Comma separated list of ticket IDs to plan for the milestone")
+	subcommand.flagset.StringVar(&ids, "id", "", "Comma separated list of ticket IDs to plan for the milestone")
+	subcommand.flagset.BoolVar(&subcommand.unsetFlag, "unset", false, "Remove the tickets from their milestone")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["action"] = subcommand.action
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["name"] = subcommand.name
+	subcommand.parameters["description"] = subcommand.description
+	subcommand.parameters["due"] = due
+	subcommand.parameters["ids"] = ids
+	subcommand.parameters["unsetFlag"] = subcommand.unsetFlag
+
+	// Sanity checks
+	if !slices.Contains(ticket.MilestoneActions, subcommand.action) {
+		return fmt.Errorf("an action must be given first, one of: %s", strings.Join(ticket.MilestoneActions, ", "))
+	}
+	if subcommand.action != "list" && subcommand.name == "" && !subcommand.unsetFlag {
+		return fmt.Errorf("--name must be given to %s a milestone", subcommand.action)
+	}
+	if subcommand.action != "create" && (subcommand.description != "" || due != "") {
+		return fmt.Errorf("--description and --due can only be given when creating a milestone")
+	}
+	if due != "" {
+		var err error
+		subcommand.due, err = ticket.ParseDue(due, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	if subcommand.action == "set" {
+		if subcommand.unsetFlag && subcommand.name != "" {
+			return fmt.Errorf("--unset cannot be combined with --name")
+		}
+		var err error
+		subcommand.ticketIDs, err = ticket.ParseTicketIDs(ids)
+		if err != nil {
+			return err
+		}
+		if len(subcommand.ticketIDs) == 0 {
+			return fmt.Errorf("ticket IDs must be specified")
+		}
+	} else if ids != "" || subcommand.unsetFlag {
+		return fmt.Errorf("--id and --unset can only be given with the set action")
+	}
+
+	return nil
+}
+
+// Execute creates, lists, shows, or closes milestones, or plans tickets for a
+// milestone, when the milestone subcommand is used from the CLI
+func (subcommand *SubcommandMilestone) Execute() {
+	var err error
+	switch subcommand.action {
+	case "create":
+		err = ticket.HandleMilestoneCreate(common.BranchName, subcommand.name, subcommand.description, subcommand.due, subcommand.debugFlag)
+	case "list":
+		err = ticket.HandleMilestoneList(os.Stdout, common.BranchName, subcommand.debugFlag)
+	case "show":
+		err = ticket.HandleMilestoneShow(os.Stdout, common.BranchName, subcommand.name, subcommand.debugFlag)
+	case "close":
+		err = ticket.HandleMilestoneClose(common.BranchName, subcommand.name, subcommand.debugFlag)
+	case "set":
+		err = ticket.HandleMilestoneSet(common.BranchName, subcommand.ticketIDs, subcommand.name, subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the milestone subcommand
+func (subcommand *SubcommandMilestone) Help() {
+	fmt.Println("  milestone - Plan tickets for releases and other milestones")
+	fmt.Println("    eg: giticket milestone create|list|show|close|set [params]")
+	fmt.Println("    Progress counts the open and closed tickets in each milestone. Once an open")
+	fmt.Println("    milestone is past its due date, its open tickets are overdue.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --name        | -n v1.0")
+	fmt.Println("      --description | -d \"First stable release\"")
+	fmt.Println("      --due 2024-07-01|+2w|friday")
+	fmt.Println("      --ticketid    | --id 1,4-6")
+	fmt.Println("      --unset")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Create a milestone due on the first of July")
+	fmt.Println("        example: giticket milestone create --name v1.0 --due 2024-07-01 --description \"First stable release\"")
+	fmt.Println("      - name: Plan tickets 1 and 4 to 6 for the milestone")
+	fmt.Println("        example: giticket milestone set --name v1.0 --id 1,4-6")
+	fmt.Println("      - name: Remove ticket 5 from its milestone")
+	fmt.Println("        example: giticket milestone set --unset --id 5")
+	fmt.Println("      - name: List milestones with their progress")
+	fmt.Println("        example: giticket milestone list")
+	fmt.Println("      - name: Show a milestone and its tickets")
+	fmt.Println("        example: giticket milestone show --name v1.0")
+	fmt.Println("      - name: Close the milestone")
+	fmt.Println("        example: giticket milestone close --name v1.0")
+}
+
+// Parameters
+func (subcommand *SubcommandMilestone) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandMilestone) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,144 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the ref subcommand
+func init() {
+	subcommand := new(SubcommandRef)
+	registerSubcommand("ref", subcommand)
+}
+
+// SubcommandRef implements SubcommandInterface and extends it with attributes
+// specific to the ref subcommand
+type SubcommandRef struct {
+	action     string
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	location   string
+	parameters map[string]interface{}
+	ticketIDs  []int
+}
+
+// InitFlags sets up the flags specific to the ref subcommand, parses the
+// action, flags, and the location that follows the flags, and returns any
+// errors
+func (subcommand *SubcommandRef) InitFlags(args []string) error {
+	// The action comes before the flags, eg: giticket ref add --id 1 src/foo.go:120-140
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.action = args[0]
+		args = args[1:]
+	}
+
+	var ids string
+	subcommand.flagset = flag.NewFlagSet("ref", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&ids, "ticketid", "", "Ticket ID, or a comma separated list of ticket IDs to check")
+	subcommand.flagset.StringVar(&ids, "id", "", "Ticket ID, or a comma separated list of ticket IDs to check")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	// The location follows the flags, eg: src/foo.go:120-140
+	rest := subcommand.flagset.Args()
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["action"] = subcommand.action
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ids"] = ids
+	subcommand.parameters["args"] = rest
+
+	// Sanity checks
+	if !slices.Contains(ticket.CodeRefActions, subcommand.action) {
+		return fmt.Errorf("an action must be given first, one of: %s", strings.Join(ticket.CodeRefActions, ", "))
+	}
+	var err error
+	subcommand.ticketIDs, err = ticket.ParseTicketIDs(ids)
+	if err != nil {
+		return err
+	}
+	if subcommand.action == "check" {
+		if len(rest) > 0 {
+			return fmt.Errorf("check only accepts --id")
+		}
+		return nil
+	}
+
+	if len(subcommand.ticketIDs) != 1 {
+		return fmt.Errorf("a single ticket ID must be specified")
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("a location must be given after the flags, eg: src/foo.go:120-140")
+	}
+	subcommand.location = rest[0]
+
+	return nil
+}
+
+// Execute adds, removes, or checks references from tickets to lines of code
+// when the ref subcommand is used from the CLI
+func (subcommand *SubcommandRef) Execute() {
+	var err error
+	switch subcommand.action {
+	case "add":
+		err = ticket.HandleRefAdd(common.BranchName, subcommand.ticketIDs[0], subcommand.location, subcommand.debugFlag)
+	case "remove":
+		err = ticket.HandleRefRemove(common.BranchName, subcommand.ticketIDs[0], subcommand.location, subcommand.debugFlag)
+	case "check":
+		err = ticket.HandleRefCheck(os.Stdout, common.BranchName, subcommand.ticketIDs, subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the ref subcommand
+func (subcommand *SubcommandRef) Help() {
+	fmt.Println("  ref - Refer tickets to lines of code")
+	fmt.Println("    eg: giticket ref add|remove|check [params] [location]")
+	fmt.Println("    A reference records the lines as they are in the current commit, and show")
+	fmt.Println("    prints them from that commit. check reports whether the lines are")
+	fmt.Println("    unchanged in the current commit, have moved, have changed, or were deleted.")
+	fmt.Println("    Paths are relative to the top of the repository.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Refer ticket 1 to lines 120 to 140 of src/foo.go")
+	fmt.Println("        example: giticket ref add --id 1 src/foo.go:120-140")
+	fmt.Println("      - name: Remove the reference")
+	fmt.Println("        example: giticket ref remove --id 1 src/foo.go:120-140")
+	fmt.Println("      - name: Check whether the code referred to by any ticket has changed")
+	fmt.Println("        example: giticket ref check")
+	fmt.Println("      - name: Check the code referred to by tickets 1 and 4 to 6")
+	fmt.Println("        example: giticket ref check --id 1,4-6")
+}
+
+// Parameters
+func (subcommand *SubcommandRef) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandRef) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,109 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the scan-commits subcommand
+func init() {
+	subcommand := new(SubcommandScanCommits)
+	registerSubcommand("scan-commits", subcommand)
+}
+
+// SubcommandScanCommits implements SubcommandInterface and extends it with
+// attributes specific to the scan-commits subcommand
+type SubcommandScanCommits struct {
+	branches   []string
+	closeFlag  bool
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the scan-commits subcommand, parses
+// flags, and returns any errors
+func (subcommand *SubcommandScanCommits) InitFlags(args []string) error {
+	var branches string
+	subcommand.flagset = flag.NewFlagSet("scan-commits", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&branches, "branches", "", "Comma separated list of branches to scan, default is the current branch")
+	subcommand.flagset.StringVar(&branches, "b", "", "Comma separated list of branches to scan, default is the current branch")
+	subcommand.flagset.BoolVar(&subcommand.closeFlag, "close", false, "Close tickets referred to with a closing keyword such as \"fixes #12\"")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	for _, branch := range strings.Split(branches, ",") {
+		branch = strings.TrimSpace(branch)
+		if branch != "" {
+			subcommand.branches = append(subcommand.branches, branch)
+		}
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["branches"] = subcommand.branches
+	subcommand.parameters["closeFlag"] = subcommand.closeFlag
+
+	return nil
+}
+
+// Execute scans branches for commits that refer to tickets when the
+// scan-commits subcommand is used from the CLI
+func (subcommand *SubcommandScanCommits) Execute() {
+	if subcommand.helpFlag {
+		return
+	}
+
+	err := ticket.HandleScanCommits(os.Stdout, common.BranchName, subcommand.branches, subcommand.closeFlag, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the scan-commits subcommand
+func (subcommand *SubcommandScanCommits) Help() {
+	fmt.Println("  scan-commits - Link tickets to the commits that refer to them")
+	fmt.Println("    eg: giticket scan-commits [params]")
+	fmt.Println("    Commit messages refer to tickets with keywords such as \"fixes #12\" or")
+	fmt.Println("    \"refs #7, #8\", or with trailers such as \"Ticket: 12\" in their last")
+	fmt.Println("    paragraph. The closing keywords are close, fix and resolve, and their other")
+	fmt.Println("    forms such as closes and fixed. Linked commits are shown by giticket show,")
+	fmt.Println("    and scanning again only links new commits.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --branches | -b \"main,develop\"")
+	fmt.Println("      --close")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Link tickets to the commits on the current branch")
+	fmt.Println("        example: giticket scan-commits")
+	fmt.Println("      - name: Link tickets to the commits on main and develop, closing fixed tickets")
+	fmt.Println("        example: giticket scan-commits --branches main,develop --close")
+}
+
+// Parameters
+func (subcommand *SubcommandScanCommits) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandScanCommits) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,115 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the scan-todos subcommand
+func init() {
+	subcommand := new(SubcommandScanTodos)
+	registerSubcommand("scan-todos", subcommand)
+}
+
+// SubcommandScanTodos implements SubcommandInterface and extends it with
+// attributes specific to the scan-todos subcommand
+type SubcommandScanTodos struct {
+	commit     string
+	debugFlag  bool
+	dryRunFlag bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	label      string
+	parameters map[string]interface{}
+	path       string
+}
+
+// InitFlags sets up the flags specific to the scan-todos subcommand, parses
+// flags, and returns any errors
+func (subcommand *SubcommandScanTodos) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("scan-todos", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.path, "path", "", "Only scan files under this path, eg: ./src/...")
+	subcommand.flagset.StringVar(&subcommand.path, "p", "", "Only scan files under this path, eg: ./src/...")
+	subcommand.flagset.StringVar(&subcommand.commit, "commit", "", "Scan the files in this commit rather than the working tree")
+	subcommand.flagset.StringVar(&subcommand.label, "label", ticket.DefaultTodoLabel, "Label of the tickets created for TODO comments")
+	subcommand.flagset.BoolVar(&subcommand.dryRunFlag, "dry-run", false, "Print the tickets that would be created and closed without changing them")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["path"] = subcommand.path
+	subcommand.parameters["commit"] = subcommand.commit
+	subcommand.parameters["label"] = subcommand.label
+	subcommand.parameters["dryRunFlag"] = subcommand.dryRunFlag
+
+	// Sanity checks
+	if subcommand.label == "" {
+		return fmt.Errorf("--label can't be empty")
+	}
+
+	return nil
+}
+
+// Execute creates tickets for TODO comments when the scan-todos subcommand is
+// used from the CLI
+func (subcommand *SubcommandScanTodos) Execute() {
+	if subcommand.helpFlag {
+		return
+	}
+
+	err := ticket.HandleScanTodos(os.Stdout, common.BranchName, subcommand.path, subcommand.commit, subcommand.label, subcommand.dryRunFlag, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the scan-todos subcommand
+func (subcommand *SubcommandScanTodos) Help() {
+	fmt.Println("  scan-todos - Create tickets for TODO comments in the code")
+	fmt.Println("    eg: giticket scan-todos [params]")
+	fmt.Println("    Comments starting with TODO, FIXME, XXX or HACK become tickets with a")
+	fmt.Println("    label and a reference to the comment, see giticket ref. Scanning again")
+	fmt.Println("    skips comments that already have a ticket, and closes the tickets of")
+	fmt.Println("    comments that were removed. Files tracked in the current commit are read")
+	fmt.Println("    from the working tree unless --commit is given.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --path   | -p ./src/...")
+	fmt.Println("      --commit v1.0")
+	fmt.Println("      --label " + ticket.DefaultTodoLabel)
+	fmt.Println("      --dry-run")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Create tickets for the TODO comments in the working tree")
+	fmt.Println("        example: giticket scan-todos")
+	fmt.Println("      - name: See which tickets would be created or closed for the src directory")
+	fmt.Println("        example: giticket scan-todos --path ./src/... --dry-run")
+	fmt.Println("      - name: Create tickets for the TODO comments in the v1.0 tag")
+	fmt.Println("        example: giticket scan-todos --commit v1.0")
+}
+
+// Parameters
+func (subcommand *SubcommandScanTodos) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandScanTodos) DebugFlag() bool {
+	return subcommand.debugFlag
+}
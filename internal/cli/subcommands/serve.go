@@ -0,0 +1,111 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/server"
+)
+
+// init registers the serve subcommand
+func init() {
+	subcommand := new(SubcommandServe)
+	registerSubcommand("serve", subcommand)
+}
+
+// SubcommandServe implements SubcommandInterface and extends it with
+// attributes specific to the serve subcommand
+type SubcommandServe struct {
+	addr       string
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the serve subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandServe) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("serve", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.addr, "addr", server.DefaultAddr, "Address to serve the API on")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["addr"] = subcommand.addr
+
+	return nil
+}
+
+// Execute serves the web UI and REST API when the serve subcommand is used from the CLI
+func (subcommand *SubcommandServe) Execute() {
+	if subcommand.helpFlag {
+		return
+	}
+
+	fmt.Println("Serving giticket on http://" + subcommand.addr + "/, and its API on http://" + subcommand.addr + server.APIPrefix + "/")
+	err := server.ListenAndServe(subcommand.addr, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the serve subcommand
+func (subcommand *SubcommandServe) Help() {
+	fmt.Println("  serve - Serve a web UI and a JSON REST API for the tickets in this repository")
+	fmt.Println("    eg: giticket serve [params]")
+	fmt.Println("    Open http://" + server.DefaultAddr + "/ in a browser to list, view, create, and")
+	fmt.Println("    comment on tickets, and change their status and labels.")
+	fmt.Println("    Endpoints, under " + server.APIPrefix + ":")
+	fmt.Println("      GET    /tickets?filter=name&q=query&sort=field&limit=n&offset=n")
+	fmt.Println("      POST   /tickets")
+	fmt.Println("      GET    /tickets/{id}")
+	fmt.Println("      PATCH  /tickets/{id}")
+	fmt.Println("      DELETE /tickets/{id}")
+	fmt.Println("      POST   /tickets/{id}/comments")
+	fmt.Println("      POST   /tickets/{id}/labels")
+	fmt.Println("      DELETE /tickets/{id}/labels/{label}")
+	fmt.Println("      PUT    /tickets/{id}/status")
+	fmt.Println("      GET    /filters")
+	fmt.Println("      POST   /filters")
+	fmt.Println("      GET    /filters/{name}")
+	fmt.Println("      PUT    /filters/{name}")
+	fmt.Println("      DELETE /filters/{name}")
+	fmt.Println("    Tickets are returned with an ETag header. Send it back in an If-Match")
+	fmt.Println("    header when changing the ticket to refuse the change if the ticket has")
+	fmt.Println("    changed since, with 412 Precondition Failed.")
+	fmt.Println("    Only requests for the --addr host or a loopback name such as localhost are")
+	fmt.Println("    accepted.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --addr " + server.DefaultAddr)
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Serve the API on the default address")
+	fmt.Println("        example: giticket serve")
+	fmt.Println("      - name: Serve the API on port 9000")
+	fmt.Println("        example: giticket serve --addr 127.0.0.1:9000")
+}
+
+// Parameters
+func (subcommand *SubcommandServe) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandServe) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -3,6 +3,8 @@ package subcommands
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/jeffwelling/giticket/pkg/common"
 	"github.com/jeffwelling/giticket/pkg/ticket"
@@ -20,14 +22,25 @@ type SubcommandShow struct {
 	debugFlag  bool
 	helpFlag   bool
 	output     string
-	ticket_id  int
+	renderFlag bool
+	color      string
+	format     string
+	ticket_ids string
+	ticketIDs  []int
+	fromRange  map[int]bool
 	flagset    *flag.FlagSet
 	parameters map[string]interface{}
 }
 
 // Execute is used to show a ticket when the user uses the show subcommand from the CLI
 func (subcommand *SubcommandShow) Execute() {
-	err := ticket.HandleShow(subcommand.ticket_id, subcommand.output, subcommand.debugFlag, subcommand.helpFlag)
+	color, err := common.UseColor(subcommand.color)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = ticket.HandleShow(os.Stdout, subcommand.ticketIDs, subcommand.fromRange, subcommand.output, subcommand.format, subcommand.renderFlag, color, subcommand.debugFlag, subcommand.helpFlag)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -37,15 +50,31 @@ func (subcommand *SubcommandShow) Execute() {
 // Help prints help information for the show subcommand
 func (subcommand *SubcommandShow) Help() {
 	fmt.Println("  show - Show ticket")
-	fmt.Println("    eg: giticket show [parameters]")
+	fmt.Println("    eg: giticket show [parameters] [ID...]")
 	fmt.Println("    parameters:")
-	fmt.Println("      --ticketid | --id N")
-	fmt.Println("      --output   | --o text|yaml|json")
+	fmt.Println("      --ticketid | --id N[,N-M...]")
+	fmt.Println("      --output   | --o " + strings.Join(ticket.ShowOutputFormats, "|"))
+	fmt.Println("      --format   '{{.ID}}: {{.Title}}' | @name")
+	fmt.Println("      --render")
+	fmt.Println("      --color auto|always|never")
 	fmt.Println("      --debug")
 	fmt.Println("      --help")
+	fmt.Println("    Tickets can be given with --id, as arguments, or both, and are shown")
+	fmt.Println("    in the order given. Tickets missing from a range such as 4-6 are skipped.")
+	fmt.Println("    --render formats the description and comments as")
+	fmt.Println("    markdown in text output, and --color highlights text output, by")
+	fmt.Println("    default only when printing to a terminal and NO_COLOR isn't set.")
 	fmt.Println("    examples:")
 	fmt.Println("      - name: Show ticket with ID #1")
 	fmt.Println("        example: giticket show --ticketid 1")
+	fmt.Println("      - name: Show tickets 1, 4, 5 and 6 as markdown")
+	fmt.Println("        example: giticket show --id 1,4-6 --output markdown")
+	fmt.Println("      - name: Show tickets 2 and 3 with their comments rendered in color")
+	fmt.Println("        example: giticket show --render --color always 2 3")
+	fmt.Println("      - name: Print the title and comment count of ticket 1 with a Go template")
+	fmt.Println("        example: giticket show --id 1 --format '{{.Title}} ({{len .Comments}} comments)'")
+	fmt.Println("      - name: Save ticket 1 as a web page")
+	fmt.Println("        example: giticket show --id 1 --output html > ticket-1.html")
 
 }
 
@@ -54,10 +83,10 @@ func (subcommand *SubcommandShow) Help() {
 func (subcommand *SubcommandShow) InitFlags(args []string) error {
 	subcommand.parameters = make(map[string]interface{})
 	var (
-		helpFlag  bool
-		debugFlag bool
-		output    string
-		ticket_id int
+		helpFlag   bool
+		debugFlag  bool
+		output     string
+		ticket_ids string
 	)
 	subcommand.flagset = flag.NewFlagSet("show", flag.ExitOnError)
 
@@ -65,16 +94,28 @@ func (subcommand *SubcommandShow) InitFlags(args []string) error {
 	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help for the show subcommand")
 	subcommand.flagset.StringVar(&subcommand.output, "output", "text", "Output format")
 	subcommand.flagset.StringVar(&subcommand.output, "o", "text", "Output format")
-	subcommand.flagset.IntVar(&subcommand.ticket_id, "ticketid", 0, "Ticket ID")
-	subcommand.flagset.IntVar(&subcommand.ticket_id, "id", 0, "Ticket ID")
+	subcommand.flagset.StringVar(&subcommand.format, "format", "", "Go template to print each ticket with, or @name of a stored format")
+	subcommand.flagset.BoolVar(&subcommand.renderFlag, "render", false, "Render the description and comments as markdown")
+	subcommand.flagset.StringVar(&subcommand.color, "color", "auto", "Use color: auto, always, or never")
+	subcommand.flagset.StringVar(&subcommand.ticket_ids, "ticketid", "", "Ticket IDs")
+	subcommand.flagset.StringVar(&subcommand.ticket_ids, "id", "", "Ticket IDs")
 	if err := subcommand.flagset.Parse(args); err != nil {
 		return err
 	}
 
+	// Ticket IDs may be given with --id, as arguments after the flags, or both
+	ids := append([]string{subcommand.ticket_ids}, subcommand.flagset.Args()...)
+	ticketIDs, fromRange, err := ticket.ParseTicketIDRanges(strings.Join(ids, ","))
+	if err != nil {
+		return err
+	}
+	subcommand.ticketIDs = ticketIDs
+	subcommand.fromRange = fromRange
+
 	subcommand.parameters["debugFlag"] = debugFlag
 	subcommand.parameters["helpFlag"] = helpFlag
 	subcommand.parameters["output"] = output
-	subcommand.parameters["ticket_id"] = ticket_id
+	subcommand.parameters["ticket_ids"] = ticket_ids
 
 	if subcommand.helpFlag {
 		common.PrintVersion()
@@ -82,7 +123,7 @@ func (subcommand *SubcommandShow) InitFlags(args []string) error {
 		subcommand.Help()
 	}
 
-	if subcommand.ticket_id == 0 {
+	if len(subcommand.ticketIDs) == 0 && !subcommand.helpFlag {
 		fmt.Println("Error: Ticket ID must be specified")
 		// Print usage
 		common.PrintGeneralUsage()
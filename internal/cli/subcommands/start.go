@@ -0,0 +1,100 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the start subcommand
+func init() {
+	subcommand := new(SubcommandStart)
+	registerSubcommand("start", subcommand)
+}
+
+// SubcommandStart implements SubcommandInterface and extends it with
+// attributes specific to the start subcommand
+type SubcommandStart struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+	prefix     string
+	ticketID   int
+}
+
+// InitFlags sets up the flags specific to the start subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandStart) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("start", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "ID of the ticket to start work on")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "ID of the ticket to start work on")
+	subcommand.flagset.StringVar(&subcommand.prefix, "prefix", "", "Prefix for the work branch name, eg: feature/")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["prefix"] = subcommand.prefix
+
+	// Sanity checks
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
+
+	return nil
+}
+
+// Execute checks out a work branch for a ticket and marks the ticket as in
+// progress when the start subcommand is used from the CLI
+func (subcommand *SubcommandStart) Execute() {
+	err := ticket.HandleStart(os.Stdout, common.BranchName, subcommand.ticketID, subcommand.prefix, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the start subcommand
+func (subcommand *SubcommandStart) Help() {
+	fmt.Println("  start - Start work on a ticket in its own branch")
+	fmt.Println("    eg: giticket start [params]")
+	fmt.Println("    Checks out a branch named from the ticket ID and title, such as")
+	fmt.Println("    12-fix-the-login-page, creating it from the current commit if it doesn't")
+	fmt.Println("    exist. The ticket is assigned to you and moved to the startStatus setting,")
+	fmt.Println("    \"" + ticket.DefaultStartStatus + "\" by default. See also: giticket finish")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 12")
+	fmt.Println("      --prefix feature/")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Start work on ticket 12")
+	fmt.Println("        example: giticket start --id 12")
+	fmt.Println("      - name: Start work on ticket 12 in a branch named feature/12-...")
+	fmt.Println("        example: giticket start --id 12 --prefix feature/")
+}
+
+// Parameters
+func (subcommand *SubcommandStart) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandStart) DebugFlag() bool {
+	return subcommand.debugFlag
+}
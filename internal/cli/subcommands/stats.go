@@ -0,0 +1,125 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the stats subcommand
+func init() {
+	subcommand := new(SubcommandStats)
+	registerSubcommand("stats", subcommand)
+}
+
+// SubcommandStats implements SubcommandInterface and extends it with
+// attributes specific to the stats subcommand
+type SubcommandStats struct {
+	debugFlag  bool
+	filter     string
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	output     string
+	parameters map[string]interface{}
+	report     string
+	since      int64
+}
+
+// InitFlags sets up the flags specific to the stats subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandStats) InitFlags(args []string) error {
+	var since string
+	subcommand.flagset = flag.NewFlagSet("stats", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.StringVar(&subcommand.filter, "filter", "", "Name of the filter to report on the tickets of")
+	subcommand.flagset.StringVar(&since, "since", "", "Start the burndown at this date or this long ago, eg: 2026-10-01 or 30d")
+	subcommand.flagset.StringVar(&subcommand.report, "report", "", "Only write one report, one of: "+strings.Join(ticket.StatsReports, ", "))
+	subcommand.flagset.StringVar(&subcommand.output, "output", "table", "Output format, one of: "+strings.Join(ticket.StatsFormats, ", "))
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["filter"] = subcommand.filter
+	subcommand.parameters["since"] = since
+	subcommand.parameters["report"] = subcommand.report
+	subcommand.parameters["output"] = subcommand.output
+
+	// Sanity checks
+	if subcommand.report != "" && !slices.Contains(ticket.StatsReports, subcommand.report) {
+		return fmt.Errorf("--report must be one of: %s", strings.Join(ticket.StatsReports, ", "))
+	}
+	if !slices.Contains(ticket.StatsFormats, subcommand.output) {
+		return fmt.Errorf("--output must be one of: %s", strings.Join(ticket.StatsFormats, ", "))
+	}
+	if since != "" {
+		var err error
+		subcommand.since, err = ticket.ParseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute writes the stats when the stats subcommand is used from the CLI
+func (subcommand *SubcommandStats) Execute() {
+	err := ticket.HandleStats(os.Stdout, common.BranchName, subcommand.filter, subcommand.since, subcommand.report, subcommand.output, subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the stats subcommand
+func (subcommand *SubcommandStats) Help() {
+	fmt.Println("  stats - Report ticket counts, cycle and lead times, and a burndown")
+	fmt.Println("    eg: giticket stats [params]")
+	fmt.Println("    counts - the open and closed tickets by status, label, priority, and")
+	fmt.Println("             assignee")
+	fmt.Println("    times - how long closed tickets took, from creation to closing for lead")
+	fmt.Println("            time, and from first moving to the start status to closing for")
+	fmt.Println("            cycle time")
+	fmt.Println("    burndown - the tickets open and closed at the end of each day, replayed")
+	fmt.Println("               from the history of the giticket branch")
+	fmt.Println("    Times are in seconds in JSON and CSV. In CSV, each report has its own")
+	fmt.Println("    header, and reports are separated by an empty line.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --filter open")
+	fmt.Println("      --since 2026-10-01|30d")
+	fmt.Println("      --report counts|times|burndown")
+	fmt.Println("      --output table|json|csv")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Report on every ticket")
+	fmt.Println("        example: giticket stats")
+	fmt.Println("      - name: Chart the last 30 days of the burndown")
+	fmt.Println("        example: giticket stats --report burndown --since 30d --output csv")
+}
+
+// Parameters
+func (subcommand *SubcommandStats) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandStats) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,165 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// init registers the time subcommand
+func init() {
+	subcommand := new(SubcommandTime)
+	registerSubcommand("time", subcommand)
+}
+
+// SubcommandTime implements SubcommandInterface and extends it with
+// attributes specific to the time subcommand
+type SubcommandTime struct {
+	action     string
+	by         string
+	debugFlag  bool
+	duration   time.Duration
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	note       string
+	parameters map[string]interface{}
+	since      int64
+	ticketID   int
+}
+
+// InitFlags sets up the flags specific to the time subcommand, parses the
+// action, flags, and the duration and note that follow the flags, and returns
+// any errors
+func (subcommand *SubcommandTime) InitFlags(args []string) error {
+	// The action comes before the flags, eg: giticket time log --id 1 1h
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand.action = args[0]
+		args = args[1:]
+	}
+
+	var since string
+	subcommand.flagset = flag.NewFlagSet("time", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "ticketid", 0, "Ticket ID")
+	subcommand.flagset.IntVar(&subcommand.ticketID, "id", 0, "Ticket ID")
+	subcommand.flagset.StringVar(&since, "since", "", "Only report work logged since this date or this long ago, eg: 2026-10-01 or 7d")
+	subcommand.flagset.StringVar(&subcommand.by, "by", "ticket", "Total the report by author, ticket, or label")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	// The duration and note follow the flags, eg: 1h30m "investigating"
+	rest := subcommand.flagset.Args()
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["action"] = subcommand.action
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+	subcommand.parameters["ticketID"] = subcommand.ticketID
+	subcommand.parameters["since"] = since
+	subcommand.parameters["by"] = subcommand.by
+	subcommand.parameters["args"] = rest
+
+	// Sanity checks
+	if !slices.Contains(ticket.TimeActions, subcommand.action) {
+		return fmt.Errorf("an action must be given first, one of: %s", strings.Join(ticket.TimeActions, ", "))
+	}
+	if subcommand.action == "report" {
+		if subcommand.ticketID != 0 || len(rest) > 0 {
+			return fmt.Errorf("report only accepts --since and --by")
+		}
+		if !slices.Contains(ticket.TimeReportGroups, subcommand.by) {
+			return fmt.Errorf("--by must be one of: %s", strings.Join(ticket.TimeReportGroups, ", "))
+		}
+		if since != "" {
+			var err error
+			subcommand.since, err = ticket.ParseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if subcommand.ticketID == 0 {
+		return fmt.Errorf("ticket ID must be specified")
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("a duration must be given after the flags, eg: 1h30m")
+	}
+	if subcommand.action == "estimate" && len(rest) > 1 {
+		return fmt.Errorf("estimate accepts only a duration after the flags")
+	}
+	var err error
+	subcommand.duration, err = ticket.ParseDuration(rest[0])
+	if err != nil {
+		return err
+	}
+	subcommand.note = strings.Join(rest[1:], " ")
+
+	return nil
+}
+
+// Execute logs work, sets estimates, or reports logged work when the time
+// subcommand is used from the CLI
+func (subcommand *SubcommandTime) Execute() {
+	var err error
+	switch subcommand.action {
+	case "log":
+		err = ticket.HandleTimeLog(common.BranchName, subcommand.ticketID, subcommand.duration, subcommand.note, subcommand.debugFlag)
+	case "estimate":
+		err = ticket.HandleTimeEstimate(common.BranchName, subcommand.ticketID, subcommand.duration, subcommand.debugFlag)
+	case "report":
+		err = ticket.HandleTimeReport(os.Stdout, common.BranchName, subcommand.since, subcommand.by, subcommand.debugFlag)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the time subcommand
+func (subcommand *SubcommandTime) Help() {
+	fmt.Println("  time - Log work on tickets, estimate tickets, and report logged work")
+	fmt.Println("    eg: giticket time log|estimate|report [params] [duration] [note]")
+	fmt.Println("    Durations are given as hours and minutes such as 1h30m, or as days of 24")
+	fmt.Println("    hours such as 2d. The total logged and the estimate are shown by show.")
+	fmt.Println("    parameters:")
+	fmt.Println("      --ticketid | --id 1")
+	fmt.Println("      --since 2026-10-01|7d")
+	fmt.Println("      --by author|ticket|label")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Log an hour and a half spent investigating ticket 1")
+	fmt.Println("        example: giticket time log --id 1 1h30m \"investigating\"")
+	fmt.Println("      - name: Estimate ticket 1 will take four hours")
+	fmt.Println("        example: giticket time estimate --id 1 4h")
+	fmt.Println("      - name: Remove ticket 1's estimate")
+	fmt.Println("        example: giticket time estimate --id 1 0")
+	fmt.Println("      - name: Total the work logged by each person in the last week")
+	fmt.Println("        example: giticket time report --since 7d --by author")
+}
+
+// Parameters
+func (subcommand *SubcommandTime) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandTime) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,91 @@
+package subcommands
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/tui"
+)
+
+// init registers the tui subcommand
+func init() {
+	subcommand := new(SubcommandTUI)
+	registerSubcommand("tui", subcommand)
+}
+
+// SubcommandTUI implements SubcommandInterface and extends it with attributes
+// specific to the tui subcommand
+type SubcommandTUI struct {
+	debugFlag  bool
+	flagset    *flag.FlagSet
+	helpFlag   bool
+	parameters map[string]interface{}
+}
+
+// InitFlags sets up the flags specific to the tui subcommand, parses flags,
+// and returns any errors
+func (subcommand *SubcommandTUI) InitFlags(args []string) error {
+	subcommand.flagset = flag.NewFlagSet("tui", flag.ExitOnError)
+	subcommand.flagset.BoolVar(&subcommand.debugFlag, "debug", false, "Print debug info")
+	subcommand.flagset.BoolVar(&subcommand.helpFlag, "help", false, "Print help")
+	if err := subcommand.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand.helpFlag {
+		common.PrintVersion()
+		fmt.Println("giticket")
+		subcommand.Help()
+	}
+
+	subcommand.parameters = make(map[string]interface{})
+	subcommand.parameters["debugFlag"] = subcommand.debugFlag
+	subcommand.parameters["helpFlag"] = subcommand.helpFlag
+
+	return nil
+}
+
+// Execute runs the terminal UI when the tui subcommand is used from the CLI
+func (subcommand *SubcommandTUI) Execute() {
+	err := tui.Run(subcommand.debugFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// Help prints help information for the tui subcommand
+func (subcommand *SubcommandTUI) Help() {
+	fmt.Println("  tui - Browse and change tickets in an interactive terminal UI")
+	fmt.Println("    eg: giticket tui [params]")
+	fmt.Println("    Shows the tickets matching the current filter next to the details of the")
+	fmt.Println("    selected ticket, or as a board with a column for each status.")
+	fmt.Println("    keys:")
+	fmt.Println("      j/k or up/down     select the next or previous ticket")
+	fmt.Println("      h/l or left/right  select a ticket in the next or previous board column")
+	fmt.Println("      tab or b           switch between the list and the board")
+	fmt.Println("      f/F                use the next or previous saved filter")
+	fmt.Println("      s, p, v            set the status, priority, or severity")
+	fmt.Println("      a, d               add or remove a label")
+	fmt.Println("      c                  comment, in your editor")
+	fmt.Println("      [ and ]            move the ticket to the previous or next board column")
+	fmt.Println("      r                  reload the tickets")
+	fmt.Println("      q                  quit")
+	fmt.Println("    parameters:")
+	fmt.Println("      --debug")
+	fmt.Println("      --help")
+	fmt.Println("    examples:")
+	fmt.Println("      - name: Start the terminal UI")
+	fmt.Println("        example: giticket tui")
+}
+
+// Parameters
+func (subcommand *SubcommandTUI) Parameters() map[string]interface{} {
+	return subcommand.parameters
+}
+
+// DebugFlag
+func (subcommand *SubcommandTUI) DebugFlag() bool {
+	return subcommand.debugFlag
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// TerminalWidth returns the width of the terminal attached to stdout in
+// columns. If stdout is not a terminal, such as when output is piped to another
+// program, it falls back to the COLUMNS environment variable and returns 0 if
+// that isn't set either, meaning the width is unknown.
+func TerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err == nil && width > 0 {
+		return width
+	}
+
+	width, err = strconv.Atoi(os.Getenv("COLUMNS"))
+	if err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// UseColor takes a color mode, one of "auto", "always" or "never", and returns
+// whether output to stdout should be colored. In auto mode color is used when
+// stdout is a terminal and the NO_COLOR environment variable is not set.
+// Returns an error if mode is not recognized.
+func UseColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+		return os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd())), nil
+	}
+	return false, errors.New("unknown color mode '" + mode + "', valid modes are: auto, always, never")
+}
@@ -0,0 +1,254 @@
+package repo
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// ReadFile takes a pointer to a git repository, a branch name, a path relative
+// to the .giticket directory such as "filters.json" or "milestones/v1.0", and
+// a debugFlag. It returns the contents of that file at the tip of branchName,
+// or an error if there was one. A missing file returns an error for which
+// git.IsErrorCode(err, git.ErrorCodeNotFound) is true.
+func ReadFile(thisRepo *git.Repository, branchName string, filePath string, debugFlag bool) ([]byte, error) {
+	debug.DebugMessage(debugFlag, "Reading .giticket/"+filePath+" from branch '"+branchName+"'")
+	parentCommit, err := GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer parentCommit.Free()
+
+	rootTree, err := parentCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer rootTree.Free()
+
+	entry, err := rootTree.EntryByPath(path.Join(".giticket", filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := thisRepo.LookupBlob(entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Free()
+
+	return blob.Contents(), nil
+}
+
+// ListFiles takes a pointer to a git repository, a branch name, a directory
+// relative to the .giticket directory, and a debugFlag. It returns the names of
+// the files in that directory at the tip of branchName, sorted by name. A
+// directory that does not exist yet has no files, and is not an error.
+func ListFiles(thisRepo *git.Repository, branchName string, dirPath string, debugFlag bool) ([]string, error) {
+	debug.DebugMessage(debugFlag, "Listing files in .giticket/"+dirPath+" on branch '"+branchName+"'")
+	parentCommit, err := GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer parentCommit.Free()
+
+	rootTree, err := parentCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer rootTree.Free()
+
+	entry, err := rootTree.EntryByPath(path.Join(".giticket", dirPath))
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	dirTree, err := thisRepo.LookupTree(entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer dirTree.Free()
+
+	names := []string{}
+	for i := uint64(0); i < dirTree.EntryCount(); i++ {
+		treeEntry := dirTree.EntryByIndex(i)
+		if treeEntry.Type == git.ObjectBlob {
+			names = append(names, treeEntry.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CommitFiles takes a pointer to a git repository, a branch name, a map of
+// files to write, a commit message, and a debugFlag. The keys of files are
+// paths relative to the .giticket directory, and the values are the new
+// contents of each file, or nil to remove the file. Every change is made in a
+// single new commit on branchName. Directories are created as needed, and are
+// kept even if they are left empty. Paths must be clean, and ticket files must
+// be directly in the tickets directory. Returns an error if there was one.
+func CommitFiles(thisRepo *git.Repository, branchName string, files map[string][]byte, commitMessage string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Committing "+commitMessage)
+	if len(files) == 0 {
+		return errors.New("no files to commit")
+	}
+
+	parentCommit, err := GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	defer parentCommit.Free()
+
+	rootTree, err := parentCommit.Tree()
+	if err != nil {
+		return err
+	}
+	defer rootTree.Free()
+
+	// Prefix every path with .giticket, and write a blob for each file
+	blobs := make(map[string]*git.Oid)
+	for filePath, contents := range files {
+		cleanPath := path.Clean(filePath)
+		if cleanPath != filePath || cleanPath == "." || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") || path.IsAbs(cleanPath) {
+			return errors.New("invalid path for .giticket file: " + filePath)
+		}
+		if dir, _ := path.Split(cleanPath); strings.HasPrefix(cleanPath, "tickets/") && dir != "tickets/" {
+			return errors.New("ticket files must be directly in .giticket/tickets: " + filePath)
+		}
+		fullPath := path.Join(".giticket", cleanPath)
+
+		if contents == nil {
+			debug.DebugMessage(debugFlag, "Removing "+fullPath)
+			blobs[fullPath] = nil
+			continue
+		}
+
+		blobOID, err := thisRepo.CreateBlobFromBuffer(contents)
+		if err != nil {
+			return err
+		}
+		debug.DebugMessage(debugFlag, "Writing "+fullPath+": "+blobOID.String())
+		blobs[fullPath] = blobOID
+	}
+
+	newRootTreeID, err := updateTree(thisRepo, rootTree, blobs, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	newRootTree, err := thisRepo.LookupTree(newRootTreeID)
+	if err != nil {
+		return err
+	}
+	defer newRootTree.Free()
+
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	commitID, err := thisRepo.CreateCommit("refs/heads/"+branchName, author, author, commitMessage, newRootTree, parentCommit)
+	if err != nil {
+		debug.DebugMessage(debugFlag, "Error creating commit: "+err.Error())
+		return err
+	}
+	debug.DebugMessage(debugFlag, "Created commit: "+commitID.String())
+	return nil
+}
+
+// updateTree takes a pointer to a git repository, a tree (which may be nil for
+// a directory that doesn't exist yet), a map of paths relative to that tree to
+// the blob to store there (nil removes the path), and a debugFlag. It writes
+// the updated tree and any updated sub-trees, and returns the ID of the new
+// tree.
+func updateTree(thisRepo *git.Repository, tree *git.Tree, blobs map[string]*git.Oid, debugFlag bool) (*git.Oid, error) {
+	var (
+		treeBuilder *git.TreeBuilder
+		err         error
+	)
+	if tree == nil {
+		treeBuilder, err = thisRepo.TreeBuilder()
+	} else {
+		treeBuilder, err = thisRepo.TreeBuilderFromTree(tree)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer treeBuilder.Free()
+
+	// Split the paths into files in this tree and paths in each sub-tree
+	subTrees := make(map[string]map[string]*git.Oid)
+	for blobPath, blobOID := range blobs {
+		name, rest, isSubTree := strings.Cut(blobPath, "/")
+		if isSubTree {
+			if subTrees[name] == nil {
+				subTrees[name] = make(map[string]*git.Oid)
+			}
+			subTrees[name][rest] = blobOID
+			continue
+		}
+
+		if blobOID == nil {
+			if tree != nil && tree.EntryByName(name) != nil {
+				err = treeBuilder.Remove(name)
+				if err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		err = treeBuilder.Insert(name, blobOID, git.FilemodeBlob)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for name, subTreeBlobs := range subTrees {
+		var subTree *git.Tree
+		if tree != nil {
+			entry := tree.EntryByName(name)
+			if entry != nil && entry.Type == git.ObjectTree {
+				subTree, err = thisRepo.LookupTree(entry.Id)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		debug.DebugMessage(debugFlag, "Updating sub-tree "+name)
+		subTreeID, err := updateTree(thisRepo, subTree, subTreeBlobs, debugFlag)
+		if subTree != nil {
+			subTree.Free()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = treeBuilder.Insert(name, subTreeID, git.FilemodeTree)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return treeBuilder.Write()
+}
+
+// CommitTickets takes a pointer to a git repository, a branch name, a list of
+// tickets, a commit message, and a debugFlag, and writes every ticket in a
+// single new commit on branchName. It is used when a change touches more than
+// one ticket, such as linking two tickets together, so the tickets can never
+// be seen half updated. Returns an error if there was one.
+func CommitTickets(thisRepo *git.Repository, branchName string, tickets []common.TicketInterface, commitMessage string, debugFlag bool) error {
+	files := make(map[string][]byte)
+	for _, t := range tickets {
+		files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+	}
+	return CommitFiles(thisRepo, branchName, files, commitMessage, debugFlag)
+}
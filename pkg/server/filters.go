@@ -0,0 +1,145 @@
+package server
+
+import (
+	"net/http"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// filterRequest is the body of a request to create a filter. Name is taken
+// from the path when a filter is replaced.
+type filterRequest struct {
+	Name   string
+	Filter string
+}
+
+// listFilters writes the list of filters, which is empty until a filter is
+// created
+func (s *Server) listFilters(w http.ResponseWriter) error {
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, filters)
+}
+
+// createFilter creates a filter from the request body, refusing to replace a
+// filter that already exists
+func (s *Server) createFilter(w http.ResponseWriter, r *http.Request) error {
+	var req filterRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return &apiError{http.StatusBadRequest, "the filter name can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	if _, ok := filters.Filters[req.Name]; ok {
+		return &apiError{http.StatusConflict, "filter '" + req.Name + "' already exists"}
+	}
+	return s.writeFilter(w, req.Name, req.Filter, http.StatusCreated)
+}
+
+// getFilter writes the filter with the given name
+func (s *Server) getFilter(w http.ResponseWriter, name string) error {
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	filter, ok := filters.Filters[name]
+	if !ok {
+		return &apiError{http.StatusNotFound, "filter '" + name + "' not found"}
+	}
+	return writeJSON(w, http.StatusOK, filter)
+}
+
+// putFilter creates or replaces the filter with the given name
+func (s *Server) putFilter(w http.ResponseWriter, r *http.Request, name string) error {
+	var req filterRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	if req.Name != "" && req.Name != name {
+		return &apiError{http.StatusBadRequest, "the filter name in the body doesn't match the path"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	status := http.StatusCreated
+	if _, ok := filters.Filters[name]; ok {
+		status = http.StatusOK
+	}
+	return s.writeFilter(w, name, req.Filter, status)
+}
+
+// deleteFilter deletes the filter with the given name
+func (s *Server) deleteFilter(w http.ResponseWriter, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	if _, ok := filters.Filters[name]; !ok {
+		return &apiError{http.StatusNotFound, "filter '" + name + "' not found"}
+	}
+
+	err = ticket.HandleFilterDelete(name, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// loadFilters returns the list of filters, or an empty list if no filter has
+// been created yet
+func (s *Server) loadFilters() (*ticket.FilterList, error) {
+	filters, err := ticket.GetFilters(common.BranchName, s.debugFlag)
+	if err != nil {
+		if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return nil, err
+		}
+		filters = &ticket.FilterList{}
+	}
+	if filters.Filters == nil {
+		filters.Filters = make(map[string]ticket.Filter)
+	}
+	return filters, nil
+}
+
+// writeFilter checks that filter is a valid jq query, saves it under name,
+// and writes the saved filter with the given status
+func (s *Server) writeFilter(w http.ResponseWriter, name string, filter string, status int) error {
+	if filter == "" {
+		return &apiError{http.StatusBadRequest, "the filter can't be empty"}
+	}
+	_, err := ticket.QueryTickets([]ticket.Ticket{{ID: 1}}, filter, s.debugFlag)
+	if err != nil {
+		return &apiError{http.StatusBadRequest, err.Error()}
+	}
+
+	err = ticket.HandleFilterCreate(filter, name, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, status, filters.Filters[name])
+}
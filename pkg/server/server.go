@@ -0,0 +1,245 @@
+// Package server implements giticket's local HTTP REST API, and a web UI
+// built on the same handlers. Tickets and filters are read from and written to
+// the giticket branch of the repository in the current directory, with the
+// same handlers the CLI uses, so every change made through the API or the UI
+// is a commit like any other.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// APIPrefix is the path every API endpoint is served under. It changes when
+// the API does in a way that isn't backwards compatible.
+const APIPrefix = "/api/v1"
+
+// DefaultAddr is the address the API is served on when none is given. Only
+// connections from the same machine are accepted.
+const DefaultAddr = "127.0.0.1:8080"
+
+// Server is an http.Handler serving the giticket REST API
+type Server struct {
+	debugFlag bool
+	// addr is the address the server listens on, the only Host other than a
+	// loopback name requests are accepted for
+	addr string
+
+	// mu is held while a change is made, so checking a ticket's version
+	// against If-Match and committing the change can't be interleaved with
+	// another change
+	mu sync.Mutex
+}
+
+// apiError is an error with the HTTP status it should be reported with
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// New takes a debug flag and returns a Server listening on DefaultAddr
+func New(debugFlag bool) *Server {
+	return &Server{debugFlag: debugFlag, addr: DefaultAddr}
+}
+
+// ListenAndServe takes an address such as 127.0.0.1:8080 and a debug flag,
+// and serves the API on that address until there is an error
+func ListenAndServe(addr string, debugFlag bool) error {
+	s := New(debugFlag)
+	s.addr = addr
+	return http.ListenAndServe(addr, s)
+}
+
+// ServeHTTP routes a request to the endpoint for its path and method
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	debug.DebugMessage(s.debugFlag, r.Method+" "+r.URL.String())
+
+	if !s.allowedHost(r) {
+		writeError(w, &apiError{http.StatusForbidden, "requests for the host '" + r.Host + "' are not allowed"})
+		return
+	}
+	if !sameOrigin(r) {
+		writeError(w, &apiError{http.StatusForbidden, "requests from other sites are not allowed"})
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, APIPrefix+"/")
+	if !ok {
+		s.serveUI(w, r)
+		return
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "tickets":
+		err = route(w, r, map[string]func() error{
+			http.MethodGet:  func() error { return s.listTickets(w, r) },
+			http.MethodPost: func() error { return s.createTicket(w, r) },
+		})
+	case len(parts) >= 2 && parts[0] == "tickets":
+		var ticketID int
+		ticketID, err = strconv.Atoi(parts[1])
+		if err != nil || ticketID < 1 {
+			err = &apiError{http.StatusNotFound, "invalid ticket ID '" + parts[1] + "'"}
+			break
+		}
+		err = s.routeTicket(w, r, ticketID, parts[2:])
+	case len(parts) == 1 && parts[0] == "filters":
+		err = route(w, r, map[string]func() error{
+			http.MethodGet:  func() error { return s.listFilters(w) },
+			http.MethodPost: func() error { return s.createFilter(w, r) },
+		})
+	case len(parts) == 2 && parts[0] == "filters":
+		name := parts[1]
+		err = route(w, r, map[string]func() error{
+			http.MethodGet:    func() error { return s.getFilter(w, name) },
+			http.MethodPut:    func() error { return s.putFilter(w, r, name) },
+			http.MethodDelete: func() error { return s.deleteFilter(w, name) },
+		})
+	default:
+		err = &apiError{http.StatusNotFound, "not found"}
+	}
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// routeTicket routes a request for a ticket, or for one of its comments,
+// labels, or its status, to its endpoint
+func (s *Server) routeTicket(w http.ResponseWriter, r *http.Request, ticketID int, parts []string) error {
+	switch {
+	case len(parts) == 0:
+		return route(w, r, map[string]func() error{
+			http.MethodGet:    func() error { return s.getTicket(w, ticketID) },
+			http.MethodPatch:  func() error { return s.updateTicket(w, r, ticketID) },
+			http.MethodDelete: func() error { return s.deleteTicket(w, r, ticketID) },
+		})
+	case len(parts) == 1 && parts[0] == "comments":
+		return route(w, r, map[string]func() error{
+			http.MethodPost: func() error { return s.addComment(w, r, ticketID) },
+		})
+	case len(parts) == 1 && parts[0] == "labels":
+		return route(w, r, map[string]func() error{
+			http.MethodPost: func() error { return s.addLabel(w, r, ticketID) },
+		})
+	case len(parts) == 2 && parts[0] == "labels":
+		return route(w, r, map[string]func() error{
+			http.MethodDelete: func() error { return s.deleteLabel(w, r, ticketID, parts[1]) },
+		})
+	case len(parts) == 1 && parts[0] == "status":
+		return route(w, r, map[string]func() error{
+			http.MethodPut: func() error { return s.setStatus(w, r, ticketID) },
+		})
+	}
+	return &apiError{http.StatusNotFound, "not found"}
+}
+
+// allowedHost returns true if the request's Host is the address the server
+// listens on or a loopback name. Otherwise a page from another site could
+// make its own name resolve to this machine, and the browser would treat the
+// server as the same origin as the page and let it read and change tickets.
+func (s *Server) allowedHost(r *http.Request) bool {
+	if strings.EqualFold(r.Host, s.addr) {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin returns false if the request changes something and was sent by
+// a page from another site, which could otherwise use the browser of someone
+// running giticket serve to change their tickets
+func sameOrigin(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	return err == nil && originURL.Host == r.Host
+}
+
+// route calls the endpoint for the request's method, or reports that the
+// method isn't allowed
+func route(w http.ResponseWriter, r *http.Request, endpoints map[string]func() error) error {
+	endpoint, ok := endpoints[r.Method]
+	if !ok {
+		var allowed []string
+		for method := range endpoints {
+			allowed = append(allowed, method)
+		}
+		w.Header().Set("Allow", strings.Join(sortedMethods(allowed), ", "))
+		return &apiError{http.StatusMethodNotAllowed, "method " + r.Method + " is not allowed"}
+	}
+	return endpoint()
+}
+
+// sortedMethods returns HTTP methods in a stable order for the Allow header
+func sortedMethods(methods []string) []string {
+	var sorted []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		for _, m := range methods {
+			if m == method {
+				sorted = append(sorted, m)
+			}
+		}
+	}
+	return sorted
+}
+
+// readJSON decodes the body of a request into v, rejecting fields v doesn't
+// have
+func readJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		return &apiError{http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err)}
+	}
+	return nil
+}
+
+// writeJSON writes v to w as JSON with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err to w as a JSON object such as {"error": "not found"},
+// with the status of an apiError, or 500 for any other error
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		status = apiErr.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
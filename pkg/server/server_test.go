@@ -0,0 +1,365 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// do sends a request to the server and returns the response
+func do(s *Server, method string, target string, body string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Host = DefaultAddr
+	for k, v := range header {
+		if k == "Host" {
+			r.Host = v
+			continue
+		}
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// decode decodes the body of a response into v
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	err := json.Unmarshal(w.Body.Bytes(), v)
+	if err != nil {
+		t.Fatalf("%s: %q", err, w.Body.String())
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	tests := []struct {
+		ifMatch string
+		want    bool
+	}{
+		{`"abc"`, true},
+		{`"def", "abc"`, true},
+		{`*`, true},
+		{`"def"`, false},
+		{`W/"abc"`, false},
+		{`abc`, false},
+	}
+	for _, test := range tests {
+		if got := MatchesETag(test.ifMatch, "abc"); got != test.want {
+			t.Errorf("MatchesETag(%q) = %t, want %t", test.ifMatch, got, test.want)
+		}
+	}
+}
+
+func TestServeHTTPRouting(t *testing.T) {
+	s := New(false)
+	tests := []struct {
+		method string
+		target string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, APIPrefix + "/nothing", http.StatusNotFound, ""},
+		{http.MethodGet, APIPrefix + "/tickets/abc", http.StatusNotFound, ""},
+		{http.MethodGet, APIPrefix + "/tickets/0", http.StatusNotFound, ""},
+		{http.MethodGet, APIPrefix + "/tickets/1/nothing", http.StatusNotFound, ""},
+		{http.MethodPut, APIPrefix + "/tickets", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodPost, APIPrefix + "/tickets/1", http.StatusMethodNotAllowed, "GET, PATCH, DELETE"},
+		{http.MethodGet, APIPrefix + "/tickets/1/status", http.StatusMethodNotAllowed, "PUT"},
+		{http.MethodPatch, APIPrefix + "/filters/open", http.StatusMethodNotAllowed, "GET, PUT, DELETE"},
+	}
+	for _, test := range tests {
+		w := do(s, test.method, test.target, "", nil)
+		if w.Code != test.status {
+			t.Errorf("%s %s: status %d, want %d", test.method, test.target, w.Code, test.status)
+		}
+		if got := w.Header().Get("Allow"); got != test.allow {
+			t.Errorf("%s %s: Allow %q, want %q", test.method, test.target, got, test.allow)
+		}
+		var body map[string]string
+		decode(t, w, &body)
+		if body["error"] == "" {
+			t.Errorf("%s %s: no error in body %q", test.method, test.target, w.Body.String())
+		}
+	}
+}
+
+func TestServeHTTPHost(t *testing.T) {
+	s := New(false)
+	tests := []struct {
+		method string
+		header map[string]string
+		status int
+	}{
+		// A page from another site that made its name resolve to this machine
+		{http.MethodGet, map[string]string{"Host": "evil.example:8080"}, http.StatusForbidden},
+		{http.MethodPost, map[string]string{"Host": "evil.example:8080", "Origin": "http://evil.example:8080"}, http.StatusForbidden},
+		// Allowed through to not find the endpoint
+		{http.MethodGet, map[string]string{"Host": "localhost:8080"}, http.StatusNotFound},
+		{http.MethodGet, map[string]string{"Host": "127.0.0.1:9000"}, http.StatusNotFound},
+		{http.MethodGet, map[string]string{"Host": "[::1]:8080"}, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := do(s, test.method, APIPrefix+"/nothing", "", test.header)
+		if w.Code != test.status {
+			t.Errorf("%s %v: status %d, want %d", test.method, test.header, w.Code, test.status)
+		}
+	}
+
+	s.addr = "tickets.lan:8080"
+	if w := do(s, http.MethodGet, APIPrefix+"/nothing", "", map[string]string{"Host": "tickets.lan:8080"}); w.Code != http.StatusNotFound {
+		t.Errorf("the listen address: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeHTTPCrossOrigin(t *testing.T) {
+	s := New(false)
+	tests := []struct {
+		header map[string]string
+		status int
+	}{
+		{map[string]string{"Origin": "https://attacker.example"}, http.StatusForbidden},
+		{map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		// Allowed through to be rejected for its empty body
+		{map[string]string{"Origin": "http://" + DefaultAddr}, http.StatusBadRequest},
+		{nil, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		w := do(s, http.MethodPost, APIPrefix+"/tickets", "", test.header)
+		if w.Code != test.status {
+			t.Errorf("%v: status %d, want %d", test.header, w.Code, test.status)
+		}
+	}
+}
+
+func TestServeHTTPInvalidBody(t *testing.T) {
+	s := New(false)
+	for _, body := range []string{"", "{", `{"Title": "x", "Colour": "blue"}`} {
+		w := do(s, http.MethodPost, APIPrefix+"/tickets", body, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	w := do(s, http.MethodPost, APIPrefix+"/tickets", `{"Title": " "}`, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty title: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestValidateAssignees(t *testing.T) {
+	assignees, err := validateAssignees([]string{" John Smith <jsmith@example.com> ", "John <JSmith@example.com>", "Jane Doe <jdoe@example.com>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"John Smith <jsmith@example.com>", "Jane Doe <jdoe@example.com>"}
+	if strings.Join(assignees, "|") != strings.Join(expected, "|") {
+		t.Errorf("validateAssignees returned %q, want %q", assignees, expected)
+	}
+
+	_, err = validateAssignees([]string{"John Smith <jsmith@example.com>", "jsmith"})
+	if apiErr, ok := err.(*apiError); !ok || apiErr.status != http.StatusBadRequest {
+		t.Errorf("Expected a 400 error for an invalid assignee, got %v", err)
+	}
+}
+
+func TestHandleServerTickets(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(true)
+
+	// Create
+	w := do(s, http.MethodPost, APIPrefix+"/tickets", `{"Title": "Reverse the polarity", "Labels": ["tardis"], "Priority": 2}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	var created ticket.Ticket
+	decode(t, w, &created)
+	if created.ID != 2 || created.Priority != 2 || created.Severity != 1 || created.Status != "new" {
+		t.Fatalf("create: unexpected ticket %+v", created)
+	}
+	if w.Header().Get("Location") != APIPrefix+"/tickets/2" {
+		t.Errorf("create: Location %q", w.Header().Get("Location"))
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("create: no ETag")
+	}
+
+	// Show
+	w = do(s, http.MethodGet, APIPrefix+"/tickets/2", "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+		t.Fatalf("show: status %d, ETag %q, want %q", w.Code, w.Header().Get("ETag"), etag)
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/tickets/99", "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("show missing: status %d", w.Code)
+	}
+
+	// Update with the current version, renaming the ticket
+	w = do(s, http.MethodPatch, APIPrefix+"/tickets/2", `{"Title": "Invert the polarity", "Severity": 3}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status %d: %s", w.Code, w.Body.String())
+	}
+	var updated ticket.Ticket
+	decode(t, w, &updated)
+	if updated.Title != "Invert the polarity" || updated.Severity != 3 || updated.Priority != 2 {
+		t.Errorf("update: unexpected ticket %+v", updated)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("update: ETag didn't change")
+	}
+
+	// Assignees must be in "Name <email>" form
+	w = do(s, http.MethodPatch, APIPrefix+"/tickets/2", `{"Assignees": ["jsmith"]}`, map[string]string{"If-Match": "*"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid assignee: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// The old version is refused
+	w = do(s, http.MethodPatch, APIPrefix+"/tickets/2", `{"Priority": 5}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale update: status %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+
+	// The rename left only one copy of the ticket
+	w = do(s, http.MethodGet, APIPrefix+"/tickets", "", nil)
+	var tickets []ticket.Ticket
+	decode(t, w, &tickets)
+	if len(tickets) != 2 {
+		t.Fatalf("list: got %d tickets, want 2", len(tickets))
+	}
+
+	// Comment, label, and status
+	w = do(s, http.MethodPost, APIPrefix+"/tickets/2/comments", `{"Body": "Polarity inverted"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("comment: status %d: %s", w.Code, w.Body.String())
+	}
+	var comment ticket.Comment
+	decode(t, w, &comment)
+	if comment.Body != "Polarity inverted" {
+		t.Errorf("comment: unexpected comment %+v", comment)
+	}
+	w = do(s, http.MethodPost, APIPrefix+"/tickets/2/labels", `{"Label": "urgent"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("label: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodDelete, APIPrefix+"/tickets/2/labels/tardis", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlabel: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodDelete, APIPrefix+"/tickets/2/labels/tardis", "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unlabel missing label: status %d", w.Code)
+	}
+	w = do(s, http.MethodPut, APIPrefix+"/tickets/2/status", `{"Status": "closed"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: status %d: %s", w.Code, w.Body.String())
+	}
+	decode(t, w, &updated)
+	if updated.Status != "closed" || len(updated.Comments) != 1 || len(updated.Labels) != 1 || updated.Labels[0] != "urgent" {
+		t.Errorf("status: unexpected ticket %+v", updated)
+	}
+
+	// Query and page the list
+	w = do(s, http.MethodGet, APIPrefix+"/tickets?q="+url.QueryEscape(`.[] | select(.Status == "closed")`), "", nil)
+	decode(t, w, &tickets)
+	if len(tickets) != 1 || tickets[0].ID != 2 {
+		t.Errorf("query: got %+v", tickets)
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/tickets?sort=-id&limit=1", "", nil)
+	decode(t, w, &tickets)
+	if len(tickets) != 1 || tickets[0].ID != 2 {
+		t.Errorf("sort and limit: got %+v", tickets)
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/tickets?limit=x", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status %d", w.Code)
+	}
+
+	// Delete
+	w = do(s, http.MethodDelete, APIPrefix+"/tickets/2", "", map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale delete: status %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+	w = do(s, http.MethodDelete, APIPrefix+"/tickets/2", "", map[string]string{"If-Match": "*"})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/tickets/2", "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("show deleted: status %d", w.Code)
+	}
+}
+
+func TestHandleServerFilters(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(true)
+
+	// No filters yet
+	w := do(s, http.MethodGet, APIPrefix+"/filters", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: status %d: %s", w.Code, w.Body.String())
+	}
+	var filters ticket.FilterList
+	decode(t, w, &filters)
+	if len(filters.Filters) != 0 {
+		t.Errorf("list: got %+v", filters)
+	}
+
+	w = do(s, http.MethodPost, APIPrefix+"/filters", `{"Name": "open", "Filter": ".[] | select(.Status != \"closed\")"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodPost, APIPrefix+"/filters", `{"Name": "open", "Filter": "."}`, nil)
+	if w.Code != http.StatusConflict {
+		t.Errorf("create existing: status %d", w.Code)
+	}
+	w = do(s, http.MethodPut, APIPrefix+"/filters/broken", `{"Filter": "map(("}`, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid filter: status %d", w.Code)
+	}
+
+	w = do(s, http.MethodGet, APIPrefix+"/tickets?filter=open", "", nil)
+	var tickets []ticket.Ticket
+	decode(t, w, &tickets)
+	if len(tickets) != 1 {
+		t.Errorf("list with filter: got %+v", tickets)
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/tickets?filter=missing", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("list with missing filter: status %d", w.Code)
+	}
+
+	w = do(s, http.MethodPut, APIPrefix+"/filters/open", `{"Filter": ".[] | select(.Status == \"closed\")"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("replace: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/filters/open", "", nil)
+	var filter ticket.Filter
+	decode(t, w, &filter)
+	if filter.Filter != `.[] | select(.Status == "closed")` {
+		t.Errorf("get: got %+v", filter)
+	}
+
+	w = do(s, http.MethodDelete, APIPrefix+"/filters/open", "", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d: %s", w.Code, w.Body.String())
+	}
+	w = do(s, http.MethodGet, APIPrefix+"/filters/open", "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: status %d", w.Code)
+	}
+}
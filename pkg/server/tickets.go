@@ -0,0 +1,466 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// createRequest is the body of a request to create a ticket. Priority and
+// severity default to 1, and status to "new".
+type createRequest struct {
+	Title       string
+	Description string
+	Labels      []string
+	Priority    *int
+	Severity    *int
+	Status      string
+}
+
+// updateRequest is the body of a request to change a ticket, fields left out
+// are left unchanged
+type updateRequest struct {
+	Title       *string
+	Description *string
+	Priority    *int
+	Severity    *int
+	Status      *string
+	Labels      *[]string
+	Assignees   *[]string
+}
+
+// commentRequest is the body of a request to comment on a ticket
+type commentRequest struct {
+	Body string
+}
+
+// labelRequest is the body of a request to label a ticket
+type labelRequest struct {
+	Label string
+}
+
+// statusRequest is the body of a request to set a ticket's status
+type statusRequest struct {
+	Status string
+}
+
+// listTickets writes the tickets matching the request's filter and q query
+// parameters, sorted and paged by its sort, limit, and offset parameters
+func (s *Server) listTickets(w http.ResponseWriter, r *http.Request) error {
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, s.debugFlag)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	if filterName := query.Get("filter"); filterName != "" {
+		filters, err := ticket.GetFilters(common.BranchName, s.debugFlag)
+		if err != nil && !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return err
+		}
+		if _, ok := filters.Filters[filterName]; !ok {
+			return &apiError{http.StatusBadRequest, "unknown filter '" + filterName + "'"}
+		}
+		filtered, err := ticket.FilterTickets(tickets, filterName, s.debugFlag)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, err.Error()}
+		}
+		tickets = *filtered
+	}
+	if q := query.Get("q"); q != "" {
+		tickets, err = ticket.QueryTickets(tickets, q, s.debugFlag)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, err.Error()}
+		}
+	}
+
+	listOptions := ticket.ListOptions{SortBy: query.Get("sort")}
+	for name, value := range map[string]*int{"limit": &listOptions.Limit, "offset": &listOptions.Offset} {
+		if query.Get(name) == "" {
+			continue
+		}
+		*value, err = strconv.Atoi(query.Get(name))
+		if err != nil || *value < 0 {
+			return &apiError{http.StatusBadRequest, "invalid " + name + " '" + query.Get(name) + "'"}
+		}
+	}
+	tickets, err = ticket.ApplyListOptions(tickets, listOptions)
+	if err != nil {
+		return &apiError{http.StatusBadRequest, err.Error()}
+	}
+	return writeJSON(w, http.StatusOK, tickets)
+}
+
+// createTicket creates a ticket from the request body, and writes it with its
+// location
+func (s *Server) createTicket(w http.ResponseWriter, r *http.Request) error {
+	var req createRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	err = validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+	priority, severity, status := 1, 1, "new"
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
+	if req.Severity != nil {
+		severity = *req.Severity
+	}
+	if req.Status != "" {
+		status = req.Status
+	}
+	if req.Labels == nil {
+		req.Labels = []string{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ticketID, _, err := ticket.HandleCreate(common.BranchName, time.Now().Unix(), req.Title, req.Description, req.Labels, priority, severity, status, []ticket.Comment{}, 1, s.debugFlag)
+	if err != nil {
+		return err
+	}
+
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", APIPrefix+"/tickets/"+strconv.Itoa(ticketID))
+	return s.writeTicket(w, thisRepo, http.StatusCreated, t)
+}
+
+// getTicket writes a ticket with its version in the ETag header
+func (s *Server) getTicket(w http.ResponseWriter, ticketID int) error {
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	return s.writeTicket(w, thisRepo, http.StatusOK, t)
+}
+
+// updateTicket changes the fields of a ticket given in the request body in a
+// single commit, and writes the changed ticket
+func (s *Server) updateTicket(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	var req updateRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	if req.Title != nil && *req.Title != t.Title {
+		err = validateTitle(*req.Title)
+		if err != nil {
+			return err
+		}
+		// The ticket's file is named after its title
+		files[path.Join("tickets", t.TicketFilename())] = nil
+		t.Title = *req.Title
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.Severity != nil {
+		t.Severity = *req.Severity
+	}
+	if req.Labels != nil {
+		t.Labels = *req.Labels
+	}
+	if req.Assignees != nil {
+		t.Assignees, err = validateAssignees(*req.Assignees)
+		if err != nil {
+			return err
+		}
+	}
+	if req.Status != nil && *req.Status != t.Status {
+		t.Status = *req.Status
+		tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, s.debugFlag)
+		if err != nil {
+			return err
+		}
+		for _, parent := range ticket.SyncPromotedChecklistItems(t, tickets) {
+			files[path.Join("tickets", parent.TicketFilename())] = parent.TicketToYaml()
+		}
+	}
+	files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+
+	err = repo.CommitFiles(thisRepo, common.BranchName, files, "Updating ticket "+t.TicketFilename(), s.debugFlag)
+	if err != nil {
+		return err
+	}
+	return s.writeTicket(w, thisRepo, http.StatusOK, t)
+}
+
+// deleteTicket deletes a ticket
+func (s *Server) deleteTicket(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	_, err = ticket.HandleDelete(ticketID, common.BranchName, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// addComment comments on a ticket, and writes the new comment
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	var req commentRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return &apiError{http.StatusBadRequest, "the comment body can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	fullCommentID, err := ticket.HandleComment(common.BranchName, req.Body, 0, ticketID, false, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	_, t, err = s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	for _, comment := range t.Comments {
+		if strconv.Itoa(t.ID)+"-"+strconv.Itoa(comment.ID) == fullCommentID {
+			return writeJSON(w, http.StatusCreated, comment)
+		}
+	}
+	return fmt.Errorf("comment %s was not found after adding it", fullCommentID)
+}
+
+// addLabel adds a label to a ticket, and writes the ticket. Adding a label the
+// ticket already has changes nothing.
+func (s *Server) addLabel(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	var req labelRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Label) == "" {
+		return &apiError{http.StatusBadRequest, "the label can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(t.Labels, req.Label) {
+		err = ticket.HandleLabel(common.BranchName, req.Label, false, ticketID, s.debugFlag)
+		if err != nil {
+			return err
+		}
+		_, t, err = s.loadTicket(ticketID)
+		if err != nil {
+			return err
+		}
+	}
+	return s.writeTicket(w, thisRepo, http.StatusOK, t)
+}
+
+// deleteLabel removes a label from a ticket, and writes the ticket
+func (s *Server) deleteLabel(w http.ResponseWriter, r *http.Request, ticketID int, label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(t.Labels, label) {
+		return &apiError{http.StatusNotFound, fmt.Sprintf("ticket %d doesn't have the label '%s'", ticketID, label)}
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	err = ticket.HandleLabel(common.BranchName, label, true, ticketID, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	_, t, err = s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	return s.writeTicket(w, thisRepo, http.StatusOK, t)
+}
+
+// setStatus sets the status of a ticket, and writes the ticket
+func (s *Server) setStatus(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	var req statusRequest
+	err := readJSON(r, &req)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Status) == "" {
+		return &apiError{http.StatusBadRequest, "the status can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	err = s.checkIfMatch(r, thisRepo, t)
+	if err != nil {
+		return err
+	}
+
+	err = ticket.HandleStatus(req.Status, ticketID, false, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	_, t, err = s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	return s.writeTicket(w, thisRepo, http.StatusOK, t)
+}
+
+// loadTicket opens the repository and returns it with the ticket, or a 404
+// error if the ticket doesn't exist
+func (s *Server) loadTicket(ticketID int) (*git.Repository, ticket.Ticket, error) {
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return nil, ticket.Ticket{}, err
+	}
+	tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, s.debugFlag)
+	if err != nil {
+		return nil, ticket.Ticket{}, err
+	}
+	t := ticket.FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return nil, ticket.Ticket{}, &apiError{http.StatusNotFound, fmt.Sprintf("ticket %d not found", ticketID)}
+	}
+	return thisRepo, t, nil
+}
+
+// writeTicket writes a ticket with the given status, and its version in the
+// ETag header
+func (s *Server) writeTicket(w http.ResponseWriter, thisRepo *git.Repository, status int, t ticket.Ticket) error {
+	version, err := ticket.TicketVersion(thisRepo, common.BranchName, t, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", strconv.Quote(version))
+	return writeJSON(w, status, t)
+}
+
+// checkIfMatch returns a 412 error if the request has an If-Match header that
+// doesn't match the ticket's current version, meaning the ticket has changed
+// since the client read it. Requests without If-Match are always allowed.
+func (s *Server) checkIfMatch(r *http.Request, thisRepo *git.Repository, t ticket.Ticket) error {
+	return s.checkVersion(thisRepo, t, r.Header.Get("If-Match"))
+}
+
+// checkVersion returns a 412 error if ifMatch, in the form of an If-Match
+// header, is set and doesn't match the ticket's current version
+func (s *Server) checkVersion(thisRepo *git.Repository, t ticket.Ticket, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+	version, err := ticket.TicketVersion(thisRepo, common.BranchName, t, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	if !MatchesETag(ifMatch, version) {
+		return &apiError{http.StatusPreconditionFailed, fmt.Sprintf("ticket %d has changed since it was read, fetch it again", t.ID)}
+	}
+	return nil
+}
+
+// MatchesETag takes the value of an If-Match header, such as "abc", "abc",
+// "def" or *, and the current version of a resource, and returns true if the
+// header matches the version. Weak ETags never match.
+func MatchesETag(ifMatch string, version string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == strconv.Quote(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTitle returns a 400 error if title can't be used as a ticket's
+// title
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return &apiError{http.StatusBadRequest, "the title can't be empty"}
+	}
+	return nil
+}
+
+// validateAssignees takes a list of assignees in "Name <email>" form and
+// returns them normalized, without repeating anyone's email address. Returns
+// an error if an assignee isn't in that form.
+func validateAssignees(assignees []string) ([]string, error) {
+	var t ticket.Ticket
+	for _, assignee := range assignees {
+		identity, _, err := ticket.ParseIdentity(assignee)
+		if err != nil {
+			return nil, &apiError{http.StatusBadRequest, err.Error()}
+		}
+		ticket.AssignTicket(&t, []string{identity})
+	}
+	return t.Assignees, nil
+}
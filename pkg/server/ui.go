@@ -0,0 +1,352 @@
+package server
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// uiFiles holds the pages and stylesheet of the web UI, so the UI works
+// without any files besides the binary and without a network connection
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiTemplates are the pages of the web UI, each one a template named after
+// its file, eg: "list.html"
+var uiTemplates = template.Must(template.New("ui").Funcs(template.FuncMap{
+	"markdown": func(s string) template.HTML { return template.HTML(ticket.RenderMarkdownHTML(s)) },
+	"time":     func(unix int64) string { return time.Unix(unix, 0).Format("2006-01-02 15:04:05 -0700") },
+	"join":     strings.Join,
+}).ParseFS(uiFiles, "ui/*.html"))
+
+// listPage is the data for the ticket list page
+type listPage struct {
+	Tickets []ticket.Ticket
+	Filters []string
+	Filter  string
+	Query   string
+}
+
+// ticketPage is the data for the page showing a ticket. Version is sent back
+// with each change so a change made to a ticket someone else has changed
+// since the page was loaded is refused.
+type ticketPage struct {
+	Ticket   ticket.Ticket
+	Version  string
+	Statuses []string
+}
+
+// errorPage is the data for the page showing an error
+type errorPage struct {
+	Status  string
+	Message string
+}
+
+// serveUI routes a request for a page of the web UI, or for a form on one of
+// them, and writes any error as a page
+func (s *Server) serveUI(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	var err error
+	switch {
+	case r.URL.Path == "/":
+		err = route(w, r, map[string]func() error{
+			http.MethodGet: func() error { return s.listPage(w, r) },
+		})
+	case r.URL.Path == "/new":
+		err = route(w, r, map[string]func() error{
+			http.MethodGet:  func() error { return renderPage(w, http.StatusOK, "new.html", nil) },
+			http.MethodPost: func() error { return s.createForm(w, r) },
+		})
+	case len(parts) == 2 && parts[0] == "static":
+		err = route(w, r, map[string]func() error{
+			http.MethodGet: func() error { return serveStatic(w, parts[1]) },
+		})
+	case len(parts) >= 2 && parts[0] == "tickets":
+		var ticketID int
+		ticketID, err = strconv.Atoi(parts[1])
+		if err != nil || ticketID < 1 {
+			err = &apiError{http.StatusNotFound, "invalid ticket ID '" + parts[1] + "'"}
+			break
+		}
+		err = s.routeTicketPage(w, r, ticketID, parts[2:])
+	default:
+		err = &apiError{http.StatusNotFound, "page not found"}
+	}
+	if err != nil {
+		writeErrorPage(w, err)
+	}
+}
+
+// routeTicketPage routes a request for the page showing a ticket, or for a
+// form on that page
+func (s *Server) routeTicketPage(w http.ResponseWriter, r *http.Request, ticketID int, parts []string) error {
+	if len(parts) == 0 {
+		return route(w, r, map[string]func() error{
+			http.MethodGet: func() error { return s.ticketPage(w, ticketID) },
+		})
+	}
+	forms := map[string]func(http.ResponseWriter, *http.Request, int) error{
+		"comments": s.commentForm,
+		"status":   s.statusForm,
+		"labels":   s.labelForm,
+	}
+	form, ok := forms[parts[0]]
+	if len(parts) != 1 || !ok {
+		return &apiError{http.StatusNotFound, "page not found"}
+	}
+	return route(w, r, map[string]func() error{
+		http.MethodPost: func() error { return form(w, r, ticketID) },
+	})
+}
+
+// listPage shows the tickets matching a saved filter and a query
+func (s *Server) listPage(w http.ResponseWriter, r *http.Request) error {
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	filters, err := s.loadFilters()
+	if err != nil {
+		return err
+	}
+
+	page := listPage{Filter: r.URL.Query().Get("filter"), Query: r.URL.Query().Get("q")}
+	for name := range filters.Filters {
+		page.Filters = append(page.Filters, name)
+	}
+	sort.Strings(page.Filters)
+
+	if page.Filter != "" {
+		if _, ok := filters.Filters[page.Filter]; !ok {
+			return &apiError{http.StatusBadRequest, "unknown filter '" + page.Filter + "'"}
+		}
+		filtered, err := ticket.FilterTickets(tickets, page.Filter, s.debugFlag)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, err.Error()}
+		}
+		tickets = *filtered
+	}
+	if page.Query != "" {
+		tickets, err = ticket.QueryTickets(tickets, page.Query, s.debugFlag)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, err.Error()}
+		}
+	}
+	page.Tickets = tickets
+	return renderPage(w, http.StatusOK, "list.html", page)
+}
+
+// ticketPage shows a ticket with forms to comment on it and change it
+func (s *Server) ticketPage(w http.ResponseWriter, ticketID int) error {
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	version, err := ticket.TicketVersion(thisRepo, common.BranchName, t, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	return renderPage(w, http.StatusOK, "ticket.html", ticketPage{Ticket: t, Version: version, Statuses: knownStatuses(tickets)})
+}
+
+// createForm creates a ticket from the new ticket form, and shows it
+func (s *Server) createForm(w http.ResponseWriter, r *http.Request) error {
+	title := r.PostFormValue("title")
+	err := validateTitle(title)
+	if err != nil {
+		return err
+	}
+	numbers := make(map[string]int)
+	for _, name := range []string{"priority", "severity"} {
+		numbers[name] = 1
+		if value := strings.TrimSpace(r.PostFormValue(name)); value != "" {
+			numbers[name], err = strconv.Atoi(value)
+			if err != nil {
+				return &apiError{http.StatusBadRequest, "invalid " + name + " '" + value + "'"}
+			}
+		}
+	}
+	status := strings.TrimSpace(r.PostFormValue("status"))
+	if status == "" {
+		status = "new"
+	}
+	labels := []string{}
+	for _, label := range strings.Split(r.PostFormValue("labels"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ticketID, _, err := ticket.HandleCreate(common.BranchName, time.Now().Unix(), title, r.PostFormValue("description"), labels, numbers["priority"], numbers["severity"], status, []ticket.Comment{}, 1, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	http.Redirect(w, r, "/tickets/"+strconv.Itoa(ticketID), http.StatusSeeOther)
+	return nil
+}
+
+// commentForm comments on a ticket, and shows it again. Comments never
+// conflict with other changes, so the ticket's version isn't checked.
+func (s *Server) commentForm(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	body := r.PostFormValue("body")
+	if strings.TrimSpace(body) == "" {
+		return &apiError{http.StatusBadRequest, "the comment can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _, err := s.loadTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	_, err = ticket.HandleComment(common.BranchName, body, 0, ticketID, false, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	http.Redirect(w, r, "/tickets/"+strconv.Itoa(ticketID), http.StatusSeeOther)
+	return nil
+}
+
+// statusForm sets the status of a ticket, and shows it again
+func (s *Server) statusForm(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	status := strings.TrimSpace(r.PostFormValue("status"))
+	if status == "" {
+		return &apiError{http.StatusBadRequest, "the status can't be empty"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.loadTicketVersion(r, ticketID)
+	if err != nil {
+		return err
+	}
+	err = ticket.HandleStatus(status, ticketID, false, s.debugFlag)
+	if err != nil {
+		return err
+	}
+	http.Redirect(w, r, "/tickets/"+strconv.Itoa(ticketID), http.StatusSeeOther)
+	return nil
+}
+
+// labelForm adds a label to a ticket, or removes it if the form's remove field
+// is set, and shows the ticket again
+func (s *Server) labelForm(w http.ResponseWriter, r *http.Request, ticketID int) error {
+	label := strings.TrimSpace(r.PostFormValue("label"))
+	if label == "" {
+		return &apiError{http.StatusBadRequest, "the label can't be empty"}
+	}
+	remove := r.PostFormValue("remove") != ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.loadTicketVersion(r, ticketID)
+	if err != nil {
+		return err
+	}
+	// Adding a label the ticket has, or removing one it doesn't, changes
+	// nothing
+	if slices.Contains(t.Labels, label) == remove {
+		err = ticket.HandleLabel(common.BranchName, label, remove, ticketID, s.debugFlag)
+		if err != nil {
+			return err
+		}
+	}
+	http.Redirect(w, r, "/tickets/"+strconv.Itoa(ticketID), http.StatusSeeOther)
+	return nil
+}
+
+// loadTicketVersion returns a ticket, or a 412 error if it has changed since
+// the version in the request's form was shown
+func (s *Server) loadTicketVersion(r *http.Request, ticketID int) (ticket.Ticket, error) {
+	thisRepo, t, err := s.loadTicket(ticketID)
+	if err != nil {
+		return t, err
+	}
+	if version := r.PostFormValue("version"); version != "" {
+		err = s.checkVersion(thisRepo, t, strconv.Quote(version))
+		if err != nil {
+			return t, &apiError{http.StatusPreconditionFailed, "ticket " + strconv.Itoa(ticketID) + " has changed since the page was loaded, go back and reload it to see the changes"}
+		}
+	}
+	return t, nil
+}
+
+// knownStatuses returns the statuses to suggest when changing a ticket's
+// status, the ones giticket uses itself and the ones used by tickets
+func knownStatuses(tickets []ticket.Ticket) []string {
+	statuses := []string{"new", ticket.DefaultStartStatus, ticket.DefaultReviewStatus}
+	statuses = append(statuses, ticket.ClosedStatuses...)
+	for _, t := range tickets {
+		if t.Status != "" && !slices.Contains(statuses, t.Status) {
+			statuses = append(statuses, t.Status)
+		}
+	}
+	return statuses
+}
+
+// serveStatic writes a file from the ui directory that isn't a template, such
+// as the stylesheet
+func serveStatic(w http.ResponseWriter, name string) error {
+	if strings.HasSuffix(name, ".html") {
+		return &apiError{http.StatusNotFound, "page not found"}
+	}
+	contents, err := fs.ReadFile(uiFiles, path.Join("ui", name))
+	if err != nil {
+		return &apiError{http.StatusNotFound, "page not found"}
+	}
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+	_, err = w.Write(contents)
+	return err
+}
+
+// renderPage writes the page named name with the given status and data
+func renderPage(w http.ResponseWriter, status int, name string, data interface{}) error {
+	var page strings.Builder
+	err := uiTemplates.ExecuteTemplate(&page, name, data)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write([]byte(page.String()))
+	return err
+}
+
+// writeErrorPage writes err as a page, with the status of an apiError, or 500
+// for any other error
+func writeErrorPage(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		status = apiErr.status
+	}
+	renderPage(w, status, "error.html", errorPage{
+		Status:  strconv.Itoa(status) + " " + http.StatusText(status),
+		Message: err.Error(),
+	})
+}
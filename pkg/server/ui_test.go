@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// form is the header of a request sending a form
+var form = map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+
+func TestServeUIPages(t *testing.T) {
+	s := New(false)
+	tests := []struct {
+		method      string
+		target      string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{http.MethodGet, "/new", http.StatusOK, "text/html; charset=utf-8", `<form class="new" method="post" action="/new">`},
+		{http.MethodGet, "/static/style.css", http.StatusOK, "text/css; charset=utf-8", "body {"},
+		{http.MethodGet, "/static/list.html", http.StatusNotFound, "text/html; charset=utf-8", "page not found"},
+		{http.MethodGet, "/static/missing.css", http.StatusNotFound, "text/html; charset=utf-8", "page not found"},
+		{http.MethodGet, "/nothing", http.StatusNotFound, "text/html; charset=utf-8", "404 Not Found"},
+		{http.MethodGet, "/tickets/abc", http.StatusNotFound, "text/html; charset=utf-8", "invalid ticket ID"},
+		{http.MethodGet, "/tickets/1/status", http.StatusMethodNotAllowed, "text/html; charset=utf-8", "method GET is not allowed"},
+		{http.MethodPost, "/new", http.StatusBadRequest, "text/html; charset=utf-8", "the title can&#39;t be empty"},
+	}
+	for _, test := range tests {
+		w := do(s, test.method, test.target, "", form)
+		if w.Code != test.status {
+			t.Errorf("%s %s: status %d, want %d", test.method, test.target, w.Code, test.status)
+		}
+		if got := w.Header().Get("Content-Type"); got != test.contentType {
+			t.Errorf("%s %s: Content-Type %q, want %q", test.method, test.target, got, test.contentType)
+		}
+		if !strings.Contains(w.Body.String(), test.contains) {
+			t.Errorf("%s %s: body doesn't contain %q:\n%s", test.method, test.target, test.contains, w.Body.String())
+		}
+	}
+}
+
+func TestHandleServeUI(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(true)
+
+	// Create a ticket with the new ticket form
+	values := url.Values{"title": {"Reverse the polarity"}, "description": {"Use the *sonic* screwdriver"}, "labels": {"tardis, urgent"}}
+	w := do(s, http.MethodPost, "/new", values.Encode(), form)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/tickets/2" {
+		t.Fatalf("create: status %d, Location %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+
+	// The description is rendered as markdown
+	w = do(s, http.MethodGet, "/tickets/2", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("show: status %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "Use the <em>sonic</em> screwdriver") {
+		t.Errorf("show: description not rendered:\n%s", w.Body.String())
+	}
+
+	// Changes made with an old version of the page are refused
+	w = do(s, http.MethodPost, "/tickets/2/status", url.Values{"status": {"closed"}, "version": {"0000"}}.Encode(), form)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale status: status %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+
+	for _, change := range []struct {
+		target string
+		values url.Values
+	}{
+		{"/tickets/2/status", url.Values{"status": {"in progress"}}},
+		{"/tickets/2/labels", url.Values{"label": {"sonic"}}},
+		{"/tickets/2/labels", url.Values{"label": {"urgent"}, "remove": {"true"}}},
+		{"/tickets/2/comments", url.Values{"body": {"Polarity **reversed**"}}},
+	} {
+		w = do(s, http.MethodPost, change.target, change.values.Encode(), form)
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("%s %v: status %d: %s", change.target, change.values, w.Code, w.Body.String())
+		}
+	}
+
+	_, ticket, err := s.loadTicket(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Status != "in progress" || strings.Join(ticket.Labels, ",") != "tardis,sonic" || len(ticket.Comments) != 1 {
+		t.Errorf("unexpected ticket after changes: %+v", ticket)
+	}
+
+	// The list shows the ticket, and can be narrowed with a query
+	w = do(s, http.MethodGet, "/?q="+url.QueryEscape(`.[] | select(.Status == "in progress")`), "", nil)
+	if !strings.Contains(w.Body.String(), `<a href="/tickets/2">Reverse the polarity</a>`) || strings.Contains(w.Body.String(), `href="/tickets/1"`) {
+		t.Errorf("list: unexpected page:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,236 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// HandleAssign takes a branch name, a list of people, an unassign flag, a
+// ticket ID, and a debug flag. It assigns the people to the ticket, or
+// unassigns them if unassignFlag is true, and commits the change. Each person
+// may be given as "Name <email>", or as just the email address or name of
+// someone giticket already knows, see KnownIdentities. Returns an error if
+// there was one.
+func HandleAssign(branchName string, who []string, unassignFlag bool, ticketID int, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	known, err := KnownIdentities(thisRepo, branchName, tickets, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	var identities []string
+	for _, person := range who {
+		identity, err := ResolveIdentity(person, known)
+		if err != nil {
+			// Unassigning someone who is no longer known is fine
+			if !unassignFlag {
+				return err
+			}
+			identity = person
+		}
+		identities = append(identities, identity)
+	}
+
+	if unassignFlag {
+		UnassignTicket(&t, identities)
+		return repo.Commit(&t, thisRepo, branchName, author, "Unassigning "+strings.Join(identities, ", ")+" from ticket "+t.TicketFilename(), debugFlag)
+	}
+	AssignTicket(&t, identities)
+	return repo.Commit(&t, thisRepo, branchName, author, "Assigning "+strings.Join(identities, ", ")+" to ticket "+t.TicketFilename(), debugFlag)
+}
+
+// ParseIdentity takes an identity in "Name <email>" form, the same form as
+// Comment.Author, and returns it normalized along with the lower cased email
+// address used to compare identities. The name is everything before the last
+// '<', so it may contain commas and other punctuation as git author names
+// can, and only the email address is validated. The normalized identity
+// parses to itself. Returns an error if identity is not in that form.
+func ParseIdentity(identity string) (string, string, error) {
+	name, email, err := splitIdentity(identity)
+	if err != nil {
+		return "", "", err
+	}
+	return name + " <" + email + ">", strings.ToLower(email), nil
+}
+
+// splitIdentity returns the name and email address of an identity in
+// "Name <email>" form, see ParseIdentity
+func splitIdentity(identity string) (string, string, error) {
+	invalid := errors.New("invalid identity '" + identity + "', expected \"Name <email>\"")
+	identity = strings.TrimSpace(identity)
+	i := strings.LastIndex(identity, "<")
+	if i < 0 || !strings.HasSuffix(identity, ">") {
+		return "", "", invalid
+	}
+	name := strings.TrimSpace(identity[:i])
+	// Names quoted as in a mail header, eg: "Smith, John" <js@example.com>
+	if len(name) >= 2 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		name = strings.TrimSpace(name[1 : len(name)-1])
+	}
+	email := strings.TrimSpace(identity[i+1 : len(identity)-1])
+	address, err := mail.ParseAddress(email)
+	if err != nil || name == "" || address.Name != "" || address.Address != email {
+		return "", "", invalid
+	}
+	return name, email, nil
+}
+
+// ResolveIdentity takes a person given on the command line and a list of
+// known identities, and returns the person's identity in "Name <email>" form.
+// A person already in that form is returned as is, otherwise they are looked
+// up by email address or name in known. Returns an error if the person can't
+// be resolved to exactly one identity.
+func ResolveIdentity(person string, known []string) (string, error) {
+	person = strings.TrimSpace(person)
+	if identity, _, err := ParseIdentity(person); err == nil {
+		return identity, nil
+	}
+
+	var matches []string
+	for _, identity := range known {
+		name, email, err := splitIdentity(identity)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(email, person) || strings.EqualFold(name, person) {
+			matches = append(matches, identity)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", errors.New("unknown person '" + person + "', give them as \"Name <email>\"")
+	case 1:
+		return matches[0], nil
+	}
+	return "", errors.New("'" + person + "' matches more than one person: " + strings.Join(matches, ", "))
+}
+
+// KnownIdentities takes a pointer to a git repository, a branch name, a list
+// of tickets, and a debug flag. It returns the identities giticket knows
+// about: the current user, the authors of commits on branchName, and the
+// authors of comments on and assignees of the tickets, sorted and without
+// duplicates.
+func KnownIdentities(thisRepo *git.Repository, branchName string, tickets []Ticket, debugFlag bool) ([]string, error) {
+	debug.DebugMessage(debugFlag, "Looking up known identities")
+	seen := make(map[string]bool)
+	var known []string
+	add := func(identity string) {
+		identity, email, err := ParseIdentity(identity)
+		if err != nil || seen[email] {
+			return
+		}
+		seen[email] = true
+		known = append(known, identity)
+	}
+
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return nil, err
+	}
+	add(author.Name + " <" + author.Email + ">")
+
+	for _, t := range tickets {
+		for _, assignee := range t.Assignees {
+			add(assignee)
+		}
+		for _, comment := range t.Comments {
+			add(comment.Author)
+		}
+	}
+
+	walk, err := thisRepo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+	err = walk.PushRef("refs/heads/" + branchName)
+	if err != nil {
+		return nil, err
+	}
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		add(commit.Author().Name + " <" + commit.Author().Email + ">")
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(known)
+	return known, nil
+}
+
+// AssignTicket takes a pointer to a ticket and a list of identities, and adds
+// each identity to the ticket's assignees unless someone with the same email
+// address is already assigned
+func AssignTicket(t *Ticket, identities []string) {
+	for _, identity := range identities {
+		_, email, _ := ParseIdentity(identity)
+		if !IsAssignedTo(*t, email) {
+			t.Assignees = append(t.Assignees, identity)
+		}
+	}
+}
+
+// UnassignTicket takes a pointer to a ticket and a list of identities, and
+// removes every assignee with the same email address as one of the
+// identities. Identities may also be given as just an email address.
+func UnassignTicket(t *Ticket, identities []string) {
+	assignees := []string{}
+	for _, assignee := range t.Assignees {
+		_, assigneeEmail, _ := ParseIdentity(assignee)
+		keep := true
+		for _, identity := range identities {
+			_, email, err := ParseIdentity(identity)
+			if err != nil {
+				email = strings.ToLower(strings.TrimSpace(identity))
+			}
+			if email == assigneeEmail || identity == assignee {
+				keep = false
+			}
+		}
+		if keep {
+			assignees = append(assignees, assignee)
+		}
+	}
+	t.Assignees = assignees
+}
+
+// IsAssignedTo takes a ticket and an email address, and returns true if
+// someone with that email address is assigned to the ticket
+func IsAssignedTo(t Ticket, email string) bool {
+	for _, assignee := range t.Assignees {
+		_, assigneeEmail, err := ParseIdentity(assignee)
+		if err == nil && strings.EqualFold(assigneeEmail, email) {
+			return true
+		}
+	}
+	return false
+}
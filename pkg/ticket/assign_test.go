@@ -0,0 +1,141 @@
+package ticket
+
+import (
+	"reflect"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestResolveIdentity(t *testing.T) {
+	known := []string{"Alice Smith <alice@example.com>", "Bob Jones <bob@example.com>", "Bob Brown <bbrown@example.com>"}
+
+	testCases := []struct {
+		person      string
+		expected    string
+		expectedErr bool
+	}{
+		{person: "Carol King <carol@example.com>", expected: "Carol King <carol@example.com>"},
+		{person: "alice@example.com", expected: "Alice Smith <alice@example.com>"},
+		{person: "ALICE@example.com", expected: "Alice Smith <alice@example.com>"},
+		{person: "Bob Jones", expected: "Bob Jones <bob@example.com>"},
+		{person: "carol@example.com", expectedErr: true},
+		{person: "<carol@example.com>", expectedErr: true},
+		{person: "not an identity", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ResolveIdentity(tc.person, known)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ResolveIdentity(%q) returned error %v, expected an error: %t", tc.person, err, tc.expectedErr)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("ResolveIdentity(%q) = %q, want %q", tc.person, actual, tc.expected)
+		}
+	}
+}
+
+func TestParseIdentity(t *testing.T) {
+	testCases := []struct {
+		identity    string
+		expected    string
+		expectedErr bool
+	}{
+		{identity: "Alice Smith <alice@example.com>", expected: "Alice Smith <alice@example.com>"},
+		{identity: `"Smith, John" <js@example.com>`, expected: "Smith, John <js@example.com>"},
+		{identity: "Smith, John <js@example.com>", expected: "Smith, John <js@example.com>"},
+		{identity: "  O'Brien (ops) <ob@example.com> ", expected: "O'Brien (ops) <ob@example.com>"},
+		{identity: "<js@example.com>", expectedErr: true},
+		{identity: "John <not an address>", expectedErr: true},
+		{identity: "js@example.com", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		actual, email, err := ParseIdentity(tc.identity)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ParseIdentity(%q) returned error %v, expected an error: %t", tc.identity, err, tc.expectedErr)
+			continue
+		}
+		if tc.expectedErr {
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("ParseIdentity(%q) = %q, want %q", tc.identity, actual, tc.expected)
+		}
+		// The normalized identity parses to itself
+		again, againEmail, err := ParseIdentity(actual)
+		if err != nil || again != actual || againEmail != email {
+			t.Errorf("ParseIdentity(%q) = %q, %q, %v, want it unchanged", actual, again, againEmail, err)
+		}
+	}
+}
+
+func TestAssignTicket(t *testing.T) {
+	ticket := Ticket{ID: 1}
+
+	AssignTicket(&ticket, []string{"Alice Smith <alice@example.com>", "Bob Jones <bob@example.com>"})
+	AssignTicket(&ticket, []string{"Alice S <ALICE@example.com>"})
+	expected := []string{"Alice Smith <alice@example.com>", "Bob Jones <bob@example.com>"}
+	if !reflect.DeepEqual(ticket.Assignees, expected) {
+		t.Errorf("After assigning, Assignees = %v, want %v", ticket.Assignees, expected)
+	}
+	if !IsAssignedTo(ticket, "bob@example.com") || IsAssignedTo(ticket, "carol@example.com") {
+		t.Errorf("IsAssignedTo returned the wrong result for %v", ticket.Assignees)
+	}
+
+	UnassignTicket(&ticket, []string{"alice@example.com"})
+	expected = []string{"Bob Jones <bob@example.com>"}
+	if !reflect.DeepEqual(ticket.Assignees, expected) {
+		t.Errorf("After unassigning, Assignees = %v, want %v", ticket.Assignees, expected)
+	}
+
+	tickets := []Ticket{ticket, {ID: 2}}
+	mine := FilterTicketsByAssignee(tickets, "Bob@Example.com")
+	if len(mine) != 1 || mine[0].ID != 1 {
+		t.Errorf("FilterTicketsByAssignee returned %v", mine)
+	}
+}
+
+func TestHandleAssign(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		who          []string
+		unassignFlag bool
+		expectedErr  bool
+		expected     []string
+	}{
+		{who: []string{"Alice Smith <alice@example.com>"}, expected: []string{"Alice Smith <alice@example.com>"}},
+		{who: []string{"nobody@example.com"}, expectedErr: true, expected: []string{"Alice Smith <alice@example.com>"}},
+		{who: []string{"alice@example.com"}, unassignFlag: true, expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		err := HandleAssign(common.BranchName, tc.who, tc.unassignFlag, 1, true)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("HandleAssign(%v, %t) returned error %v, expected an error: %t", tc.who, tc.unassignFlag, err, tc.expectedErr)
+		}
+
+		thisRepo, err := git.OpenRepository(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ticket := FilterTicketsByID(tickets, 1)
+		if len(ticket.Assignees) != len(tc.expected) || (len(tc.expected) > 0 && !reflect.DeepEqual(ticket.Assignees, tc.expected)) {
+			t.Errorf("After HandleAssign(%v, %t) Assignees = %v, want %v", tc.who, tc.unassignFlag, ticket.Assignees, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,644 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"gopkg.in/yaml.v2"
+)
+
+// ExportFormats are the formats tickets can be exported as
+var ExportFormats = []string{"csv", "jsonl", "yaml"}
+
+// BulkImportFormats are the formats tickets can be imported from in bulk
+var BulkImportFormats = []string{"csv", "jsonl"}
+
+// bulkColumn is a column of a CSV export or import
+type bulkColumn struct {
+	// text returns the value of the column for a ticket
+	text func(t Ticket) string
+	// set sets the column of a ticket from a cell, or returns an error if the
+	// cell isn't valid. The id and external_id columns are used to find the
+	// ticket to update, so have no set.
+	set func(t *Ticket, value string, now time.Time) error
+}
+
+// bulkColumnOrder is the order of the columns of a CSV export
+var bulkColumnOrder = []string{"id", "external_id", "title", "description", "status", "priority", "severity", "labels", "assignees", "milestone", "due", "estimate", "created"}
+
+// bulkColumns maps the name of each column of a CSV export or import to its
+// bulkColumn. Empty title, status, priority, severity, and created cells
+// leave them as they are, other empty cells clear them.
+var bulkColumns = map[string]bulkColumn{
+	"id": {
+		text: func(t Ticket) string { return strconv.Itoa(t.ID) },
+	},
+	"external_id": {
+		text: func(t Ticket) string { return t.ExternalID },
+	},
+	"title": {
+		text: func(t Ticket) string { return t.Title },
+		set: func(t *Ticket, value string, now time.Time) error {
+			if strings.TrimSpace(value) != "" {
+				t.Title = strings.TrimSpace(value)
+			}
+			return nil
+		},
+	},
+	"description": {
+		text: func(t Ticket) string { return t.Description },
+		set: func(t *Ticket, value string, now time.Time) error {
+			t.Description = value
+			return nil
+		},
+	},
+	"status": {
+		text: func(t Ticket) string { return t.Status },
+		set: func(t *Ticket, value string, now time.Time) error {
+			if strings.TrimSpace(value) != "" {
+				t.Status = strings.TrimSpace(value)
+			}
+			return nil
+		},
+	},
+	"priority": {
+		text: func(t Ticket) string { return strconv.Itoa(t.Priority) },
+		set: func(t *Ticket, value string, now time.Time) error {
+			return setBulkNumber(&t.Priority, "priority", value)
+		},
+	},
+	"severity": {
+		text: func(t Ticket) string { return strconv.Itoa(t.Severity) },
+		set: func(t *Ticket, value string, now time.Time) error {
+			return setBulkNumber(&t.Severity, "severity", value)
+		},
+	},
+	"labels": {
+		text: func(t Ticket) string { return strings.Join(t.Labels, ",") },
+		set: func(t *Ticket, value string, now time.Time) error {
+			t.Labels = splitBulkList(value)
+			return nil
+		},
+	},
+	"assignees": {
+		text: func(t Ticket) string { return strings.Join(t.Assignees, ", ") },
+		set: func(t *Ticket, value string, now time.Time) error {
+			t.Assignees = splitBulkList(value)
+			return nil
+		},
+	},
+	"milestone": {
+		text: func(t Ticket) string { return t.Milestone },
+		set: func(t *Ticket, value string, now time.Time) error {
+			t.Milestone = strings.TrimSpace(value)
+			return nil
+		},
+	},
+	"due": {
+		text: func(t Ticket) string {
+			if t.Due == 0 {
+				return ""
+			}
+			return time.Unix(t.Due, 0).Format(time.RFC3339)
+		},
+		set: func(t *Ticket, value string, now time.Time) error {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				t.Due = 0
+				return nil
+			}
+			if due, err := time.Parse(time.RFC3339, value); err == nil {
+				t.Due = due.Unix()
+				return nil
+			}
+			due, err := ParseDue(value, now)
+			if err != nil {
+				return err
+			}
+			t.Due = due
+			return nil
+		},
+	},
+	"estimate": {
+		text: func(t Ticket) string {
+			if t.Estimate == 0 {
+				return ""
+			}
+			return formatWork(t.Estimate)
+		},
+		set: func(t *Ticket, value string, now time.Time) error {
+			if strings.TrimSpace(value) == "" {
+				t.Estimate = 0
+				return nil
+			}
+			d, err := ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			t.Estimate = int64(d.Seconds())
+			return nil
+		},
+	},
+	"created": {
+		text: func(t Ticket) string { return time.Unix(t.Created, 0).Format(time.RFC3339) },
+		set: func(t *Ticket, value string, now time.Time) error {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil
+			}
+			created, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				created, err = time.ParseInLocation("2006-01-02", value, time.Local)
+			}
+			if err != nil {
+				return errors.New("invalid created time '" + value + "', expected a time such as 2006-01-02T15:04:05Z or a date such as 2006-01-02")
+			}
+			t.Created = created.Unix()
+			return nil
+		},
+	},
+}
+
+// setBulkNumber sets n to the number in value, or leaves it as it is if value
+// is empty
+func setBulkNumber(n *int, name string, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("the " + name + " must be a number, not '" + value + "'")
+	}
+	*n = parsed
+	return nil
+}
+
+// splitBulkList splits a comma separated list, dropping empty items
+func splitBulkList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// HandleExport takes a writer, a branch name, a format from ExportFormats, the
+// name of a filter or "" for every ticket, and a debug flag, and writes the
+// tickets to the writer in that format. Returns an error if there is one.
+func HandleExport(w io.Writer, branchName string, format string, filterName string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	if filterName != "" {
+		filtered, err := FilterTickets(tickets, filterName, debugFlag)
+		if err != nil {
+			return err
+		}
+		tickets = *filtered
+	}
+	return ExportTickets(w, tickets, format)
+}
+
+// ExportTickets writes tickets to w in order of ID, in a format from
+// ExportFormats. CSV has a column for each field that can be imported from
+// CSV, JSON Lines has a ticket as a JSON object on each line, and YAML is a
+// list of tickets, and both have every field. Returns an error if there is
+// one.
+func ExportTickets(w io.Writer, tickets []Ticket, format string) error {
+	tickets = append([]Ticket{}, tickets...)
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		err := writer.Write(bulkColumnOrder)
+		if err != nil {
+			return err
+		}
+		for _, t := range tickets {
+			record := make([]string, len(bulkColumnOrder))
+			for i, column := range bulkColumnOrder {
+				record[i] = bulkColumns[column].text(t)
+			}
+			err = writer.Write(record)
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, t := range tickets {
+			err := encoder.Encode(t)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml":
+		return yaml.NewEncoder(w).Encode(tickets)
+	}
+	return errors.New("unknown format '" + format + "', valid formats are: " + strings.Join(ExportFormats, ", "))
+}
+
+// ParseColumnMap takes a comma separated list of mappings from the columns of
+// a file to import to giticket's columns, eg: "Summary=title,Owner=assignees",
+// and returns it as a map keyed by the lower case name of the file's column.
+// Mapping a column to nothing, eg: "Notes=", ignores it. Returns an error if a
+// mapping isn't in that form.
+func ParseColumnMap(mappings string) (map[string]string, error) {
+	columnMap := make(map[string]string)
+	for _, mapping := range strings.Split(mappings, ",") {
+		if strings.TrimSpace(mapping) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(mapping, "=")
+		if !ok || strings.TrimSpace(from) == "" {
+			return nil, errors.New("invalid column mapping '" + mapping + "', expected a mapping such as Summary=title")
+		}
+		columnMap[strings.ToLower(strings.TrimSpace(from))] = strings.ToLower(strings.TrimSpace(to))
+	}
+	return columnMap, nil
+}
+
+// bulkRow is a ticket read from a line of a file being imported: the ID or
+// external ID of the ticket it updates, if any, and a function setting the
+// fields the line has on the ticket
+type bulkRow struct {
+	line       int
+	id         int
+	externalID string
+	apply      func(t *Ticket, now time.Time) error
+}
+
+// parseBulkCSV reads the rows of a CSV file with a header row naming each
+// column, see bulkColumns. Columns are named as in a CSV export, or mapped to
+// those names with columnMap. Returns the errors in every row if there are
+// any.
+func parseBulkCSV(contents []byte, columnMap map[string]string) ([]bulkRow, error) {
+	reader := csv.NewReader(bytes.NewReader(contents))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		column := bulkColumnName(name, columnMap)
+		if column == "" {
+			continue
+		}
+		if _, ok := bulkColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column '%s', map it to one of %s with --map, or ignore it with --map '%s='", name, strings.Join(bulkColumnOrder, ", "), name)
+		}
+		if seen[column] {
+			return nil, errors.New("more than one column is " + column)
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+
+	var rows []bulkRow
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(columns) {
+			errs = append(errs, fmt.Errorf("line %d: expected %d cells, found %d", line, len(columns), len(record)))
+			continue
+		}
+
+		row := bulkRow{line: line}
+		cells := make(map[string]string)
+		for i, column := range columns {
+			switch column {
+			case "":
+			case "id":
+				value := strings.TrimSpace(record[i])
+				if value == "" {
+					continue
+				}
+				row.id, err = strconv.Atoi(value)
+				if err != nil || row.id <= 0 {
+					errs = append(errs, fmt.Errorf("line %d: invalid ticket ID '%s'", line, value))
+				}
+			case "external_id":
+				row.externalID = strings.TrimSpace(record[i])
+			default:
+				cells[column] = record[i]
+			}
+		}
+		row.apply = applyBulkCells(cells)
+		rows = append(rows, row)
+	}
+	return rows, errors.Join(errs...)
+}
+
+// parseBulkJSONL reads a JSON object from each line, with the keys of a
+// ticket as they are exported, or mapped to them with columnMap. Keys are
+// matched ignoring case. Each key is a column of a CSV import, see
+// bulkColumns, and is set the same way: numbers, lists of strings, and null
+// are read as the text of a CSV cell, and the unix times and seconds of an
+// export as times and durations. Keys of other fields of an exported ticket,
+// such as Comments, are ignored, and other keys are errors. Returns the errors
+// in every line if there are any.
+func parseBulkJSONL(contents []byte, columnMap map[string]string) ([]bulkRow, error) {
+	var rows []bulkRow
+	var errs []error
+	for i, text := range strings.Split(string(contents), "\n") {
+		line := i + 1
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		var object map[string]json.RawMessage
+		err := json.Unmarshal([]byte(text), &object)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		row := bulkRow{line: line}
+		cells := make(map[string]string)
+		var invalid []string
+		for _, key := range keys {
+			column := bulkColumnName(key, columnMap)
+			if _, ok := bulkColumns[column]; !ok {
+				if column != "" && !slices.Contains(ticketJSONKeys(), strings.ToLower(key)) {
+					invalid = append(invalid, fmt.Sprintf("unknown key '%s', map it to one of %s with --map, or ignore it with --map '%s='", key, strings.Join(bulkColumnOrder, ", "), key))
+				}
+				continue
+			}
+			value, err := bulkJSONCell(column, object[key])
+			if err != nil {
+				invalid = append(invalid, column+": "+err.Error())
+				continue
+			}
+			switch column {
+			case "id":
+				if strings.TrimSpace(value) == "" {
+					continue
+				}
+				row.id, err = strconv.Atoi(strings.TrimSpace(value))
+				if err != nil || row.id <= 0 {
+					invalid = append(invalid, "invalid ticket ID '"+value+"'")
+				}
+			case "external_id":
+				row.externalID = strings.TrimSpace(value)
+			default:
+				cells[column] = value
+			}
+		}
+		if len(invalid) > 0 {
+			errs = append(errs, fmt.Errorf("line %d: %s", line, strings.Join(invalid, "; ")))
+			continue
+		}
+		row.apply = applyBulkCells(cells)
+		rows = append(rows, row)
+	}
+	return rows, errors.Join(errs...)
+}
+
+// bulkColumnName returns the column of bulkColumns that a column of a file
+// being imported is mapped to with columnMap, or "" if it is mapped to
+// nothing. Columns that aren't mapped are matched ignoring case, and with ' '
+// and '-' read as '_', eg: "External ID", or without the '_', eg:
+// "ExternalID".
+func bulkColumnName(name string, columnMap map[string]string) string {
+	column := strings.ToLower(strings.TrimSpace(name))
+	if mapped, ok := columnMap[column]; ok {
+		return mapped
+	}
+	column = strings.NewReplacer(" ", "_", "-", "_").Replace(column)
+	if _, ok := bulkColumns[column]; !ok {
+		for _, known := range bulkColumnOrder {
+			if strings.ReplaceAll(known, "_", "") == column {
+				return known
+			}
+		}
+	}
+	return column
+}
+
+// applyBulkCells returns a function setting each column of a row on a ticket,
+// see bulkColumns, which returns the errors in every cell if there are any
+func applyBulkCells(cells map[string]string) func(t *Ticket, now time.Time) error {
+	return func(t *Ticket, now time.Time) error {
+		var invalid []string
+		for _, column := range bulkColumnOrder {
+			if value, ok := cells[column]; ok {
+				err := bulkColumns[column].set(t, value, now)
+				if err != nil {
+					invalid = append(invalid, column+": "+err.Error())
+				}
+			}
+		}
+		if len(invalid) > 0 {
+			return errors.New(strings.Join(invalid, "; "))
+		}
+		return nil
+	}
+}
+
+// bulkJSONCell returns the value of a key of a JSON Lines line as the text of
+// a CSV cell in column. Lists are joined with ',', null is empty, and numbers
+// in the due and created columns are unix times, and in the estimate column
+// seconds, as they are exported.
+func bulkJSONCell(column string, value json.RawMessage) (string, error) {
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return text, nil
+	}
+	var list []string
+	if json.Unmarshal(value, &list) == nil {
+		return strings.Join(list, ","), nil
+	}
+	var n int64
+	if json.Unmarshal(value, &n) != nil {
+		return "", errors.New("expected a string, a number, or a list of strings, not " + string(value))
+	}
+	switch {
+	case n == 0 && (column == "due" || column == "created" || column == "estimate"):
+		return "", nil
+	case column == "due" || column == "created":
+		return time.Unix(n, 0).Format(time.RFC3339), nil
+	case column == "estimate":
+		return formatWork(n), nil
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+// ticketJSONKeys returns the lower case keys of a ticket exported as JSON
+func ticketJSONKeys() []string {
+	var keys []string
+	ticketType := reflect.TypeOf(Ticket{})
+	for i := 0; i < ticketType.NumField(); i++ {
+		field := ticketType.Field(i)
+		key := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" {
+			key = tag
+		}
+		keys = append(keys, strings.ToLower(key))
+	}
+	return keys
+}
+
+// HandleBulkImport takes a writer, a branch name, a format from
+// BulkImportFormats, the file to import, a map of the file's columns to
+// giticket's (see ParseColumnMap), dry run and debug flags. Each row of the
+// file updates the ticket with its id, or with its external_id if a ticket
+// has it, or is a new ticket. Every change is made in a single commit, and
+// if any row is invalid the errors in every row are returned and nothing is
+// committed.
+func HandleBulkImport(w io.Writer, branchName string, format string, file string, columnMap map[string]string, dryRun bool, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Reading "+file)
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var rows []bulkRow
+	switch format {
+	case "csv":
+		rows, err = parseBulkCSV(contents, columnMap)
+	case "jsonl":
+		rows, err = parseBulkJSONL(contents, columnMap)
+	default:
+		return errors.New("unknown format '" + format + "', valid formats are: " + strings.Join(BulkImportFormats, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s:\n%w", file, err)
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	imported, err := applyBulkRows(tickets, rows, time.Now())
+	if err != nil {
+		return fmt.Errorf("reading %s:\n%w", file, err)
+	}
+	return CommitImport(w, thisRepo, branchName, tickets, imported, filepath.Base(file), dryRun, debugFlag)
+}
+
+// applyBulkRows takes every ticket, the rows read from a file, and the current
+// time, and returns the tickets as the rows change them. Tickets for rows
+// without an ID, or an external ID of an existing ticket, are returned
+// without an ID so they are created. More than one row for the same ticket,
+// or the same new external ID, is an error. Returns the errors in every row if
+// there are any.
+func applyBulkRows(tickets []Ticket, rows []bulkRow, now time.Time) ([]Ticket, error) {
+	byID := make(map[int]Ticket)
+	byExternalID := make(map[string]Ticket)
+	for _, t := range tickets {
+		byID[t.ID] = t
+		if t.ExternalID != "" {
+			byExternalID[t.ExternalID] = t
+		}
+	}
+
+	lineByID := make(map[int]int)
+	lineByExternalID := make(map[string]int)
+	var imported []Ticket
+	var errs []error
+	for _, row := range rows {
+		var base Ticket
+		var ok bool
+		switch {
+		case row.id != 0:
+			base, ok = byID[row.id]
+			if !ok {
+				errs = append(errs, fmt.Errorf("line %d: there is no ticket %d", row.line, row.id))
+				continue
+			}
+		case row.externalID != "":
+			base, ok = byExternalID[row.externalID]
+		}
+		if !ok {
+			base = Ticket{ExternalID: row.externalID, Created: now.Unix()}
+		}
+
+		// Each ticket can only be changed by one row
+		if base.ID != 0 {
+			if first, ok := lineByID[base.ID]; ok {
+				errs = append(errs, fmt.Errorf("line %d: ticket %d is already changed on line %d", row.line, base.ID, first))
+				continue
+			}
+			lineByID[base.ID] = row.line
+		} else if row.externalID != "" {
+			if first, ok := lineByExternalID[row.externalID]; ok {
+				errs = append(errs, fmt.Errorf("line %d: external ID %s is already on line %d", row.line, row.externalID, first))
+				continue
+			}
+			lineByExternalID[row.externalID] = row.line
+		}
+
+		// Copy the ticket so the row doesn't change its lists
+		var t Ticket
+		copied, err := json.Marshal(base)
+		if err == nil {
+			err = json.Unmarshal(copied, &t)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = row.apply(&t, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", row.line, err))
+			continue
+		}
+		t.ID = base.ID
+		if t.ID == 0 && t.Title == "" {
+			errs = append(errs, fmt.Errorf("line %d: new tickets need a title", row.line))
+			continue
+		}
+		imported = append(imported, t)
+	}
+	return imported, errors.Join(errs...)
+}
@@ -0,0 +1,263 @@
+package ticket
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestExportTickets(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local).Unix()
+	tickets := []Ticket{
+		{ID: 2, Title: "Fix, the build", Status: "closed", Priority: 1, Severity: 2, Created: created},
+		{ID: 1, Title: "Reverse the polarity", Status: "new", Priority: 3, Severity: 1, Labels: []string{"tardis", "bug"},
+			Assignees: []string{"John Smith <jsmith@example.com>"}, Estimate: 5400, Created: created, ExternalID: "github:12"},
+	}
+	stamp := time.Unix(created, 0).Format(time.RFC3339)
+
+	var out bytes.Buffer
+	err := ExportTickets(&out, tickets, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,external_id,title,description,status,priority,severity,labels,assignees,milestone,due,estimate,created\n" +
+		"1,github:12,Reverse the polarity,,new,3,1,\"tardis,bug\",John Smith <jsmith@example.com>,,,1h30m," + stamp + "\n" +
+		"2,,\"Fix, the build\",,closed,1,2,,,,,," + stamp + "\n"
+	if out.String() != want {
+		t.Errorf("ExportTickets() csv =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	err = ExportTickets(&out, tickets, "jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"Title":"Reverse the polarity"`) {
+		t.Errorf("ExportTickets() jsonl =\n%s", out.String())
+	}
+
+	err = ExportTickets(&out, tickets, "xml")
+	if err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestParseColumnMap(t *testing.T) {
+	got, err := ParseColumnMap("Summary=title, Owner = Assignees,Notes=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"summary": "title", "owner": "assignees", "notes": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseColumnMap() = %v, want %v", got, want)
+	}
+	_, err = ParseColumnMap("Summary")
+	if err == nil {
+		t.Error("expected an error for a mapping without =")
+	}
+}
+
+func TestBulkCSV(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Title: "Reverse the polarity", Status: "new", Priority: 1, Severity: 1, Labels: []string{"tardis"}, Milestone: "v1", Created: 100},
+		{ID: 2, Title: "Imported", Status: "new", Priority: 1, Severity: 1, ExternalID: "jira:7", Created: 100},
+	}
+	now := time.Unix(1000, 0)
+
+	csv := "ID,Summary,Status,Priority,Labels,Notes\n" +
+		"1,,in progress,2,\"tardis, bug\",ignored\n" +
+		",Write the docs,,,docs,\n"
+	rows, err := parseBulkCSV([]byte(csv), map[string]string{"summary": "title", "notes": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := applyBulkRows(tickets, rows, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Ticket{
+		{ID: 1, Title: "Reverse the polarity", Status: "in progress", Priority: 2, Severity: 1, Labels: []string{"tardis", "bug"}, Milestone: "v1", Created: 100},
+		{Title: "Write the docs", Labels: []string{"docs"}, Created: 1000},
+	}
+	if !reflect.DeepEqual(imported, want) {
+		t.Errorf("applyBulkRows() = %+v, want %+v", imported, want)
+	}
+	if !reflect.DeepEqual(tickets[0].Labels, []string{"tardis"}) {
+		t.Error("applyBulkRows() changed the existing ticket")
+	}
+
+	// Rows are matched by external ID when they have no ID
+	rows, err = parseBulkCSV([]byte("External ID,Title\njira:7,Renamed\njira:8,New\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err = applyBulkRows(tickets, rows, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 || imported[0].ID != 2 || imported[0].Title != "Renamed" || imported[1].ID != 0 || imported[1].ExternalID != "jira:8" {
+		t.Errorf("applyBulkRows() = %+v", imported)
+	}
+
+	// Every invalid row is reported
+	rows, err = parseBulkCSV([]byte("id,title,priority,due\n9,Missing,1,\n1,,high,someday\n,,,\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = applyBulkRows(tickets, rows, now)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"line 2: there is no ticket 9", "line 3: priority: the priority must be a number, not 'high'; due: invalid due date", "line 4: new tickets need a title"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("errors don't contain %q:\n%s", want, err)
+		}
+	}
+
+	_, err = parseBulkCSV([]byte("title,owner\nA,b\n"), nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown column 'owner'") {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+	_, err = parseBulkCSV([]byte("id,title\nx,A\n1,B,C\n"), nil)
+	if err == nil || !strings.Contains(err.Error(), "line 2: invalid ticket ID 'x'") || !strings.Contains(err.Error(), "line 3: expected 2 cells, found 3") {
+		t.Errorf("expected errors for lines 2 and 3, got %v", err)
+	}
+}
+
+func TestBulkJSONL(t *testing.T) {
+	tickets := []Ticket{{ID: 1, Title: "Reverse the polarity", Status: "new", Labels: []string{"tardis"}, Created: 100}}
+	jsonl := `{"ID": 1, "status": "closed", "Comments": [], "next_comment_id": 1}
+
+{"Summary": "Write the docs", "Labels": ["docs"], "external_id": "jira:9", "Due": 1700000000, "Estimate": 5400}
+{"ID": 1, "Colour": "blue", "Labels": {"docs": true}}
+not json
+`
+	_, err := parseBulkJSONL([]byte(jsonl), map[string]string{"summary": "title"})
+	for _, want := range []string{"labels: expected a string, a number, or a list of strings", "line 4: unknown key 'Colour'", "line 5:"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("errors don't contain %q: %v", want, err)
+		}
+	}
+
+	valid := strings.Join(strings.Split(jsonl, "\n")[:3], "\n")
+	rows, err := parseBulkJSONL([]byte(valid), map[string]string{"summary": "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := applyBulkRows(tickets, rows, time.Unix(1000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Ticket{
+		{ID: 1, Title: "Reverse the polarity", Status: "closed", Labels: []string{"tardis"}, Created: 100},
+		{Title: "Write the docs", Labels: []string{"docs"}, Due: 1700000000, Estimate: 5400, ExternalID: "jira:9", Created: 1000},
+	}
+	if !reflect.DeepEqual(imported, want) {
+		t.Errorf("applyBulkRows() = %+v, want %+v", imported, want)
+	}
+
+	// Cells are checked as they are in a CSV file
+	rows, err = parseBulkJSONL([]byte(`{"ID": 1, "priority": "high"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = applyBulkRows(tickets, rows, time.Unix(1000, 0))
+	if err == nil || !strings.Contains(err.Error(), "line 1: priority: the priority must be a number, not 'high'") {
+		t.Errorf("expected a priority error for line 1, got %v", err)
+	}
+}
+
+func TestBulkDuplicateRows(t *testing.T) {
+	tickets := []Ticket{{ID: 1, Title: "Reverse the polarity", Status: "new", ExternalID: "jira:7", Created: 100}}
+	rows, err := parseBulkCSV([]byte("id,external_id,title\n1,,A\n,jira:7,B\n,jira:8,C\n,jira:8,D\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = applyBulkRows(tickets, rows, time.Unix(1000, 0))
+	for _, want := range []string{"line 3: ticket 1 is already changed on line 2", "line 5: external ID jira:8 is already on line 4"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("errors don't contain %q: %v", want, err)
+		}
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	var out bytes.Buffer
+	writeDiff(&out, []byte("title: a\nstatus: new\npriority: 1\n"), []byte("title: a\nstatus: closed\npriority: 1\n"))
+	want := "    - status: new\n    + status: closed\n"
+	if out.String() != want {
+		t.Errorf("writeDiff() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestHandleBulkImport(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.GetParentCommit(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer before.Free()
+
+	var exported bytes.Buffer
+	err = HandleExport(&exported, common.BranchName, "csv", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(exported.String(), ",open,", ",closed,", 1) + ",,Write the docs,,,,,docs,,,,,\n"
+	err = os.WriteFile("tickets.csv", []byte(edited), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run shows the changes without committing them
+	var out bytes.Buffer
+	err = HandleBulkImport(&out, common.BranchName, "csv", "tickets.csv", nil, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Would update ticket 1", "    + status: closed", "Would create ticket 2: Write the docs"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+	after, err := repo.GetParentCommit(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after.Free()
+	if !after.Id().Equal(before.Id()) {
+		t.Error("a dry run made a commit")
+	}
+
+	out.Reset()
+	err = HandleBulkImport(&out, common.BranchName, "csv", "tickets.csv", nil, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Imported 1 new and 1 updated tickets from tickets.csv") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 || FilterTicketsByID(tickets, 1).Status != "closed" || FilterTicketsByID(tickets, 2).Title != "Write the docs" {
+		t.Errorf("unexpected tickets after importing: %+v", tickets)
+	}
+}
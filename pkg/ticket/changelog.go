@@ -0,0 +1,196 @@
+package ticket
+
+import (
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// otherSection is the section of a changelog for tickets without a label in
+// any other section
+const otherSection = "Other"
+
+// DefaultChangelogTemplate is the Markdown a changelog is rendered with when
+// no template is given
+const DefaultChangelogTemplate = `## {{.To}}
+{{range .Sections}}
+### {{.Label}}
+
+{{range .Tickets}}- {{.Title}} (#{{.ID}}){{if not (closed .)}} (still open){{end}}
+{{end}}{{else}}
+No tickets were closed.
+{{end}}`
+
+// Changelog is the tickets closed between two revisions of the code, grouped
+// into sections by label. It is what a changelog template is executed with.
+type Changelog struct {
+	From     string
+	To       string
+	FromTime int64
+	ToTime   int64
+	Sections []ChangelogSection
+}
+
+// ChangelogSection is the tickets in a changelog with a label
+type ChangelogSection struct {
+	Label   string
+	Tickets []Ticket
+}
+
+// HandleChangelog takes a writer, a branch name, the revisions of the code
+// the changelog is from and to, such as tags, with "" for to meaning the
+// current commit, the labels to make sections for in order or nil for every
+// label, a template or "" for DefaultChangelogTemplate, and a debug flag. It
+// writes the tickets closed on branchName between the times of the two
+// commits, and the tickets referred to by the commits after from up to to,
+// see ChangelogTickets. The template is a --format template, see
+// ParseFormat, and may name a stored format. Returns an error if there is
+// one.
+func HandleChangelog(w io.Writer, branchName string, from string, to string, labels []string, tmplText string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	if tmplText == "" {
+		tmplText = DefaultChangelogTemplate
+	}
+	tmplText, err = ResolveFormat(thisRepo, branchName, tmplText, debugFlag)
+	if err != nil {
+		return err
+	}
+	tmpl, err := ParseFormat(tmplText)
+	if err != nil {
+		return err
+	}
+
+	fromCommit, err := lookupCodeCommit(thisRepo, branchName, from)
+	if err != nil {
+		return err
+	}
+	defer fromCommit.Free()
+	toCommit, err := lookupCodeCommit(thisRepo, branchName, to)
+	if err != nil {
+		return err
+	}
+	defer toCommit.Free()
+	if to == "" {
+		to = "HEAD"
+	}
+
+	debug.DebugMessage(debugFlag, "Finding the tickets referred to by commits from "+from+" to "+to)
+	walk, err := thisRepo.Walk()
+	if err != nil {
+		return err
+	}
+	defer walk.Free()
+	err = walk.Push(toCommit.Id())
+	if err != nil {
+		return err
+	}
+	err = walk.Hide(fromCommit.Id())
+	if err != nil {
+		return err
+	}
+	referenced := make(map[int]bool)
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		for _, ref := range ParseCommitReferences(commit.Message()) {
+			referenced[ref.TicketID] = true
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	history, err := TicketHistory(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	changelog := Changelog{
+		From:     from,
+		To:       to,
+		FromTime: fromCommit.Committer().When.Unix(),
+		ToTime:   toCommit.Committer().When.Unix(),
+	}
+	included := ChangelogTickets(tickets, history, changelog.FromTime, changelog.ToTime, referenced)
+	changelog.Sections = ChangelogSections(included, labels)
+	return tmpl.Execute(w, changelog)
+}
+
+// ChangelogTickets takes every ticket, their history, the times a changelog
+// is from and to, and the IDs of the tickets referred to by the commits in
+// between. It returns the tickets that were closed after from and up to to,
+// and the referred to tickets, in order of ID.
+func ChangelogTickets(tickets []Ticket, history map[int][]HistoryEntry, from int64, to int64, referenced map[int]bool) []Ticket {
+	var included []Ticket
+	for _, t := range tickets {
+		if referenced[t.ID] {
+			included = append(included, t)
+			continue
+		}
+		wasClosed := false
+		for _, entry := range history[t.ID] {
+			closed := !entry.Deleted && IsClosed(Ticket{Status: entry.Status})
+			if closed && !wasClosed && entry.When > from && entry.When <= to {
+				included = append(included, t)
+				break
+			}
+			wasClosed = closed
+		}
+	}
+	sort.Slice(included, func(i, j int) bool { return included[i].ID < included[j].ID })
+	return included
+}
+
+// ChangelogSections takes the tickets in a changelog and the labels to make
+// sections for in order, and returns the sections. Each ticket is in the
+// section of the first of labels it has, or in a last section named "Other"
+// if it has none of them. If labels is empty, there is a section for each
+// label in alphabetical order, and each ticket is in the section of its first
+// label. Sections without tickets are left out.
+func ChangelogSections(tickets []Ticket, labels []string) []ChangelogSection {
+	order := slices.Clone(labels)
+	if len(order) == 0 {
+		for _, t := range tickets {
+			if len(t.Labels) > 0 && !slices.Contains(order, t.Labels[0]) {
+				order = append(order, t.Labels[0])
+			}
+		}
+		sort.Strings(order)
+	}
+
+	byLabel := make(map[string][]Ticket)
+	for _, t := range tickets {
+		section := otherSection
+		if len(labels) == 0 && len(t.Labels) > 0 {
+			section = t.Labels[0]
+		}
+		for _, label := range labels {
+			if slices.ContainsFunc(t.Labels, func(l string) bool { return strings.EqualFold(l, label) }) {
+				section = label
+				break
+			}
+		}
+		byLabel[section] = append(byLabel[section], t)
+	}
+
+	var sections []ChangelogSection
+	for _, label := range append(order, otherSection) {
+		if len(byLabel[label]) > 0 {
+			sections = append(sections, ChangelogSection{Label: label, Tickets: byLabel[label]})
+			delete(byLabel, label)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,151 @@
+package ticket
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestChangelogTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Title: "Closed in the release", Status: "closed"},
+		{ID: 2, Title: "Closed before the release", Status: "closed"},
+		{ID: 3, Title: "Referred to by a commit", Status: "new"},
+		{ID: 4, Title: "Reopened and closed again", Status: "done"},
+		{ID: 5, Title: "Still open", Status: "in progress"},
+	}
+	history := map[int][]HistoryEntry{
+		1: {{When: 50, Status: "new"}, {When: 150, Status: "closed"}},
+		2: {{When: 50, Status: "new"}, {When: 90, Status: "closed"}, {When: 150, Status: "closed"}},
+		4: {{When: 50, Status: "closed"}, {When: 120, Status: "new"}, {When: 180, Status: "done"}},
+		5: {{When: 50, Status: "new"}, {When: 150, Status: "in progress"}},
+	}
+
+	got := ChangelogTickets(tickets, history, 100, 200, map[int]bool{3: true})
+	var ids []int
+	for _, t := range got {
+		ids = append(ids, t.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 3, 4}) {
+		t.Errorf("ChangelogTickets() = %v, want [1 3 4]", ids)
+	}
+}
+
+func TestChangelogSections(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Labels: []string{"ux", "bugfix"}},
+		{ID: 2, Labels: []string{"feature"}},
+		{ID: 3},
+		{ID: 4, Labels: []string{"BugFix"}},
+	}
+
+	sections := ChangelogSections(tickets, []string{"feature", "bugfix"})
+	want := []ChangelogSection{
+		{Label: "feature", Tickets: []Ticket{tickets[1]}},
+		{Label: "bugfix", Tickets: []Ticket{tickets[0], tickets[3]}},
+		{Label: "Other", Tickets: []Ticket{tickets[2]}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("ChangelogSections() = %+v, want %+v", sections, want)
+	}
+
+	sections = ChangelogSections(tickets, nil)
+	want = []ChangelogSection{
+		{Label: "BugFix", Tickets: []Ticket{tickets[3]}},
+		{Label: "feature", Tickets: []Ticket{tickets[1]}},
+		{Label: "ux", Tickets: []Ticket{tickets[0]}},
+		{Label: "Other", Tickets: []Ticket{tickets[2]}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("ChangelogSections() = %+v, want %+v", sections, want)
+	}
+}
+
+func TestDefaultChangelogTemplate(t *testing.T) {
+	tmpl, err := ParseFormat(DefaultChangelogTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, Changelog{To: "v1.3.0", Sections: []ChangelogSection{
+		{Label: "bugfix", Tickets: []Ticket{{ID: 1, Title: "Fix the build", Status: "closed"}, {ID: 3, Title: "Fix the tests", Status: "new"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## v1.3.0\n\n### bugfix\n\n- Fix the build (#1)\n- Fix the tests (#3) (still open)\n"
+	if out.String() != want {
+		t.Errorf("changelog =\n%q\nwant\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	err = tmpl.Execute(&out, Changelog{To: "v1.3.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "## v1.3.0\n\nNo tickets were closed.\n" {
+		t.Errorf("empty changelog = %q", out.String())
+	}
+}
+
+func TestHandleChangelog(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// commit adds a commit to main at the given time
+	commit := func(message string, when time.Time) string {
+		branch, err := thisRepo.LookupBranch("main", git.BranchLocal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent, err := thisRepo.LookupCommit(branch.Target())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree, err := parent.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		author := &git.Signature{Name: "test user", Email: "test@example.com", When: when}
+		oid, err := thisRepo.CreateCommit("refs/heads/main", author, author, message, tree, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return oid.String()
+	}
+
+	from := commit("Release v1.2.0", time.Now().Add(-time.Hour))
+	err = HandleStatus("closed", 1, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = HandleCreate(common.BranchName, 0, "My second ticket", "", []string{}, 1, 1, "new", []Comment{}, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := commit("Start on the second ticket, refs #2", time.Now().Add(time.Hour))
+
+	var out bytes.Buffer
+	err = HandleChangelog(&out, common.BranchName, from, to, nil, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"### bugfix\n\n- My first ticket (#1)\n", "### Other\n\n- My second ticket (#2) (still open)\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("changelog doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// ChecklistItem is a step in a ticket's checklist. An item promoted to a
+// ticket of its own has the ID of that ticket in TicketID.
+type ChecklistItem struct {
+	Text     string
+	Done     bool
+	TicketID int `yaml:"ticket_id,omitempty" json:"ticket_id,omitempty"`
+}
+
+// ChecklistActions are the actions accepted by HandleChecklist
+var ChecklistActions = []string{"add", "done", "undo", "remove", "promote"}
+
+// HandleChecklist takes a branch name, an action, a ticket ID, the position of
+// a checklist item (counting from 1), the text of a new item, and a debug
+// flag, and changes the ticket's checklist:
+//
+//   - add inserts an item with text before position, or at the end if position
+//     is 0
+//   - done and undo mark the item at position as done or not done
+//   - remove removes the item at position
+//   - promote creates a child ticket titled with the text of the item at
+//     position, and records the new ticket's ID on the item
+//
+// Returns an error if there was one.
+func HandleChecklist(branchName string, action string, ticketID int, position int, text string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	var commitMessage string
+	switch action {
+	case "add":
+		err = AddChecklistItem(&t, position, text)
+		commitMessage = "Adding checklist item to ticket " + t.TicketFilename()
+	case "done", "undo":
+		err = SetChecklistItemDone(&t, position, action == "done")
+		commitMessage = "Marking checklist item " + strconv.Itoa(position) + " of ticket " + t.TicketFilename() + " as " + action
+	case "remove":
+		err = RemoveChecklistItem(&t, position)
+		commitMessage = "Removing checklist item " + strconv.Itoa(position) + " from ticket " + t.TicketFilename()
+	case "promote":
+		return PromoteChecklistItem(thisRepo, branchName, t, position, debugFlag)
+	default:
+		return fmt.Errorf("unknown checklist action '%s'", action)
+	}
+	if err != nil {
+		return err
+	}
+	return repo.Commit(&t, thisRepo, branchName, author, commitMessage, debugFlag)
+}
+
+// AddChecklistItem takes a pointer to a ticket, a position counting from 1,
+// and the text of an item, and inserts the item before position, or at the
+// end of the checklist if position is 0
+func AddChecklistItem(t *Ticket, position int, text string) error {
+	if text == "" {
+		return errors.New("checklist items must have text")
+	}
+	if position == 0 {
+		position = len(t.Checklist) + 1
+	}
+	if position < 1 || position > len(t.Checklist)+1 {
+		return fmt.Errorf("position %d is outside the checklist of %d items", position, len(t.Checklist))
+	}
+	item := ChecklistItem{Text: text}
+	t.Checklist = append(t.Checklist[:position-1], append([]ChecklistItem{item}, t.Checklist[position-1:]...)...)
+	return nil
+}
+
+// SetChecklistItemDone takes a pointer to a ticket, the position of a
+// checklist item counting from 1, and whether the item is done
+func SetChecklistItemDone(t *Ticket, position int, done bool) error {
+	err := checkChecklistPosition(*t, position)
+	if err != nil {
+		return err
+	}
+	t.Checklist[position-1].Done = done
+	return nil
+}
+
+// RemoveChecklistItem takes a pointer to a ticket and the position of a
+// checklist item counting from 1, and removes the item
+func RemoveChecklistItem(t *Ticket, position int) error {
+	err := checkChecklistPosition(*t, position)
+	if err != nil {
+		return err
+	}
+	t.Checklist = append(t.Checklist[:position-1], t.Checklist[position:]...)
+	return nil
+}
+
+// PromoteChecklistItem takes a pointer to a git repository, a branch name, a
+// ticket, the position of a checklist item counting from 1, and a debug flag.
+// It creates a new ticket titled with the item's text, links it as a child of
+// the ticket, and records the new ticket's ID on the item, all in a single
+// commit. Returns an error if the item was already promoted, or if there was
+// another error.
+func PromoteChecklistItem(thisRepo *git.Repository, branchName string, t Ticket, position int, debugFlag bool) error {
+	err := checkChecklistPosition(t, position)
+	if err != nil {
+		return err
+	}
+	item := &t.Checklist[position-1]
+	if item.TicketID != 0 {
+		return fmt.Errorf("checklist item %d was already promoted to ticket %d", position, item.TicketID)
+	}
+
+	parentCommit, err := repo.GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	defer parentCommit.Free()
+	childID, err := ReadNextTicketID(thisRepo, parentCommit)
+	if err != nil {
+		return err
+	}
+	debug.DebugMessage(debugFlag, "Promoting checklist item "+strconv.Itoa(position)+" to ticket "+strconv.Itoa(childID))
+
+	status := "new"
+	if item.Done {
+		status = "closed"
+	}
+	child := Ticket{
+		ID:            childID,
+		Title:         item.Text,
+		Labels:        t.Labels,
+		Priority:      t.Priority,
+		Severity:      t.Severity,
+		Status:        status,
+		Assignees:     t.Assignees,
+		NextCommentID: 1,
+		Created:       time.Now().Unix(),
+	}
+	err = AddLink(&t, &child, "parent-of")
+	if err != nil {
+		return err
+	}
+	item.TicketID = childID
+
+	files := map[string][]byte{
+		"next_ticket_id":                             []byte(strconv.Itoa(childID + 1)),
+		path.Join("tickets", t.TicketFilename()):     t.TicketToYaml(),
+		path.Join("tickets", child.TicketFilename()): child.TicketToYaml(),
+	}
+	return repo.CommitFiles(thisRepo, branchName, files, "Promoting checklist item "+strconv.Itoa(position)+" of ticket "+t.TicketFilename()+" to ticket "+child.TicketFilename(), debugFlag)
+}
+
+// ChecklistProgress takes a ticket and returns its checklist progress such as
+// "3/7", or an empty string if the ticket has no checklist
+func ChecklistProgress(t Ticket) string {
+	if len(t.Checklist) == 0 {
+		return ""
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return strconv.Itoa(done) + "/" + strconv.Itoa(len(t.Checklist))
+}
+
+// SyncPromotedChecklistItems takes a ticket whose status may have changed and
+// every ticket, and marks the checklist items promoted to that ticket as done
+// or not done to match whether it is closed. It returns the parent tickets
+// that were changed, so they can be committed along with the ticket.
+func SyncPromotedChecklistItems(t Ticket, allTickets []Ticket) []Ticket {
+	var changed []Ticket
+	for _, l := range t.Links {
+		if l.Type != "child-of" {
+			continue
+		}
+		parent := FilterTicketsByID(allTickets, l.ID)
+		parent.Checklist = slices.Clone(parent.Checklist)
+		updated := false
+		for i, item := range parent.Checklist {
+			if item.TicketID == t.ID && item.Done != IsClosed(t) {
+				parent.Checklist[i].Done = IsClosed(t)
+				updated = true
+			}
+		}
+		if updated {
+			changed = append(changed, parent)
+		}
+	}
+	return changed
+}
+
+// checkChecklistPosition returns an error if position is not the position of
+// an item in the ticket's checklist
+func checkChecklistPosition(t Ticket, position int) error {
+	if position < 1 || position > len(t.Checklist) {
+		if len(t.Checklist) == 0 {
+			return fmt.Errorf("ticket %d has no checklist", t.ID)
+		}
+		return fmt.Errorf("there is no checklist item %d, ticket %d has %d items", position, t.ID, len(t.Checklist))
+	}
+	return nil
+}
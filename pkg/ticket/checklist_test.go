@@ -0,0 +1,184 @@
+package ticket
+
+import (
+	"reflect"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestChecklistItems(t *testing.T) {
+	ticket := Ticket{ID: 1}
+
+	steps := []struct {
+		name        string
+		apply       func() error
+		expectedErr bool
+		expected    []ChecklistItem
+	}{
+		{name: "add", apply: func() error { return AddChecklistItem(&ticket, 0, "two") }, expected: []ChecklistItem{{Text: "two"}}},
+		{name: "insert", apply: func() error { return AddChecklistItem(&ticket, 1, "one") }, expected: []ChecklistItem{{Text: "one"}, {Text: "two"}}},
+		{name: "append", apply: func() error { return AddChecklistItem(&ticket, 3, "three") }, expected: []ChecklistItem{{Text: "one"}, {Text: "two"}, {Text: "three"}}},
+		{name: "add past the end", apply: func() error { return AddChecklistItem(&ticket, 5, "five") }, expectedErr: true, expected: []ChecklistItem{{Text: "one"}, {Text: "two"}, {Text: "three"}}},
+		{name: "add empty", apply: func() error { return AddChecklistItem(&ticket, 0, "") }, expectedErr: true, expected: []ChecklistItem{{Text: "one"}, {Text: "two"}, {Text: "three"}}},
+		{name: "done", apply: func() error { return SetChecklistItemDone(&ticket, 2, true) }, expected: []ChecklistItem{{Text: "one"}, {Text: "two", Done: true}, {Text: "three"}}},
+		{name: "done missing", apply: func() error { return SetChecklistItemDone(&ticket, 4, true) }, expectedErr: true, expected: []ChecklistItem{{Text: "one"}, {Text: "two", Done: true}, {Text: "three"}}},
+		{name: "remove", apply: func() error { return RemoveChecklistItem(&ticket, 1) }, expected: []ChecklistItem{{Text: "two", Done: true}, {Text: "three"}}},
+		{name: "undo", apply: func() error { return SetChecklistItemDone(&ticket, 1, false) }, expected: []ChecklistItem{{Text: "two"}, {Text: "three"}}},
+	}
+
+	for _, step := range steps {
+		err := step.apply()
+		if (err != nil) != step.expectedErr {
+			t.Errorf("%s returned error %v, expected an error: %t", step.name, err, step.expectedErr)
+		}
+		if !reflect.DeepEqual(ticket.Checklist, step.expected) {
+			t.Errorf("After %s the checklist is %v, want %v", step.name, ticket.Checklist, step.expected)
+		}
+	}
+
+	if progress := ChecklistProgress(Ticket{}); progress != "" {
+		t.Errorf("ChecklistProgress of an empty checklist = %q, want \"\"", progress)
+	}
+	SetChecklistItemDone(&ticket, 2, true)
+	if progress := ChecklistProgress(ticket); progress != "1/2" {
+		t.Errorf("ChecklistProgress = %q, want \"1/2\"", progress)
+	}
+}
+
+func TestSyncPromotedChecklistItems(t *testing.T) {
+	parent := Ticket{ID: 1, Checklist: []ChecklistItem{{Text: "one"}, {Text: "two", TicketID: 2}}}
+	child := Ticket{ID: 2, Status: "closed"}
+	AddLink(&parent, &child, "parent-of")
+	allTickets := []Ticket{parent, child}
+
+	changed := SyncPromotedChecklistItems(child, allTickets)
+	if len(changed) != 1 || !changed[0].Checklist[1].Done || changed[0].Checklist[0].Done {
+		t.Errorf("Expected the parent's second item to be done, got %v", changed)
+	}
+	if allTickets[0].Checklist[1].Done {
+		t.Errorf("SyncPromotedChecklistItems modified the list of tickets it was given")
+	}
+
+	child.Status = "open"
+	if changed := SyncPromotedChecklistItems(child, allTickets); len(changed) != 0 {
+		t.Errorf("Expected no changes for an open child of an unchanged parent, got %v", changed)
+	}
+}
+
+func TestHandleChecklist(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"Write the code", "Write the docs"} {
+		err = HandleChecklist(common.BranchName, "add", 1, 0, text, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = HandleChecklist(common.BranchName, "done", 1, 1, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleChecklist(common.BranchName, "promote", 1, 2, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleChecklist(common.BranchName, "promote", 1, 2, "", true)
+	if err == nil {
+		t.Errorf("Expected an error promoting the same item twice")
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := FilterTicketsByID(tickets, 1)
+	child := FilterTicketsByID(tickets, 2)
+	if child.Title != "Write the docs" || !reflect.DeepEqual(child.Links, []Link{{Type: "child-of", ID: 1}}) {
+		t.Errorf("Unexpected child ticket %+v", child)
+	}
+	expected := []ChecklistItem{{Text: "Write the code", Done: true}, {Text: "Write the docs", TicketID: 2}}
+	if !reflect.DeepEqual(parent.Checklist, expected) {
+		t.Errorf("Parent checklist is %v, want %v", parent.Checklist, expected)
+	}
+
+	// Closing the child ticket marks the item done
+	err = HandleStatus("closed", 2, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress := ChecklistProgress(FilterTicketsByID(tickets, 1)); progress != "2/2" {
+		t.Errorf("After closing the child ticket the progress is %s, want 2/2", progress)
+	}
+}
+
+func TestHandleChecklistPromoteSlashes(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"Split a/b", "x/../../next_ticket_id"}
+	for _, text := range texts {
+		err = HandleChecklist(common.BranchName, "add", 1, 0, text, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for position := range texts {
+		err = HandleChecklist(common.BranchName, "promote", 1, position+1, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range texts {
+		if child := FilterTicketsByID(tickets, i+2); child.Title != text {
+			t.Errorf("Ticket %d has the title %q, want %q", i+2, child.Title, text)
+		}
+	}
+	parentCommit, err := repo.GetParentCommit(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parentCommit.Free()
+	nextID, err := ReadNextTicketID(thisRepo, parentCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextID != 4 {
+		t.Errorf("The next ticket ID is %d, want 4", nextID)
+	}
+}
+
+func TestTicketFilename(t *testing.T) {
+	ticket := Ticket{ID: 3, Title: "x/../../next ticket id"}
+	if got := ticket.TicketFilename(); got != "3__x-..-..-next_ticket_id" {
+		t.Errorf("TicketFilename() = %q", got)
+	}
+}
@@ -0,0 +1,403 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// CodeRefActions are the actions accepted by the ref subcommand
+var CodeRefActions = []string{"add", "remove", "check"}
+
+// The states of a code reference reported by CheckCodeRef
+const (
+	CodeRefUnchanged = "unchanged"
+	CodeRefChanged   = "changed"
+	CodeRefMoved     = "moved"
+	CodeRefDeleted   = "deleted"
+)
+
+// CodeRef is a range of lines in a file that a ticket refers to, as they were
+// in the commit the reference was made at
+type CodeRef struct {
+	Path      string
+	StartLine int `yaml:"start_line" json:"start_line"`
+	EndLine   int `yaml:"end_line" json:"end_line"`
+	Commit    string
+	Author    string
+	Created   int64
+
+	// Snippet holds the referenced lines read from Commit, see
+	// LoadCodeSnippets. It isn't stored in the ticket.
+	Snippet []string `yaml:"-" json:"-"`
+}
+
+// CodeRefState is the result of checking whether the lines a code reference
+// points at have changed since the reference was made
+type CodeRefState struct {
+	State string
+	// StartLine and EndLine are where the lines are now if they moved
+	StartLine int
+	EndLine   int
+}
+
+// HandleRefAdd takes a branch name, a ticket ID, a location such as
+// src/foo.go:120-140, and a debug flag, and adds a reference to those lines
+// as they are in the current commit to the ticket. Returns an error if the
+// file or lines don't exist in the current commit, or if there was another
+// error.
+func HandleRefAdd(branchName string, ticketID int, location string, debugFlag bool) error {
+	filePath, start, end, err := ParseCodeLocation(location)
+	if err != nil {
+		return err
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	head, err := thisRepo.Head()
+	if err != nil {
+		return err
+	}
+	defer head.Free()
+	if head.Shorthand() == branchName {
+		return errors.New("the " + branchName + " branch holds tickets, check out a branch with code to refer to")
+	}
+	commitOID := head.Target().String()
+
+	contents, err := readCommitFile(thisRepo, commitOID, filePath)
+	if err != nil {
+		return fmt.Errorf("unable to read %s from the current commit: %s", filePath, err)
+	}
+	if _, err := snippetLines(contents, start, end); err != nil {
+		return fmt.Errorf("%s: %s", filePath, err)
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	ref := CodeRef{
+		Path:      filePath,
+		StartLine: start,
+		EndLine:   end,
+		Commit:    commitOID,
+		Author:    author.Name + " <" + author.Email + ">",
+		Created:   time.Now().Unix(),
+	}
+	t.CodeRefs = append(t.CodeRefs, ref)
+	return repo.Commit(&t, thisRepo, branchName, author, "Adding reference to "+codeRefLocation(ref)+" to ticket "+t.TicketFilename(), debugFlag)
+}
+
+// HandleRefRemove takes a branch name, a ticket ID, a location such as
+// src/foo.go:120-140, and a debug flag, and removes the ticket's references to
+// exactly those lines. Returns an error if the ticket has no such reference,
+// or if there was another error.
+func HandleRefRemove(branchName string, ticketID int, location string, debugFlag bool) error {
+	filePath, start, end, err := ParseCodeLocation(location)
+	if err != nil {
+		return err
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	before := len(t.CodeRefs)
+	t.CodeRefs = slices.DeleteFunc(t.CodeRefs, func(ref CodeRef) bool {
+		return ref.Path == filePath && ref.StartLine == start && ref.EndLine == end
+	})
+	if len(t.CodeRefs) == before {
+		return fmt.Errorf("ticket %d doesn't refer to %s", ticketID, location)
+	}
+	return repo.Commit(&t, thisRepo, branchName, author, "Removing reference to "+location+" from ticket "+t.TicketFilename(), debugFlag)
+}
+
+// HandleRefCheck takes a writer, a branch name, a list of ticket IDs, and a
+// debug flag, and writes whether the lines each ticket refers to have changed
+// in the current commit since the reference was made. If no ticket IDs are
+// given every ticket is checked.
+func HandleRefCheck(w io.Writer, branchName string, ticketIDs []int, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	head, err := thisRepo.Head()
+	if err != nil {
+		return err
+	}
+	defer head.Free()
+	headOID := head.Target().String()
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	toCheck := tickets
+	if len(ticketIDs) > 0 {
+		toCheck = nil
+		for _, ticketID := range ticketIDs {
+			t := FilterTicketsByID(tickets, ticketID)
+			if t.ID == 0 {
+				return fmt.Errorf("ticket %d not found", ticketID)
+			}
+			toCheck = append(toCheck, t)
+		}
+	}
+
+	checked := 0
+	for _, t := range toCheck {
+		for _, ref := range t.CodeRefs {
+			debug.DebugMessage(debugFlag, "Checking "+codeRefLocation(ref)+" on ticket "+strconv.Itoa(t.ID))
+			referenced, err := readCommitFile(thisRepo, ref.Commit, ref.Path)
+			if err != nil {
+				fmt.Fprintf(w, "Ticket %d: %s at %s can't be checked: %s\n", t.ID, codeRefLocation(ref), shortOID(ref.Commit), err)
+				continue
+			}
+			old, err := snippetLines(referenced, ref.StartLine, ref.EndLine)
+			if err != nil {
+				fmt.Fprintf(w, "Ticket %d: %s at %s can't be checked: %s\n", t.ID, codeRefLocation(ref), shortOID(ref.Commit), err)
+				continue
+			}
+
+			current, err := readCommitFile(thisRepo, headOID, ref.Path)
+			if err != nil && !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+				return err
+			}
+			state := CheckCodeRef(ref, old, current)
+			fmt.Fprintf(w, "Ticket %d: %s at %s %s\n", t.ID, codeRefLocation(ref), shortOID(ref.Commit), describeCodeRefState(ref, state))
+			checked++
+		}
+	}
+	if checked == 0 {
+		fmt.Fprintln(w, "No tickets refer to code")
+	}
+	return nil
+}
+
+// ParseCodeLocation takes a location such as src/foo.go:120-140 or
+// src/foo.go:120, and returns the path relative to the top of the repository
+// and the first and last lines. Returns an error if the location is invalid.
+func ParseCodeLocation(location string) (string, int, int, error) {
+	i := strings.LastIndex(location, ":")
+	if i == -1 {
+		return "", 0, 0, errors.New("invalid location '" + location + "', expected a path and lines such as src/foo.go:120-140")
+	}
+	filePath, lines := location[:i], location[i+1:]
+
+	filePath = path.Clean(filepath.ToSlash(strings.TrimSpace(filePath)))
+	if filePath == "." || path.IsAbs(filePath) || filePath == ".." || strings.HasPrefix(filePath, "../") {
+		return "", 0, 0, errors.New("invalid path in '" + location + "', paths are relative to the top of the repository")
+	}
+
+	first, last, isRange := strings.Cut(lines, "-")
+	start, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil || start < 1 {
+		return "", 0, 0, errors.New("invalid lines in '" + location + "', expected a line such as 120 or a range such as 120-140")
+	}
+	end := start
+	if isRange {
+		end, err = strconv.Atoi(strings.TrimSpace(last))
+		if err != nil || end < start {
+			return "", 0, 0, errors.New("invalid range of lines in '" + location + "'")
+		}
+	}
+	return filePath, start, end, nil
+}
+
+// CheckCodeRef takes a code reference, the lines it referred to when it was
+// made, and the current contents of the file, nil if the file no longer
+// exists, and returns whether the lines are unchanged, have moved elsewhere in
+// the file, have changed, or have been deleted with the file
+func CheckCodeRef(ref CodeRef, referenced []string, current []byte) CodeRefState {
+	if current == nil {
+		return CodeRefState{State: CodeRefDeleted}
+	}
+
+	lines := splitLines(current)
+	if ref.StartLine >= 1 && ref.StartLine <= ref.EndLine && ref.EndLine <= len(lines) && slices.Equal(lines[ref.StartLine-1:ref.EndLine], referenced) {
+		return CodeRefState{State: CodeRefUnchanged, StartLine: ref.StartLine, EndLine: ref.EndLine}
+	}
+
+	// Look for the lines elsewhere, closest to where they were first
+	best := -1
+	for i := 0; i+len(referenced) <= len(lines); i++ {
+		if !slices.Equal(lines[i:i+len(referenced)], referenced) {
+			continue
+		}
+		if best == -1 || abs(i+1-ref.StartLine) < abs(best+1-ref.StartLine) {
+			best = i
+		}
+	}
+	if best != -1 {
+		return CodeRefState{State: CodeRefMoved, StartLine: best + 1, EndLine: best + len(referenced)}
+	}
+	return CodeRefState{State: CodeRefChanged}
+}
+
+// LoadCodeSnippets takes a pointer to a git repository, a list of tickets, and
+// a debug flag, and reads the lines each code reference points at from the
+// commit the reference was made at into its Snippet. References whose commit
+// or file can't be read are left without a snippet.
+func LoadCodeSnippets(thisRepo *git.Repository, tickets []Ticket, debugFlag bool) {
+	for i := range tickets {
+		for j := range tickets[i].CodeRefs {
+			ref := &tickets[i].CodeRefs[j]
+			contents, err := readCommitFile(thisRepo, ref.Commit, ref.Path)
+			if err != nil {
+				debug.DebugMessage(debugFlag, "Unable to read "+codeRefLocation(*ref)+" at "+ref.Commit+": "+err.Error())
+				continue
+			}
+			ref.Snippet, err = snippetLines(contents, ref.StartLine, ref.EndLine)
+			if err != nil {
+				debug.DebugMessage(debugFlag, "Unable to read "+codeRefLocation(*ref)+" at "+ref.Commit+": "+err.Error())
+			}
+		}
+	}
+}
+
+// readCommitFile returns the contents of the file at filePath in the commit
+// with the given ID
+func readCommitFile(thisRepo *git.Repository, commitOID string, filePath string) ([]byte, error) {
+	oid, err := git.NewOid(commitOID)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := thisRepo.LookupCommit(oid)
+	if err != nil {
+		return nil, err
+	}
+	defer commit.Free()
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+	entry, err := tree.EntryByPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := thisRepo.LookupBlob(entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Free()
+	return blob.Contents(), nil
+}
+
+// snippetLines returns lines start to end of contents, counting from 1.
+// Returns an error if the range is invalid or the file doesn't have those
+// lines.
+func snippetLines(contents []byte, start int, end int) ([]string, error) {
+	if start < 1 || start > end {
+		return nil, fmt.Errorf("lines %d-%d aren't a valid range", start, end)
+	}
+	lines := splitLines(contents)
+	if end > len(lines) {
+		return nil, fmt.Errorf("lines %d-%d are past the end of the file, which has %d lines", start, end, len(lines))
+	}
+	return lines[start-1 : end], nil
+}
+
+// splitLines splits the contents of a file into lines without their line
+// endings
+func splitLines(contents []byte) []string {
+	text := strings.ReplaceAll(string(contents), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// codeRefLocation returns the location a code reference points at, eg:
+// "src/foo.go:120-140"
+func codeRefLocation(ref CodeRef) string {
+	if ref.StartLine == ref.EndLine {
+		return ref.Path + ":" + strconv.Itoa(ref.StartLine)
+	}
+	return ref.Path + ":" + strconv.Itoa(ref.StartLine) + "-" + strconv.Itoa(ref.EndLine)
+}
+
+// codeRefLine returns a line describing a code reference, eg:
+// "src/foo.go:120-140 at 1a2b3c4"
+func codeRefLine(ref CodeRef) string {
+	return codeRefLocation(ref) + " at " + shortOID(ref.Commit)
+}
+
+// describeCodeRefState describes the result of checking a code reference, eg:
+// "moved to src/foo.go:130-150"
+func describeCodeRefState(ref CodeRef, state CodeRefState) string {
+	switch state.State {
+	case CodeRefMoved:
+		moved := ref
+		moved.StartLine, moved.EndLine = state.StartLine, state.EndLine
+		return "moved to " + codeRefLocation(moved)
+	case CodeRefDeleted:
+		return "deleted, " + ref.Path + " no longer exists"
+	}
+	return state.State
+}
+
+// numberedSnippet returns the lines of a code reference's snippet, each
+// starting with its line number
+func numberedSnippet(ref CodeRef) []string {
+	width := len(strconv.Itoa(ref.EndLine))
+	var lines []string
+	for i, line := range ref.Snippet {
+		lines = append(lines, fmt.Sprintf("%*d | %s", width, ref.StartLine+i, line))
+	}
+	return lines
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,224 @@
+package ticket
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestParseCodeLocation(t *testing.T) {
+	testCases := []struct {
+		location string
+		path     string
+		start    int
+		end      int
+		wantErr  bool
+	}{
+		{location: "src/foo.go:120-140", path: "src/foo.go", start: 120, end: 140},
+		{location: "./src/foo.go:7", path: "src/foo.go", start: 7, end: 7},
+		{location: "c:weird:name.txt:3-4", path: "c:weird:name.txt", start: 3, end: 4},
+		{location: "src/foo.go", wantErr: true},
+		{location: "src/foo.go:0", wantErr: true},
+		{location: "src/foo.go:140-120", wantErr: true},
+		{location: "../foo.go:1", wantErr: true},
+		{location: "/etc/passwd:1", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		path, start, end, err := ParseCodeLocation(tc.location)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseCodeLocation(%q) should have returned an error", tc.location)
+			}
+			continue
+		}
+		if err != nil || path != tc.path || start != tc.start || end != tc.end {
+			t.Errorf("ParseCodeLocation(%q) = %q, %d, %d, %v, want %q, %d, %d", tc.location, path, start, end, err, tc.path, tc.start, tc.end)
+		}
+	}
+}
+
+func TestCheckCodeRef(t *testing.T) {
+	ref := CodeRef{Path: "foo.go", StartLine: 2, EndLine: 3}
+	referenced := []string{"b", "c"}
+
+	testCases := []struct {
+		name     string
+		current  []byte
+		expected CodeRefState
+	}{
+		{name: "unchanged", current: []byte("a\nb\nc\nd\n"), expected: CodeRefState{State: CodeRefUnchanged, StartLine: 2, EndLine: 3}},
+		{name: "moved", current: []byte("x\ny\na\nb\nc\n"), expected: CodeRefState{State: CodeRefMoved, StartLine: 4, EndLine: 5}},
+		{name: "moved to the closest copy", current: []byte("b\nc\nx\nx\nx\nx\nb\nc\n"), expected: CodeRefState{State: CodeRefMoved, StartLine: 1, EndLine: 2}},
+		{name: "changed", current: []byte("a\nb\nC\nd\n"), expected: CodeRefState{State: CodeRefChanged}},
+		{name: "truncated", current: []byte("a\n"), expected: CodeRefState{State: CodeRefChanged}},
+		{name: "deleted", current: nil, expected: CodeRefState{State: CodeRefDeleted}},
+	}
+
+	for _, tc := range testCases {
+		if got := CheckCodeRef(ref, referenced, tc.current); got != tc.expected {
+			t.Errorf("%s: CheckCodeRef returned %+v, want %+v", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestCheckCodeRefInvalidRange(t *testing.T) {
+	for _, ref := range []CodeRef{{Path: "foo.go", StartLine: 0, EndLine: 1}, {Path: "foo.go", StartLine: 3, EndLine: 2}} {
+		if got := CheckCodeRef(ref, []string{"x"}, []byte("a\nb\nc\n")); got.State != CodeRefChanged {
+			t.Errorf("CheckCodeRef(%+v) returned %+v, want %s", ref, got, CodeRefChanged)
+		}
+	}
+}
+
+func TestSnippetLines(t *testing.T) {
+	contents := []byte("a\nb\nc\n")
+	lines, err := snippetLines(contents, 2, 3)
+	if err != nil || !slices.Equal(lines, []string{"b", "c"}) {
+		t.Errorf("snippetLines(2, 3) = %q, %v", lines, err)
+	}
+	for _, r := range [][2]int{{0, 1}, {3, 2}, {-1, -1}, {2, 4}} {
+		if _, err := snippetLines(contents, r[0], r[1]); err == nil {
+			t.Errorf("Expected an error for lines %d-%d", r[0], r[1])
+		}
+	}
+}
+
+func TestShowTicketTextCodeRefs(t *testing.T) {
+	ticket := Ticket{
+		ID:    1,
+		Title: "Off by one",
+		CodeRefs: []CodeRef{{
+			Path:      "foo.go",
+			StartLine: 9,
+			EndLine:   10,
+			Commit:    "1a2b3c4d5e6f",
+			Snippet:   []string{"for i := 0; i <= n; i++ {", "}"},
+		}},
+	}
+
+	var b bytes.Buffer
+	ShowTicketText(&b, ticket, []Ticket{ticket}, false, false)
+	expected := "Code: \n    foo.go:9-10 at 1a2b3c4\n         9 | for i := 0; i <= n; i++ {\n        10 | }\n"
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Expected the snippet in the output, got:\n%s", b.String())
+	}
+}
+
+// commitFileOnMain commits a file with the given contents to the top of the
+// main branch, for tests
+func commitFileOnMain(t *testing.T, thisRepo *git.Repository, name string, contents string) {
+	branch, err := thisRepo.LookupBranch("main", git.BranchLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := thisRepo.LookupCommit(branch.Target())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobOid, err := thisRepo.CreateBlobFromBuffer([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder, err := thisRepo.TreeBuilderFromTree(parentTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer builder.Free()
+	err = builder.Insert(name, blobOid, git.FilemodeBlob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeID, err := builder.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := thisRepo.LookupTree(treeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := &git.Signature{Name: "test user", Email: "test@example.com", When: time.Now()}
+	_, err = thisRepo.CreateCommit("refs/heads/main", author, author, "Change "+name, tree, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandleRefAdd(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = thisRepo.SetHead("refs/heads/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFileOnMain(t, thisRepo, "foo.go", "package foo\n\nfunc Foo() int {\n\treturn 1\n}\n")
+
+	err = HandleRefAdd(common.BranchName, 1, "foo.go:3-5", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleRefAdd(common.BranchName, 1, "foo.go:5-9", true)
+	if err == nil {
+		t.Errorf("Expected an error referring to lines past the end of the file")
+	}
+
+	var b bytes.Buffer
+	err = HandleShow(&b, []int{1}, nil, "text", "", false, false, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "3 | func Foo() int {") {
+		t.Errorf("Expected the snippet to be shown, got:\n%s", b.String())
+	}
+
+	// Moving the function is reported by check
+	commitFileOnMain(t, thisRepo, "foo.go", "package foo\n\nimport \"fmt\"\n\nfunc Foo() int {\n\treturn 1\n}\n")
+	b.Reset()
+	err = HandleRefCheck(&b, common.BranchName, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "moved to foo.go:5-7") {
+		t.Errorf("Expected the reference to have moved, got:\n%s", b.String())
+	}
+
+	// The snippet is still read from the commit the reference was made at
+	b.Reset()
+	err = HandleShow(&b, []int{1}, nil, "text", "", false, false, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "3 | func Foo() int {") {
+		t.Errorf("Expected the original snippet to be shown, got:\n%s", b.String())
+	}
+
+	err = HandleRefRemove(common.BranchName, 1, "foo.go:3-5", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs := FilterTicketsByID(tickets, 1).CodeRefs; len(refs) != 0 {
+		t.Errorf("Expected the reference to be removed, got %+v", refs)
+	}
+}
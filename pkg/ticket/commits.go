@@ -0,0 +1,272 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// CommitLink is a commit that refers to a ticket in its message
+type CommitLink struct {
+	OID     string
+	Summary string
+	Author  string
+	Created int64
+	// Branch is the branch the commit was found on
+	Branch string
+	// Closes is true if the commit used a closing keyword such as "fixes"
+	Closes bool
+	// Applied is true once a closing commit has been used to close its ticket
+	Applied bool
+}
+
+// CommitReference is a reference to a ticket found in a commit message
+type CommitReference struct {
+	TicketID int
+	Closes   bool
+}
+
+// commitKeywordPattern matches a keyword followed by one or more ticket IDs,
+// eg: "fixes #12", "refs #7, #8" or "Closes: #3 and #4"
+var commitKeywordPattern = regexp.MustCompile(`(?i)\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs?|references|see)\b:?\s*(#\d+(?:(?:\s*,\s*|\s+and\s+|\s+)#\d+)*)`)
+
+// commitTrailerPattern matches a git trailer line, eg: "Ticket: 12"
+var commitTrailerPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z-]*):\s*(.*)$`)
+
+// ticketIDPattern matches a ticket ID with an optional leading '#'
+var ticketIDPattern = regexp.MustCompile(`#?(\d+)`)
+
+// closingKeywords are the keywords and trailers that close the tickets they
+// refer to
+var closingKeywords = []string{"close", "closes", "closed", "fix", "fixes", "fixed", "resolve", "resolves", "resolved"}
+
+// ticketTrailers are the git trailers that refer to tickets, in addition to
+// closingKeywords and "refs"
+var ticketTrailers = []string{"ticket", "tickets", "ref", "refs", "references"}
+
+// HandleScanCommits takes a writer, a branch name, a list of branches to scan,
+// a close flag, and a debug flag. It walks the history of each branch for
+// commits whose messages refer to tickets, and records the commits on those
+// tickets. If closeFlag is true, tickets referred to with a closing keyword
+// such as "fixes #12" are closed, including by commits linked in an earlier
+// scan whose close has not been applied yet. Every change is made in a single commit,
+// and a line describing each change is written to w. Commits already recorded
+// on a ticket are skipped, so branches can be scanned again safely. If no
+// branches are given the current branch is scanned.
+func HandleScanCommits(w io.Writer, branchName string, branches []string, closeFlag bool, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	if len(branches) == 0 {
+		head, err := thisRepo.Head()
+		if err != nil {
+			return err
+		}
+		branches = []string{head.Shorthand()}
+		head.Free()
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	index := make(map[int]int)
+	for i, t := range tickets {
+		index[t.ID] = i
+	}
+
+	changed := make(map[int]bool)
+	for _, branch := range branches {
+		if branch == branchName {
+			return errors.New("the " + branchName + " branch holds tickets and can't be scanned for commits")
+		}
+		debug.DebugMessage(debugFlag, "Scanning commits on branch "+branch)
+
+		links, err := scanBranch(thisRepo, branch)
+		if err != nil {
+			return err
+		}
+		for ticketID, commits := range links {
+			i, ok := index[ticketID]
+			if !ok {
+				debug.DebugMessage(debugFlag, "Skipping reference to unknown ticket "+strconv.Itoa(ticketID))
+				continue
+			}
+			for _, link := range commits {
+				if !AddCommitLink(&tickets[i], link) {
+					continue
+				}
+				changed[ticketID] = true
+				fmt.Fprintf(w, "Linked commit %s to ticket %d: %s\n", shortOID(link.OID), ticketID, link.Summary)
+			}
+		}
+	}
+
+	if closeFlag {
+		for i := range tickets {
+			if !applyClosingCommits(&tickets[i]) {
+				continue
+			}
+			changed[tickets[i].ID] = true
+			if IsClosed(tickets[i]) {
+				continue
+			}
+			tickets[i].Status = "closed"
+			fmt.Fprintf(w, "Closed ticket %d\n", tickets[i].ID)
+			for _, parent := range SyncPromotedChecklistItems(tickets[i], tickets) {
+				tickets[index[parent.ID]] = parent
+				changed[parent.ID] = true
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "No new commits refer to tickets")
+		return nil
+	}
+
+	var ids []int
+	for id := range changed {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	var toCommit []common.TicketInterface
+	var names []string
+	for _, id := range ids {
+		toCommit = append(toCommit, &tickets[index[id]])
+		names = append(names, strconv.Itoa(id))
+	}
+	return repo.CommitTickets(thisRepo, branchName, toCommit, "Linking commits to tickets "+strings.Join(names, ", "), debugFlag)
+}
+
+// scanBranch walks the history of branch and returns the commits that refer
+// to tickets, keyed by ticket ID, oldest first
+func scanBranch(thisRepo *git.Repository, branch string) (map[int][]CommitLink, error) {
+	walk, err := thisRepo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+	walk.Sorting(git.SortTime | git.SortReverse)
+	err = walk.PushRef("refs/heads/" + branch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan branch %s: %s", branch, err)
+	}
+
+	links := make(map[int][]CommitLink)
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		for _, ref := range ParseCommitReferences(commit.Message()) {
+			links[ref.TicketID] = append(links[ref.TicketID], CommitLink{
+				OID:     commit.Id().String(),
+				Summary: commit.Summary(),
+				Author:  commit.Author().Name + " <" + commit.Author().Email + ">",
+				Created: commit.Author().When.Unix(),
+				Branch:  branch,
+				Closes:  ref.Closes,
+			})
+		}
+		return true
+	})
+	return links, err
+}
+
+// ParseCommitReferences takes a commit message and returns the tickets it
+// refers to, in the order they are first mentioned. Tickets can be referred to
+// with keywords such as "fixes #12" or "refs #7, #8", or with git trailers in
+// the last paragraph of the message such as "Ticket: 12" or "Fixes: #12". A
+// reference closes its ticket if any mention of the ticket uses a closing
+// keyword, see closingKeywords.
+func ParseCommitReferences(message string) []CommitReference {
+	var refs []CommitReference
+	add := func(keyword string, ids string) {
+		closes := slices.Contains(closingKeywords, strings.ToLower(keyword))
+		for _, match := range ticketIDPattern.FindAllStringSubmatch(ids, -1) {
+			id, err := strconv.Atoi(match[1])
+			if err != nil || id == 0 {
+				continue
+			}
+			i := slices.IndexFunc(refs, func(ref CommitReference) bool { return ref.TicketID == id })
+			if i == -1 {
+				refs = append(refs, CommitReference{TicketID: id, Closes: closes})
+			} else if closes {
+				refs[i].Closes = true
+			}
+		}
+	}
+
+	for _, match := range commitKeywordPattern.FindAllStringSubmatch(message, -1) {
+		add(match[1], match[2])
+	}
+
+	// Trailers are the "Key: value" lines of the last paragraph
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) > 1 {
+		for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+			match := commitTrailerPattern.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				continue
+			}
+			key := strings.ToLower(match[1])
+			if slices.Contains(ticketTrailers, key) || slices.Contains(closingKeywords, key) {
+				add(key, match[2])
+			}
+		}
+	}
+	return refs
+}
+
+// AddCommitLink takes a pointer to a ticket and a commit link, and records the
+// commit on the ticket. Returns false if the commit was already recorded.
+func AddCommitLink(t *Ticket, link CommitLink) bool {
+	for _, existing := range t.Commits {
+		if existing.OID == link.OID {
+			return false
+		}
+	}
+	t.Commits = append(t.Commits, link)
+	return true
+}
+
+// applyClosingCommits takes a pointer to a ticket and marks each of its
+// closing commits as applied. Returns false if there were none left to apply.
+func applyClosingCommits(t *Ticket) bool {
+	applied := false
+	for i := range t.Commits {
+		if t.Commits[i].Closes && !t.Commits[i].Applied {
+			t.Commits[i].Applied = true
+			applied = true
+		}
+	}
+	return applied
+}
+
+// commitLinkLine returns a line describing a linked commit, eg: "1a2b3c4
+// fixes: Fix the build (John Smith <jsmith@example.com>, 2024-05-24 01:11:03
+// -0700)"
+func commitLinkLine(link CommitLink) string {
+	kind := "refs"
+	if link.Closes {
+		kind = "fixes"
+	}
+	return fmt.Sprintf("%s %s: %s (%s, %s)", shortOID(link.OID), kind, link.Summary, link.Author, formatTime(link.Created))
+}
+
+// shortOID returns the abbreviated form of a commit ID
+func shortOID(oid string) string {
+	if len(oid) > 7 {
+		return oid[:7]
+	}
+	return oid
+}
@@ -0,0 +1,150 @@
+package ticket
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestParseCommitReferences(t *testing.T) {
+	testCases := []struct {
+		message  string
+		expected []CommitReference
+	}{
+		{message: "Fix the login page, fixes #12", expected: []CommitReference{{TicketID: 12, Closes: true}}},
+		{message: "Tidy up\n\nrefs #7, #8 and #9", expected: []CommitReference{{TicketID: 7}, {TicketID: 8}, {TicketID: 9}}},
+		{message: "Closes: #3 #4", expected: []CommitReference{{TicketID: 3, Closes: true}, {TicketID: 4, Closes: true}}},
+		{message: "See #2, then resolved #2", expected: []CommitReference{{TicketID: 2, Closes: true}}},
+		{message: "Add the thing\n\nMore detail.\n\nTicket: 12\nFixes: 13, #14\nSigned-off-by: John Smith <jsmith@example.com>", expected: []CommitReference{{TicketID: 12}, {TicketID: 13, Closes: true}, {TicketID: 14, Closes: true}}},
+		{message: "Ticket: 12", expected: nil},
+		{message: "Fixes 3 bugs in #4", expected: nil},
+		{message: "prefixes #5", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		refs := ParseCommitReferences(tc.message)
+		if !reflect.DeepEqual(refs, tc.expected) {
+			t.Errorf("ParseCommitReferences(%q) = %v, want %v", tc.message, refs, tc.expected)
+		}
+	}
+}
+
+func TestAddCommitLink(t *testing.T) {
+	ticket := Ticket{ID: 1}
+	link := CommitLink{OID: "1a2b3c4d5e6f", Summary: "Fix it", Closes: true}
+	if !AddCommitLink(&ticket, link) {
+		t.Errorf("Expected the first link to be added")
+	}
+	if AddCommitLink(&ticket, link) {
+		t.Errorf("Expected the same commit not to be added twice")
+	}
+	if len(ticket.Commits) != 1 {
+		t.Errorf("Expected 1 commit, got %v", ticket.Commits)
+	}
+}
+
+func TestApplyClosingCommits(t *testing.T) {
+	ticket := Ticket{ID: 1, Commits: []CommitLink{{OID: "1a2b3c4"}, {OID: "5e6f7a8", Closes: true}}}
+	if !applyClosingCommits(&ticket) {
+		t.Errorf("Expected the closing commit to be applied")
+	}
+	if ticket.Commits[0].Applied || !ticket.Commits[1].Applied {
+		t.Errorf("Unexpected commits after applying: %+v", ticket.Commits)
+	}
+	if applyClosingCommits(&ticket) {
+		t.Errorf("Expected nothing left to apply")
+	}
+}
+
+func TestHandleScanCommits(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = HandleCreate(common.BranchName, 0, "My second ticket", "", []string{}, 1, 1, "open", []Comment{}, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add commits referring to the tickets on main
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := &git.Signature{Name: "test user", Email: "test@example.com", When: time.Now()}
+	for _, message := range []string{"Start on the login page\n\nTicket: 2", "Finish the login page, fixes #1 and #3"} {
+		branch, err := thisRepo.LookupBranch("main", git.BranchLocal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent, err := thisRepo.LookupCommit(branch.Target())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree, err := parent.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = thisRepo.CreateCommit("refs/heads/main", author, author, message, tree, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = HandleScanCommits(&bytes.Buffer{}, common.BranchName, []string{common.BranchName}, false, true)
+	if err == nil {
+		t.Errorf("Expected an error scanning the giticket branch")
+	}
+
+	// Scanning without closing links the commits but leaves the tickets open
+	var b bytes.Buffer
+	err = HandleScanCommits(&b, common.BranchName, []string{"main"}, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "Closed ticket") || strings.Contains(b.String(), "ticket 3") {
+		t.Errorf("Unexpected scan output:\n%s", b.String())
+	}
+
+	// Scanning again with closing closes tickets by the commits already linked
+	b.Reset()
+	err = HandleScanCommits(&b, common.BranchName, []string{"main"}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "Closed ticket 1\n" {
+		t.Errorf("Unexpected scan output:\n%s", b.String())
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := FilterTicketsByID(tickets, 1)
+	second := FilterTicketsByID(tickets, 2)
+	if first.Status != "closed" || len(first.Commits) != 1 || !first.Commits[0].Closes || !first.Commits[0].Applied || first.Commits[0].Branch != "main" {
+		t.Errorf("Unexpected ticket 1 after scanning: %+v", first)
+	}
+	if second.Status != "open" || len(second.Commits) != 1 || second.Commits[0].Summary != "Start on the login page" {
+		t.Errorf("Unexpected ticket 2 after scanning: %+v", second)
+	}
+
+	// Scanning again finds nothing new
+	b.Reset()
+	err = HandleScanCommits(&b, common.BranchName, []string{"main"}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "No new commits refer to tickets\n" {
+		t.Errorf("Unexpected output scanning again:\n%s", b.String())
+	}
+}
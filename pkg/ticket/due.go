@@ -0,0 +1,231 @@
+package ticket
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// dayUnitPattern matches the day and week units accepted by ParseDuration in
+// addition to those accepted by time.ParseDuration
+var dayUnitPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)([dw])`)
+
+// HandleDue takes a branch name, a ticket ID, a due date as a unix time, and
+// a debug flag, and sets the ticket's due date. A due date of 0 removes it.
+// Returns an error if the ticket doesn't exist, or if there was another
+// error.
+func HandleDue(branchName string, ticketID int, due int64, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	t.Due = due
+	commitMessage := "Setting due date of ticket " + t.TicketFilename() + " to " + formatDue(due)
+	if due == 0 {
+		commitMessage = "Removing due date from ticket " + t.TicketFilename()
+	}
+	return repo.Commit(&t, thisRepo, branchName, author, commitMessage, debugFlag)
+}
+
+// HandleDueReport takes a writer, a branch name, a duration, and a debug flag,
+// and writes a table of the open tickets that are overdue or due within the
+// duration to w, soonest first. A duration of 0 includes every open ticket
+// with a due date.
+func HandleDueReport(w io.Writer, branchName string, within time.Duration, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	due := DueTickets(tickets, time.Now(), within)
+	if len(due) == 0 {
+		fmt.Fprintln(w, "No tickets are due")
+		return nil
+	}
+	renderTicketsTable(w, due, []string{"id", "title", "status", "due"}, common.TerminalWidth())
+	return nil
+}
+
+// ParseDue takes a due date and the current time, and returns the due date as
+// a unix time. Due dates may be given as:
+//
+//   - a date such as 2026-11-01
+//   - today or tomorrow
+//   - the name of a weekday such as friday or fri, meaning the next one after
+//     today
+//   - a duration from now such as +3d, +2w or +4h, see ParseDuration
+//
+// Due dates given as a day are due at the end of that day in the local time
+// zone. Returns an error if due is not in one of these forms.
+func ParseDue(due string, now time.Time) (int64, error) {
+	due = strings.ToLower(strings.TrimSpace(due))
+
+	switch due {
+	case "today":
+		return endOfDay(now), nil
+	case "tomorrow":
+		return endOfDay(now.AddDate(0, 0, 1)), nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if due == name || due == name[:3] {
+			days := (int(day)-int(now.Weekday())+6)%7 + 1
+			return endOfDay(now.AddDate(0, 0, days)), nil
+		}
+	}
+
+	if offset, isOffset := strings.CutPrefix(due, "+"); isOffset {
+		if strings.HasPrefix(offset, "+") || strings.HasPrefix(offset, "-") {
+			return 0, errors.New("invalid due date '" + due + "', an offset such as +3d can't have another sign")
+		}
+		d, err := ParseDuration(offset)
+		if err != nil {
+			return 0, err
+		}
+		// Offsets in days or weeks are due at the end of the day
+		if strings.HasSuffix(offset, "d") || strings.HasSuffix(offset, "w") {
+			return endOfDay(now.Add(d)), nil
+		}
+		return now.Add(d).Unix(), nil
+	}
+
+	unix, err := ParseDate(due)
+	if err != nil {
+		return 0, errors.New("invalid due date '" + due + "', expected a date such as 2026-11-01, today, tomorrow, a weekday such as friday, or an offset such as +3d")
+	}
+	return unix, nil
+}
+
+// ParseDate takes a date in the form 2006-01-02 and returns the unix time of
+// the end of that day in the local time zone, so that something due on a day
+// is not overdue until the day is over. Returns an error if the date is not
+// in that form.
+func ParseDate(date string) (int64, error) {
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(date), time.Local)
+	if err != nil {
+		return 0, errors.New("invalid date '" + date + "', expected YYYY-MM-DD")
+	}
+	return endOfDay(day), nil
+}
+
+// ParseDuration parses a duration like time.ParseDuration, and also accepts
+// days and weeks, eg: "7d", "2w" or "1d12h". Days are 24 hours long.
+func ParseDuration(s string) (time.Duration, error) {
+	var err error
+	hours := dayUnitPattern.ReplaceAllStringFunc(strings.TrimSpace(s), func(match string) string {
+		parts := dayUnitPattern.FindStringSubmatch(match)
+		n, parseErr := strconv.ParseFloat(parts[1], 64)
+		if parseErr != nil {
+			err = parseErr
+		}
+		if parts[2] == "w" {
+			n *= 7
+		}
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(hours)
+	if err != nil {
+		return 0, errors.New("invalid duration '" + s + "', expected a duration such as 7d, 2w or 1h30m")
+	}
+	return d, nil
+}
+
+// IsOverdue takes a ticket and the current time, and returns true if the
+// ticket is open and past its due date
+func IsOverdue(t Ticket, now time.Time) bool {
+	return t.Due != 0 && !IsClosed(t) && now.Unix() > t.Due
+}
+
+// DueTickets takes a list of tickets, the current time, and a duration, and
+// returns the open tickets that are overdue or due within the duration,
+// sorted by due date. A duration of 0 returns every open ticket with a due
+// date.
+func DueTickets(tickets []Ticket, now time.Time, within time.Duration) []Ticket {
+	due := []Ticket{}
+	for _, t := range tickets {
+		if t.Due == 0 || IsClosed(t) {
+			continue
+		}
+		if within > 0 && t.Due > now.Add(within).Unix() {
+			continue
+		}
+		due = append(due, t)
+	}
+	slices.SortFunc(due, func(a, b Ticket) int {
+		if c := cmp.Compare(a.Due, b.Due); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return due
+}
+
+// describeDue returns the ticket's due date as shown in lists, with
+// "(overdue)" after it if the ticket is overdue
+func describeDue(t Ticket, now time.Time) string {
+	if t.Due == 0 {
+		return ""
+	}
+	if IsOverdue(t, now) {
+		return formatDue(t.Due) + " (overdue)"
+	}
+	return formatDue(t.Due)
+}
+
+// formatDue formats a due date as a date, along with the time if it isn't
+// due at the end of the day, or "-" if there is no due date
+func formatDue(due int64) string {
+	if due == 0 {
+		return "-"
+	}
+	dueTime := time.Unix(due, 0)
+	if due == endOfDay(dueTime) {
+		return dueTime.Format("2006-01-02")
+	}
+	return dueTime.Format("2006-01-02 15:04")
+}
+
+// endOfDay returns the unix time of the last second of t's day, in t's time
+// zone
+func endOfDay(t time.Time) int64 {
+	year, month, day := t.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location()).Unix() - 1
+}
@@ -0,0 +1,173 @@
+package ticket
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestParseDue(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2026, 10, 21, 9, 30, 0, 0, time.Local)
+
+	testCases := []struct {
+		due         string
+		expected    string
+		expectedErr bool
+	}{
+		{due: "2026-11-01", expected: "2026-11-01 23:59:59"},
+		{due: "today", expected: "2026-10-21 23:59:59"},
+		{due: "Tomorrow", expected: "2026-10-22 23:59:59"},
+		{due: "friday", expected: "2026-10-23 23:59:59"},
+		{due: "wed", expected: "2026-10-28 23:59:59"},
+		{due: "+3d", expected: "2026-10-24 23:59:59"},
+		{due: "+2w", expected: "2026-11-04 23:59:59"},
+		{due: "+4h", expected: "2026-10-21 13:30:00"},
+		{due: "+1h30m", expected: "2026-10-21 11:00:00"},
+		{due: "next week", expectedErr: true},
+		{due: "+soon", expectedErr: true},
+		{due: "+-3d", expectedErr: true},
+		{due: "++3d", expectedErr: true},
+		{due: "2026-13-01", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		due, err := ParseDue(tc.due, now)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ParseDue(%q) returned error %v, expected an error: %t", tc.due, err, tc.expectedErr)
+			continue
+		}
+		if tc.expectedErr {
+			continue
+		}
+		if got := time.Unix(due, 0).Format("2006-01-02 15:04:05"); got != tc.expected {
+			t.Errorf("ParseDue(%q) = %s, want %s", tc.due, got, tc.expected)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		s           string
+		expected    time.Duration
+		expectedErr bool
+	}{
+		{s: "7d", expected: 7 * 24 * time.Hour},
+		{s: "2w", expected: 14 * 24 * time.Hour},
+		{s: "1d12h", expected: 36 * time.Hour},
+		{s: "1h30m", expected: 90 * time.Minute},
+		{s: "0.5d", expected: 12 * time.Hour},
+		{s: "d", expectedErr: true},
+		{s: "", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		d, err := ParseDuration(tc.s)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ParseDuration(%q) returned error %v, expected an error: %t", tc.s, err, tc.expectedErr)
+		}
+		if d != tc.expected {
+			t.Errorf("ParseDuration(%q) = %s, want %s", tc.s, d, tc.expected)
+		}
+	}
+}
+
+func TestDueTickets(t *testing.T) {
+	now := time.Date(2026, 10, 21, 9, 30, 0, 0, time.Local)
+	yesterday, _ := ParseDue("2026-10-20", now)
+	friday, _ := ParseDue("friday", now)
+	nextMonth, _ := ParseDue("2026-11-21", now)
+	tickets := []Ticket{
+		{ID: 1, Status: "open", Due: nextMonth},
+		{ID: 2, Status: "open"},
+		{ID: 3, Status: "open", Due: friday},
+		{ID: 4, Status: "closed", Due: yesterday},
+		{ID: 5, Status: "new", Due: yesterday},
+	}
+
+	ids := func(tickets []Ticket) []int {
+		var ids []int
+		for _, t := range tickets {
+			ids = append(ids, t.ID)
+		}
+		return ids
+	}
+	if got := ids(DueTickets(tickets, now, 0)); !slices.Equal(got, []int{5, 3, 1}) {
+		t.Errorf("DueTickets without a duration = %v, want [5 3 1]", got)
+	}
+	if got := ids(DueTickets(tickets, now, 7*24*time.Hour)); !slices.Equal(got, []int{5, 3}) {
+		t.Errorf("DueTickets within a week = %v, want [5 3]", got)
+	}
+
+	if !IsOverdue(tickets[4], now) || IsOverdue(tickets[3], now) || IsOverdue(tickets[2], now) {
+		t.Errorf("IsOverdue returned the wrong result")
+	}
+	if got := describeDue(tickets[4], now); got != "2026-10-20 (overdue)" {
+		t.Errorf("describeDue = %q, want \"2026-10-20 (overdue)\"", got)
+	}
+
+	SortTickets(tickets, "due")
+	if got := ids(tickets); !slices.Equal(got, []int{4, 5, 3, 1, 2}) {
+		t.Errorf("Sorting by due = %v, want [4 5 3 1 2]", got)
+	}
+}
+
+func TestHandleDue(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	yesterday := endOfDay(time.Now().AddDate(0, 0, -1))
+	err = HandleDue(common.BranchName, 1, yesterday, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleDue(common.BranchName, 2, yesterday, true)
+	if err == nil {
+		t.Errorf("Expected an error setting the due date of a ticket that doesn't exist")
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due := FilterTicketsByID(tickets, 1).Due; due != yesterday {
+		t.Errorf("Ticket 1 is due at %d, want %d", due, yesterday)
+	}
+
+	var b bytes.Buffer
+	err = HandleDueReport(&b, common.BranchName, 7*24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "(overdue)") {
+		t.Errorf("Expected ticket 1 to be reported as overdue:\n%s", b.String())
+	}
+
+	err = HandleDue(common.BranchName, 1, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	err = HandleDueReport(&b, common.BranchName, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "No tickets are due\n" {
+		t.Errorf("Expected no tickets to be due after clearing the due date:\n%s", b.String())
+	}
+}
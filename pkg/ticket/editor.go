@@ -0,0 +1,102 @@
+package ticket
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// GetEditor takes a debug flag and returns the command used to edit text: the
+// editor setting, then the VISUAL and EDITOR environment variables, then vi.
+func GetEditor(debugFlag bool) string {
+	thisRepo, err := git.OpenRepository(".")
+	if err == nil {
+		settings, err := LoadSettings(thisRepo, common.BranchName, debugFlag)
+		if err == nil && settings.Editor != "" {
+			return settings.Editor
+		}
+	}
+
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(variable); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// scissorsLine separates the text being edited in EditText from the
+// instructions below it
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// EditText takes the initial text to edit, instructions to show the user, and
+// a debug flag. It opens the user's editor (see GetEditor) on a temporary file
+// containing the text followed by a scissors line and the instructions, and
+// returns the edited text above the scissors line with surrounding whitespace
+// trimmed, see editedText. Returns an error if the editor fails or the text is
+// left empty.
+func EditText(initial string, instructions string, debugFlag bool) (string, error) {
+	file, err := os.CreateTemp("", "giticket-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	contents := initial + "\n" + scissorsLine + "\n"
+	for _, line := range strings.Split(instructions+"\nDo not change or remove the line above, everything below it is ignored.", "\n") {
+		contents += "# " + line + "\n"
+	}
+	_, err = file.WriteString(contents)
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+	err = file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	// The editor setting may include arguments, such as "code --wait"
+	editor := strings.Fields(GetEditor(debugFlag))
+	if len(editor) == 0 {
+		return "", errors.New("no editor configured")
+	}
+	debug.DebugMessage(debugFlag, "Editing "+file.Name()+" with "+strings.Join(editor, " "))
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return "", errors.New("there was a problem with the editor: " + err.Error())
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	text := editedText(string(edited))
+	if text == "" {
+		return "", errors.New("aborting because the text was left empty")
+	}
+	return text, nil
+}
+
+// editedText returns the text of an edited file up to the scissors line, with
+// surrounding whitespace trimmed. Lines starting with '#' above the scissors
+// line, such as Markdown headings, are kept.
+func editedText(edited string) string {
+	var lines []string
+	for _, line := range strings.Split(edited, "\n") {
+		if strings.TrimRight(line, " \r") == scissorsLine {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
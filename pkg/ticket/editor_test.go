@@ -0,0 +1,14 @@
+package ticket
+
+import "testing"
+
+func TestEditedText(t *testing.T) {
+	edited := "## Steps to reproduce\n\n1. Reverse the polarity\n# Not a heading, but kept\n\n" + scissorsLine + "\n# Write your comment above.\nignored\n"
+	want := "## Steps to reproduce\n\n1. Reverse the polarity\n# Not a heading, but kept"
+	if got := editedText(edited); got != want {
+		t.Errorf("editedText() = %q, want %q", got, want)
+	}
+	if got := editedText("  \n" + scissorsLine + "\n# instructions\n"); got != "" {
+		t.Errorf("editedText() of an empty comment = %q, want \"\"", got)
+	}
+}
@@ -44,6 +44,18 @@ func FilterTicketsByID(tickets []Ticket, id int) Ticket {
 	return t
 }
 
+// FilterTicketsByAssignee takes a list of tickets and an email address, and
+// returns the tickets assigned to someone with that email address
+func FilterTicketsByAssignee(tickets []Ticket, email string) []Ticket {
+	assigned := []Ticket{}
+	for _, t := range tickets {
+		if IsAssignedTo(t, email) {
+			assigned = append(assigned, t)
+		}
+	}
+	return assigned
+}
+
 // HandleFilterDelete takes the name of a filter and a debug flag, and deletes
 // the filter. It returns an error if there is one.
 func HandleFilterDelete(filterName string, debugFlag bool) error {
@@ -425,28 +437,40 @@ func FilterTickets(tickets []Ticket, filterName string, debugFlag bool) (*[]Tick
 		return nil, err
 	}
 
+	filteredTickets, err := QueryTickets(tickets, filter.Filter, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	return &filteredTickets, nil
+}
+
+// QueryTickets takes a list of tickets, a jq query such as
+// '.[] | select(.Status == "open")', and a debug flag, and returns the tickets
+// the query produces. Returns an error if the query is invalid, or produces
+// something other than tickets.
+func QueryTickets(tickets []Ticket, query string, debugFlag bool) ([]Ticket, error) {
 	// Parse the filter
-	queryObj, err := gojq.Parse(filter.Filter)
+	queryObj, err := gojq.Parse(query)
 	if err != nil {
 		return nil, fmt.Errorf("Error parsing filter: " + err.Error())
 	}
 
-	// Convert []Ticket into []map[string]interface{} for gojq
-	var listOfTickets []map[string]interface{}
+	// Convert []Ticket into []interface{} of map[string]interface{} for gojq,
+	// which only accepts the types encoding/json decodes to
+	var listOfTickets []interface{}
 	ticketsJSON, err := json.Marshal(tickets)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("The list of tickets as JSON: " + string(ticketsJSON))
+	debug.DebugMessage(debugFlag, "The list of tickets as JSON: "+string(ticketsJSON))
 	err = json.Unmarshal(ticketsJSON, &listOfTickets)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("The length of listOfTickets is " + strconv.Itoa(len(listOfTickets)))
+	debug.DebugMessage(debugFlag, "The length of listOfTickets is "+strconv.Itoa(len(listOfTickets)))
 
 	// Apply the filter
 	iter := queryObj.Run(listOfTickets)
-	var iterTicket Ticket
 	var filteredTickets []Ticket
 	for {
 		result, ok := iter.Next()
@@ -461,7 +485,10 @@ func FilterTickets(tickets []Ticket, filterName string, debugFlag bool) (*[]Tick
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println("Trying to unmarshal: " + string(resultJSON))
+		debug.DebugMessage(debugFlag, "Trying to unmarshal: "+string(resultJSON))
+		// A new ticket each time, as decoding into one that was already
+		// appended would overwrite its slices
+		var iterTicket Ticket
 		err = json.Unmarshal(resultJSON, &iterTicket)
 		if err != nil {
 			return nil, err
@@ -469,20 +496,24 @@ func FilterTickets(tickets []Ticket, filterName string, debugFlag bool) (*[]Tick
 		filteredTickets = append(filteredTickets, iterTicket)
 	}
 
-	return &filteredTickets, nil
+	return filteredTickets, nil
 }
 
 // GetCurrentFilter takes a debug flag and returns the name of the current
-// filter. Returns an error if there is one. This function may return an empty
-// string if the current filter has not yet been set.
+// filter. The current filter is a setting, so a per-user choice of filter takes
+// precedence over the shared CurrentFilter in filters.json, see LoadSettings.
+// Returns an error if there is one. This function may return an empty string
+// if the current filter has not yet been set.
 func GetCurrentFilter(debugFlag bool) (string, error) {
 	debug.DebugMessage(debugFlag, "GetCurrentFilter() start")
-	filters, err := GetFilters(common.BranchName, debugFlag)
-	if err.Error() == "the path 'filters.json' does not exist in the given tree" {
-		return "", nil
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return "", err
 	}
+
+	settings, err := LoadSettings(thisRepo, common.BranchName, debugFlag)
 	if err != nil {
 		return "", err
 	}
-	return filters.CurrentFilter, nil
+	return settings.CurrentFilter, nil
 }
@@ -1,6 +1,9 @@
 package ticket
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestFilterTicketsByID(t *testing.T) {
 	testCases := []struct {
@@ -34,3 +37,24 @@ func TestFilterTicketsByID(t *testing.T) {
 		}
 	}
 }
+
+func TestQueryTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Status: "new", Labels: []string{"a", "b"}, Assignees: []string{"alice", "bob"}},
+		{ID: 2, Status: "closed"},
+		{ID: 3, Status: "new", Labels: []string{"c"}, Assignees: []string{"carol"}},
+	}
+	got, err := QueryTickets(tickets, `.[] | select(.Status == "new")`, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !reflect.DeepEqual(got[0].Labels, []string{"a", "b"}) || !reflect.DeepEqual(got[1].Labels, []string{"c"}) ||
+		!reflect.DeepEqual(got[0].Assignees, []string{"alice", "bob"}) || !reflect.DeepEqual(got[1].Assignees, []string{"carol"}) {
+		t.Errorf("QueryTickets() = %+v", got)
+	}
+
+	_, err = QueryTickets(tickets, `.[] | select(`, false)
+	if err == nil {
+		t.Error("QueryTickets() with an invalid query didn't return an error")
+	}
+}
@@ -0,0 +1,234 @@
+package ticket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// formatsDir is the directory named formats are stored in, relative to the
+// .giticket directory
+const formatsDir = "formats"
+
+// formatNamePattern is the pattern that names of stored formats must match
+var formatNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// FormatFuncs are the functions available to --format templates in addition
+// to the text/template builtins
+var FormatFuncs = template.FuncMap{
+	// join joins a list of strings with a separator, eg: {{join .Labels ","}}
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// truncate shortens a string to width characters, eg: {{truncate .Title 20}}
+	"truncate": truncate,
+	// pad pads a string with spaces to width characters, or truncates it if
+	// it's longer, eg: {{pad .Status 10}}
+	"pad": padRight,
+	// date formats a unix timestamp as a date, eg: {{date .Created}}
+	"date": func(unix int64) string { return time.Unix(unix, 0).Format("2006-01-02") },
+	// datetime formats a unix timestamp as a date and time
+	"datetime": formatTime,
+	// timeFormat formats a unix timestamp with a Go time layout, eg:
+	// {{timeFormat .Created "Jan 2"}}
+	"timeFormat": func(unix int64, layout string) string { return time.Unix(unix, 0).Format(layout) },
+	// ago formats a unix timestamp relative to now, eg: "3 days ago"
+	"ago": func(unix int64) string { return ago(time.Since(time.Unix(unix, 0))) },
+	// closed returns true if a ticket's status is closed, see IsClosed, eg:
+	// {{if closed .}}done{{end}}
+	"closed": IsClosed,
+	// json formats a value as json, eg: {{json .Labels}}
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// default returns value, or fallback if value is empty, eg:
+	// {{default .Status "-"}}
+	"default": func(value string, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// ParseFormat takes a --format template and parses it as a text/template with
+// FormatFuncs. The escape sequences \t, \n and \\ are expanded outside of
+// {{actions}}, so that tabs and newlines can be typed in a shell.
+func ParseFormat(format string) (*template.Template, error) {
+	unescape := strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+	var b strings.Builder
+	rest := format
+	for rest != "" {
+		start := strings.Index(rest, "{{")
+		if start == -1 {
+			b.WriteString(unescape.Replace(rest))
+			break
+		}
+		b.WriteString(unescape.Replace(rest[:start]))
+		end := strings.Index(rest[start:], "}}")
+		if end == -1 {
+			// Let the template parser report the unclosed action
+			b.WriteString(rest[start:])
+			break
+		}
+		b.WriteString(rest[start : start+end+2])
+		rest = rest[start+end+2:]
+	}
+
+	return template.New("format").Funcs(FormatFuncs).Option("missingkey=error").Parse(b.String())
+}
+
+// RenderFormat takes a writer, a list of tickets, and a parsed --format
+// template, and executes the template once for each ticket. A newline is
+// written after each ticket unless the template output already ends with one.
+func RenderFormat(w io.Writer, tickets []Ticket, tmpl *template.Template) error {
+	for _, t := range tickets {
+		var b strings.Builder
+		err := tmpl.Execute(&b, t)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+		_, err = io.WriteString(w, b.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveFormat takes a pointer to a git repository, a branch name, a
+// --format value, and a debugFlag. A format starting with '@' names a format
+// stored with HandleFormatSave, and is replaced by the stored template. Any
+// other format is returned as is.
+func ResolveFormat(thisRepo *git.Repository, branchName string, format string, debugFlag bool) (string, error) {
+	name, isNamed := strings.CutPrefix(format, "@")
+	if !isNamed {
+		return format, nil
+	}
+
+	debug.DebugMessage(debugFlag, "Reading stored format "+name)
+	if !formatNamePattern.MatchString(name) {
+		return "", errors.New("invalid format name '" + name + "'")
+	}
+	contents, err := repo.ReadFile(thisRepo, branchName, path.Join(formatsDir, name), debugFlag)
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return "", errors.New("no format named '" + name + "', see giticket format --list")
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\n"), nil
+}
+
+// HandleFormatSave takes the name of a format, a --format template, and a
+// debugFlag, and stores the template under .giticket/formats so it can be used
+// with --format @name. An existing format with the same name is replaced.
+func HandleFormatSave(name string, format string, debugFlag bool) error {
+	if !formatNamePattern.MatchString(name) {
+		return errors.New("invalid format name '" + name + "', names may contain letters, numbers, '.', '_' and '-'")
+	}
+	_, err := ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{path.Join(formatsDir, name): []byte(format + "\n")}
+	return repo.CommitFiles(thisRepo, common.BranchName, files, "Saving format "+name, debugFlag)
+}
+
+// HandleFormatDelete takes the name of a stored format and a debugFlag, and
+// deletes the format. Returns an error if the format doesn't exist.
+func HandleFormatDelete(name string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	names, err := repo.ListFiles(thisRepo, common.BranchName, formatsDir, debugFlag)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, n := range names {
+		found = found || n == name
+	}
+	if !found {
+		return errors.New("no format named '" + name + "'")
+	}
+
+	files := map[string][]byte{path.Join(formatsDir, name): nil}
+	return repo.CommitFiles(thisRepo, common.BranchName, files, "Deleting format "+name, debugFlag)
+}
+
+// HandleFormatList takes a writer and a debugFlag, and writes the name and
+// template of each stored format to w
+func HandleFormatList(w io.Writer, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	names, err := repo.ListFiles(thisRepo, common.BranchName, formatsDir, debugFlag)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		format, err := ResolveFormat(thisRepo, common.BranchName, "@"+name, debugFlag)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: %s\n", name, format)
+	}
+	return nil
+}
+
+// ago formats a duration as a rough human readable age such as "5 minutes
+// ago" or "2 years ago"
+func ago(d time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		n := int(d / unit.size)
+		if n == 1 {
+			return "1 " + unit.name + " ago"
+		}
+		if n > 1 {
+			return strconv.Itoa(n) + " " + unit.name + "s ago"
+		}
+	}
+	return "just now"
+}
@@ -0,0 +1,117 @@
+package ticket
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestRenderFormat(t *testing.T) {
+	created := time.Date(2024, 5, 24, 12, 0, 0, 0, time.Local).Unix()
+	tickets := []Ticket{
+		{ID: 1, Title: "My first ticket", Labels: []string{"bug", "ux"}, Priority: 1, Status: "open", Created: created},
+		{ID: 2, Title: "Second", Priority: 7, Created: created},
+	}
+
+	testCases := []struct {
+		format      string
+		expected    string
+		expectedErr bool
+	}{
+		{format: `{{.ID}}\t{{.Title}}\t{{join .Labels ","}}`, expected: "1\tMy first ticket\tbug,ux\n2\tSecond\t\n"},
+		{format: `{{.Priority}} {{default .Status "-"}}`, expected: "1 open\n7 -\n"},
+		{format: `{{truncate .Title 5}}|{{pad .Status 5}}|`, expected: "My f…|open |\nSeco…|     |\n"},
+		{format: `{{pad .Title 4}}|`, expected: "My f|\nSeco|\n"},
+		{format: `{{date .Created}} {{timeFormat .Created "Jan 2"}}\n`, expected: "2024-05-24 May 24\n2024-05-24 May 24\n"},
+		{format: `{{upper .Title}} {{json .Labels}}`, expected: "MY FIRST TICKET [\"bug\",\"ux\"]\nSECOND null\n"},
+		{format: `{{"a\\tb"}}`, expected: "a\\tb\na\\tb\n"},
+		{format: `{{.NoSuchField}}`, expectedErr: true},
+		{format: `{{.ID`, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		tmpl, err := ParseFormat(tc.format)
+		if err == nil {
+			err = RenderFormat(&b, tickets, tmpl)
+		}
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("format %q returned error %v, expected an error: %t", tc.format, err, tc.expectedErr)
+			continue
+		}
+		if !tc.expectedErr && b.String() != tc.expected {
+			t.Errorf("format %q rendered %q, want %q", tc.format, b.String(), tc.expected)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	testCases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: 30 * time.Second, expected: "just now"},
+		{duration: time.Minute, expected: "1 minute ago"},
+		{duration: 5 * time.Hour, expected: "5 hours ago"},
+		{duration: 400 * 24 * time.Hour, expected: "1 year ago"},
+	}
+
+	for _, tc := range testCases {
+		if actual := ago(tc.duration); actual != tc.expected {
+			t.Errorf("ago(%s) = %q, want %q", tc.duration, actual, tc.expected)
+		}
+	}
+}
+
+func TestHandleFormatSave(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = HandleFormatSave("short", `{{.ID}}\t{{.Title}}`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	err = HandleFormatList(&b, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "short: {{.ID}}\\t{{.Title}}\n" {
+		t.Errorf("Unexpected list of formats: %q", b.String())
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ListTickets(thisRepo, common.BranchName, 0, "", false, ListOptions{Format: "@short"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "1\tMy first ticket\n" {
+		t.Errorf("Unexpected output listing with a stored format: %q", output)
+	}
+
+	err = HandleFormatDelete("short", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ResolveFormat(thisRepo, common.BranchName, "@short", true)
+	if err == nil {
+		t.Errorf("Expected an error resolving a deleted format")
+	}
+	err = HandleFormatSave("../escape", `{{.ID}}`, true)
+	if err == nil {
+		t.Errorf("Expected an error saving a format with an invalid name")
+	}
+}
@@ -0,0 +1,160 @@
+package ticket
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"gopkg.in/yaml.v2"
+)
+
+// HistoryEntry is a commit to the giticket branch that changed a ticket
+type HistoryEntry struct {
+	Commit  string
+	Author  string
+	When    int64
+	Message string
+	// Status is the ticket's status after the commit, or "" if the commit
+	// deleted it
+	Status  string
+	Deleted bool
+}
+
+// TicketHistory takes a pointer to a git repository, a branch name, and a
+// debug flag, and returns the commits to branchName that created, changed, or
+// deleted each ticket, oldest first, keyed by ticket ID. Only the first parent
+// of each commit is followed. Returns an error if there is one.
+func TicketHistory(thisRepo *git.Repository, branchName string, debugFlag bool) (map[int][]HistoryEntry, error) {
+	debug.DebugMessage(debugFlag, "Reading the history of tickets on branch "+branchName)
+	walk, err := thisRepo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+	walk.SimplifyFirstParent()
+	walk.Sorting(git.SortTopological | git.SortReverse)
+	err = walk.PushRef("refs/heads/" + branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[int][]HistoryEntry)
+	// previous holds the ticket files of the commit before the one being
+	// looked at, by file name
+	previous := make(map[string]git.Oid)
+	var walkErr error
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		current, err := ticketFileOids(thisRepo, commit)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+
+		currentByID := make(map[int]git.Oid)
+		for name, oid := range current {
+			currentByID[ticketIDFromFilename(name)] = oid
+		}
+
+		changed := make(map[int]bool)
+		for name, oid := range current {
+			if previousOid, ok := previous[name]; !ok || !previousOid.Equal(&oid) {
+				changed[ticketIDFromFilename(name)] = true
+			}
+		}
+		for name := range previous {
+			if _, ok := current[name]; !ok {
+				changed[ticketIDFromFilename(name)] = true
+			}
+		}
+		for id := range changed {
+			if id == 0 {
+				continue
+			}
+			entry := HistoryEntry{
+				Commit:  commit.Id().String(),
+				Author:  commit.Author().Name + " <" + commit.Author().Email + ">",
+				When:    commit.Author().When.Unix(),
+				Message: strings.TrimSpace(commit.Message()),
+				Deleted: true,
+			}
+			if oid, ok := currentByID[id]; ok {
+				entry.Deleted = false
+				entry.Status, err = ticketStatusFromBlob(thisRepo, &oid)
+				if err != nil {
+					walkErr = err
+					return false
+				}
+			}
+			history[id] = append(history[id], entry)
+		}
+		previous = current
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ticketFileOids returns the blob ID of each file in the commit's
+// .giticket/tickets directory, by file name. A commit without the directory
+// has no tickets.
+func ticketFileOids(thisRepo *git.Repository, commit *git.Commit) (map[string]git.Oid, error) {
+	oids := make(map[string]git.Oid)
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	entry, err := tree.EntryByPath(path.Join(".giticket", "tickets"))
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return oids, nil
+		}
+		return nil, err
+	}
+	ticketsTree, err := thisRepo.LookupTree(entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer ticketsTree.Free()
+
+	for i := uint64(0); i < ticketsTree.EntryCount(); i++ {
+		entry := ticketsTree.EntryByIndex(i)
+		oids[entry.Name] = *entry.Id
+	}
+	return oids, nil
+}
+
+// ticketStatusFromBlob returns the status of the ticket in a blob
+func ticketStatusFromBlob(thisRepo *git.Repository, oid *git.Oid) (string, error) {
+	blob, err := thisRepo.LookupBlob(oid)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Free()
+	var t Ticket
+	err = yaml.Unmarshal(blob.Contents(), &t)
+	if err != nil {
+		return "", err
+	}
+	return t.Status, nil
+}
+
+// ticketIDFromFilename returns the ticket ID from the name of a ticket's file,
+// eg: 12 from "12__Fix_the_login_page", or 0 if the name doesn't start with
+// one
+func ticketIDFromFilename(name string) int {
+	idPart, _, _ := strings.Cut(name, "__")
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id < 1 {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,395 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// HookNames are the git hooks installed by HandleHooksInstall
+var HookNames = []string{"commit-msg", "prepare-commit-msg", "post-commit"}
+
+// HookActions are the actions accepted by the hooks subcommand
+var HookActions = []string{"install", "uninstall", "run"}
+
+// hookMarker is the line that identifies hooks installed by giticket
+const hookMarker = "# Installed by giticket, remove with: giticket hooks uninstall"
+
+// chainedHookSuffix is added to the name of a hook that existed before
+// giticket's hook was installed. The giticket hook runs it first.
+const chainedHookSuffix = ".giticket-chained"
+
+// branchTicketIDPattern matches the ticket ID in a branch name such as
+// feature/12-login, 12-login or bug/12
+var branchTicketIDPattern = regexp.MustCompile(`(?:^|/)(\d+)(?:[-_.]|$)`)
+
+// HandleHooksInstall takes a writer and a debug flag, and installs the
+// giticket hooks in the repository's hooks directory. A hook that already
+// exists is renamed so the giticket hook can run it first, see
+// chainedHookSuffix. Installing again is harmless.
+func HandleHooksInstall(w io.Writer, debugFlag bool) error {
+	hooksDir, err := hooksDirectory(debugFlag)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(hooksDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range HookNames {
+		hookPath := filepath.Join(hooksDir, name)
+		existing, err := os.ReadFile(hookPath)
+		switch {
+		case err == nil && strings.Contains(string(existing), hookMarker):
+			debug.DebugMessage(debugFlag, "Replacing giticket hook "+hookPath)
+		case err == nil:
+			if _, err := os.Stat(hookPath + chainedHookSuffix); err == nil {
+				return errors.New("can't install " + name + " because both " + hookPath + " and " + hookPath + chainedHookSuffix + " exist")
+			}
+			err = os.Rename(hookPath, hookPath+chainedHookSuffix)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "Moved the existing %s hook to %s, it will run before giticket's\n", name, hookPath+chainedHookSuffix)
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+
+		err = os.WriteFile(hookPath, []byte(hookScript(name)), 0755)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Installed %s\n", hookPath)
+	}
+	return nil
+}
+
+// HandleHooksUninstall takes a writer and a debug flag, and removes the
+// giticket hooks from the repository's hooks directory, putting back any hooks
+// they were chained to. Hooks not installed by giticket are left alone.
+func HandleHooksUninstall(w io.Writer, debugFlag bool) error {
+	hooksDir, err := hooksDirectory(debugFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range HookNames {
+		hookPath := filepath.Join(hooksDir, name)
+		existing, err := os.ReadFile(hookPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(existing), hookMarker) {
+			debug.DebugMessage(debugFlag, "Leaving "+hookPath+" alone, it wasn't installed by giticket")
+			continue
+		}
+
+		err = os.Remove(hookPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Removed %s\n", hookPath)
+
+		if _, err := os.Stat(hookPath + chainedHookSuffix); err == nil {
+			err = os.Rename(hookPath+chainedHookSuffix, hookPath)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "Restored the previous %s hook\n", name)
+		}
+	}
+	return nil
+}
+
+// HandleHookCommitMsg implements the commit-msg hook. It takes a branch name,
+// the path of the file holding the commit message, and a debug flag, and
+// returns an error if the message refers to a ticket that doesn't exist.
+func HandleHookCommitMsg(branchName string, messageFile string, debugFlag bool) error {
+	message, err := os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+	refs := ParseCommitReferences(stripCommitComments(string(message)))
+	if len(refs) == 0 {
+		return nil
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, ref := range refs {
+		if FilterTicketsByID(tickets, ref.TicketID).ID == 0 {
+			missing = append(missing, "#"+strconv.Itoa(ref.TicketID))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("the commit message refers to tickets that don't exist: " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// HandleHookPrepareCommitMsg implements the prepare-commit-msg hook. It takes
+// the path of the file holding the commit message, the source of the message
+// as given to the hook by git, and a debug flag. If the current branch is
+// named after a ticket, such as feature/12-login, and the message doesn't
+// already refer to that ticket, a "Ticket: 12" trailer is added to the
+// message. Merges, squashes and amended commits are left alone.
+func HandleHookPrepareCommitMsg(messageFile string, source string, debugFlag bool) error {
+	if source == "merge" || source == "squash" || source == "commit" {
+		return nil
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	head, err := thisRepo.Head()
+	if err != nil {
+		// There's no branch yet, eg: the first commit
+		return nil
+	}
+	defer head.Free()
+
+	ticketID := TicketIDFromBranch(head.Shorthand())
+	if ticketID == 0 {
+		return nil
+	}
+	debug.DebugMessage(debugFlag, "Branch "+head.Shorthand()+" is for ticket "+strconv.Itoa(ticketID))
+
+	message, err := os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+	prefilled := PrefillTicketID(string(message), ticketID)
+	if prefilled == string(message) {
+		return nil
+	}
+	return os.WriteFile(messageFile, []byte(prefilled), 0644)
+}
+
+// HandleHookPostCommit implements the post-commit hook. It takes a writer, a
+// branch name, and a debug flag, and comments on each existing ticket the new
+// commit refers to, recording the commit on the ticket as scan-commits would.
+// All the tickets are changed in a single commit.
+func HandleHookPostCommit(w io.Writer, branchName string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	head, err := thisRepo.Head()
+	if err != nil {
+		return err
+	}
+	defer head.Free()
+	commit, err := thisRepo.LookupCommit(head.Target())
+	if err != nil {
+		return err
+	}
+	defer commit.Free()
+
+	refs := ParseCommitReferences(commit.Message())
+	if len(refs) == 0 {
+		return nil
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	link := CommitLink{
+		OID:     commit.Id().String(),
+		Summary: commit.Summary(),
+		Author:  commit.Author().Name + " <" + commit.Author().Email + ">",
+		Created: commit.Author().When.Unix(),
+		Branch:  head.Shorthand(),
+	}
+	var toCommit []common.TicketInterface
+	var ids []string
+	for _, ref := range refs {
+		t := FilterTicketsByID(tickets, ref.TicketID)
+		if t.ID == 0 {
+			continue
+		}
+		link.Closes = ref.Closes
+		if !AddCommitLink(&t, link) {
+			continue
+		}
+		_, err = AddComment(&t, "Referenced by commit "+shortOID(link.OID)+" on "+link.Branch+": "+link.Summary, thisRepo, branchName, debugFlag)
+		if err != nil {
+			return err
+		}
+		toCommit = append(toCommit, &t)
+		ids = append(ids, strconv.Itoa(t.ID))
+	}
+	if len(toCommit) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "giticket: commented on tickets %s\n", strings.Join(ids, ", "))
+	return repo.CommitTickets(thisRepo, branchName, toCommit, "Commenting on tickets "+strings.Join(ids, ", ")+" for commit "+shortOID(link.OID), debugFlag)
+}
+
+// TicketIDFromBranch takes a branch name and returns the ID of the ticket it
+// was named after, such as 12 for feature/12-login, or 0 if there is none
+func TicketIDFromBranch(branch string) int {
+	match := branchTicketIDPattern.FindStringSubmatch(branch)
+	if match == nil {
+		return 0
+	}
+	id, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// PrefillTicketID takes a commit message being prepared and a ticket ID, and
+// returns the message with a "Ticket: N" trailer added before git's comment
+// lines, unless the message already refers to the ticket
+func PrefillTicketID(message string, ticketID int) string {
+	for _, ref := range ParseCommitReferences(stripCommitComments(message)) {
+		if ref.TicketID == ticketID {
+			return message
+		}
+	}
+
+	lines := strings.SplitAfter(message, "\n")
+	// Find where git's trailing comment lines start
+	end := len(lines)
+	for end > 0 && (strings.HasPrefix(lines[end-1], "#") || strings.TrimSpace(lines[end-1]) == "") {
+		end--
+	}
+	body := strings.TrimRight(strings.Join(lines[:end], ""), "\n")
+	comments := strings.TrimLeft(strings.Join(lines[end:], ""), "\n")
+
+	trailer := "Ticket: " + strconv.Itoa(ticketID) + "\n"
+	if comments != "" {
+		trailer += "\n"
+	}
+	// Leave the first line free for the summary
+	if body == "" {
+		return "\n\n" + trailer + comments
+	}
+	if !isTrailerParagraph(body) {
+		trailer = "\n" + trailer
+	}
+	return body + "\n" + trailer + comments
+}
+
+// isTrailerParagraph returns true if the last paragraph of a commit message
+// is made of git trailers, so a new trailer can be added to it
+func isTrailerParagraph(message string) bool {
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return false
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if !commitTrailerPattern.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripCommitComments removes the lines git treats as comments from a commit
+// message
+func stripCommitComments(message string) string {
+	lines := strings.Split(message, "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool { return strings.HasPrefix(line, "#") })
+	return strings.Join(lines, "\n")
+}
+
+// hooksDirectory returns the path of the hooks directory of the repository in
+// the current directory, which is set by core.hooksPath if it is configured
+func hooksDirectory(debugFlag bool) (string, error) {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return "", err
+	}
+	defer thisRepo.Free()
+
+	config, err := thisRepo.Config()
+	if err != nil {
+		return "", err
+	}
+	defer config.Free()
+	hooksPath, err := config.LookupString("core.hooksPath")
+	if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		hooksPath = ""
+	} else if err != nil {
+		return "", err
+	}
+	if hooksPath != "" {
+		debug.DebugMessage(debugFlag, "Using core.hooksPath "+hooksPath)
+	}
+	return resolveHooksPath(thisRepo.Path(), thisRepo.Workdir(), hooksPath)
+}
+
+// resolveHooksPath takes the path of a repository's git directory, its working
+// directory, and its core.hooksPath setting, and returns the path of its hooks
+// directory. Like git, a relative core.hooksPath is relative to the working
+// directory, or to the git directory of a bare repository, and a leading "~/"
+// is the user's home directory.
+func resolveHooksPath(gitDir string, workdir string, hooksPath string) (string, error) {
+	if hooksPath == "" {
+		return filepath.Join(gitDir, "hooks"), nil
+	}
+	if hooksPath == "~" || strings.HasPrefix(hooksPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to find the core.hooksPath %s: %s", hooksPath, err)
+		}
+		return filepath.Join(home, strings.TrimPrefix(hooksPath, "~")), nil
+	}
+	if filepath.IsAbs(hooksPath) {
+		return filepath.Clean(hooksPath), nil
+	}
+	if workdir == "" {
+		return filepath.Join(gitDir, hooksPath), nil
+	}
+	return filepath.Join(workdir, hooksPath), nil
+}
+
+// hookScript returns the shell script installed for the named hook. The
+// script runs any hook it was chained to first, then giticket.
+func hookScript(name string) string {
+	run := `exec giticket hooks run ` + name + ` "$@"`
+	if name == "post-commit" {
+		// A failure after the commit was made shouldn't look like the commit
+		// failed
+		run = `giticket hooks run ` + name + ` "$@" || echo "giticket: unable to comment on tickets" >&2`
+	}
+	return `#!/bin/sh
+` + hookMarker + `
+chained="$(dirname "$0")/` + name + chainedHookSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+` + run + `
+`
+}
@@ -0,0 +1,166 @@
+package ticket
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestTicketIDFromBranch(t *testing.T) {
+	testCases := []struct {
+		branch   string
+		expected int
+	}{
+		{branch: "feature/12-login", expected: 12},
+		{branch: "12-login", expected: 12},
+		{branch: "bug/7", expected: 7},
+		{branch: "user/jeff/42_cleanup", expected: 42},
+		{branch: "main", expected: 0},
+		{branch: "release-1.2", expected: 0},
+		{branch: "feature/login2", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		if id := TicketIDFromBranch(tc.branch); id != tc.expected {
+			t.Errorf("TicketIDFromBranch(%q) = %d, want %d", tc.branch, id, tc.expected)
+		}
+	}
+}
+
+func TestPrefillTicketID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "new commit",
+			message:  "\n# Please enter the commit message for your changes.\n#\n",
+			expected: "\n\nTicket: 12\n\n# Please enter the commit message for your changes.\n#\n",
+		},
+		{
+			name:     "message given with -m",
+			message:  "Fix the login page\n",
+			expected: "Fix the login page\n\nTicket: 12\n",
+		},
+		{
+			name:     "existing trailers",
+			message:  "Fix the login page\n\nSigned-off-by: John Smith <jsmith@example.com>\n",
+			expected: "Fix the login page\n\nSigned-off-by: John Smith <jsmith@example.com>\nTicket: 12\n",
+		},
+		{
+			name:     "already refers to the ticket",
+			message:  "Fix the login page, fixes #12\n",
+			expected: "Fix the login page, fixes #12\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := PrefillTicketID(tc.message, 12); got != tc.expected {
+			t.Errorf("%s: PrefillTicketID returned %q, want %q", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestResolveHooksPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	testCases := []struct {
+		workdir   string
+		hooksPath string
+		expected  string
+	}{
+		{workdir: "/src/project/", hooksPath: "", expected: "/src/project/.git/hooks"},
+		{workdir: "/src/project/", hooksPath: ".githooks", expected: "/src/project/.githooks"},
+		{workdir: "/src/project/", hooksPath: "/etc/githooks/", expected: "/etc/githooks"},
+		{workdir: "/src/project/", hooksPath: "~/hooks", expected: filepath.Join(home, "hooks")},
+		{workdir: "", hooksPath: "hooks-shared", expected: "/src/project/.git/hooks-shared"},
+	}
+
+	for _, tc := range testCases {
+		got, err := resolveHooksPath("/src/project/.git/", tc.workdir, tc.hooksPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("resolveHooksPath(%q, %q) = %q, want %q", tc.workdir, tc.hooksPath, got, tc.expected)
+		}
+	}
+}
+
+func TestHandleHooksInstall(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An existing hook is chained to
+	existing := "#!/bin/sh\necho existing\n"
+	err = os.MkdirAll(filepath.Join(".git", "hooks"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(".git", "hooks", "commit-msg"), []byte(existing), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Installing twice is harmless
+	for i := 0; i < 2; i++ {
+		err = HandleHooksInstall(&bytes.Buffer{}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range HookNames {
+		contents, err := os.ReadFile(filepath.Join(".git", "hooks", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(contents), "giticket hooks run "+name) {
+			t.Errorf("Unexpected %s hook:\n%s", name, contents)
+		}
+	}
+	chained, err := os.ReadFile(filepath.Join(".git", "hooks", "commit-msg"+chainedHookSuffix))
+	if err != nil || string(chained) != existing {
+		t.Errorf("Expected the existing hook to be kept, got %q, %v", chained, err)
+	}
+
+	// Refer to a ticket that doesn't exist
+	err = os.WriteFile("COMMIT_EDITMSG", []byte("Fix it, fixes #1 and #99\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleHookCommitMsg(common.BranchName, "COMMIT_EDITMSG", true)
+	if err == nil || !strings.Contains(err.Error(), "#99") {
+		t.Errorf("Expected an error about ticket 99, got %v", err)
+	}
+	err = os.WriteFile("COMMIT_EDITMSG", []byte("Fix it, fixes #1\n# refs #99 in a comment\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleHookCommitMsg(common.BranchName, "COMMIT_EDITMSG", true)
+	if err != nil {
+		t.Errorf("Expected no error for a message referring to ticket 1, got %v", err)
+	}
+
+	err = HandleHooksUninstall(&bytes.Buffer{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := os.ReadFile(filepath.Join(".git", "hooks", "commit-msg"))
+	if err != nil || string(restored) != existing {
+		t.Errorf("Expected the existing hook to be restored, got %q, %v", restored, err)
+	}
+	if _, err := os.Stat(filepath.Join(".git", "hooks", "post-commit")); err == nil {
+		t.Errorf("Expected the post-commit hook to be removed")
+	}
+}
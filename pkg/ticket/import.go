@@ -0,0 +1,378 @@
+package ticket
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+	"gopkg.in/yaml.v2"
+)
+
+// importSource reads tickets from another tracker, either from a file it
+// exported with parse, or with read from where it keeps them in the
+// repository, which also returns what it couldn't import. Both return the
+// tickets with ExternalID set, Priority and Severity left at 0 if the tracker
+// doesn't have them, and comments in the order they were made. If
+// binaryState is true the tracker only knows whether a ticket is open or
+// closed, so a ticket's status is only changed on re-import when that
+// changes.
+type importSource struct {
+	parse       func(contents []byte) ([]Ticket, error)
+	read        func(thisRepo *git.Repository, from string, debugFlag bool) ([]Ticket, []string, error)
+	binaryState bool
+}
+
+// importSources are the trackers tickets can be imported from, by name
+var importSources = map[string]importSource{
+	"github": {parse: ParseGitHubIssues, binaryState: true},
+	"ticgit": {read: ReadTicgitTickets},
+}
+
+// ImportSources returns the names of the trackers tickets can be imported
+// from, in alphabetical order
+func ImportSources() []string {
+	var names []string
+	for name := range importSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ImportFromRepository returns true if the tracker keeps its tickets in the
+// repository, rather than exporting them to a file
+func ImportFromRepository(source string) bool {
+	return importSources[source].read != nil
+}
+
+// HandleImport takes a writer, a branch name, the name of the tracker to
+// import from (see ImportSources), the file it exported or, if it keeps its
+// tickets in the repository, the branch they're in or "" to look for it, and
+// dry run and debug flags. It creates a ticket for each new ticket and
+// updates the tickets imported before, all in a single commit, see
+// ImportTickets. Anything that couldn't be imported is reported. Returns an
+// error if there is one.
+func HandleImport(w io.Writer, branchName string, source string, from string, dryRun bool, debugFlag bool) error {
+	importer, ok := importSources[source]
+	if !ok {
+		return errors.New("unknown source '" + source + "', valid sources are: " + strings.Join(ImportSources(), ", "))
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	var imported []Ticket
+	if importer.read != nil {
+		var skipped []string
+		imported, skipped, err = importer.read(thisRepo, from, debugFlag)
+		if err != nil {
+			return err
+		}
+		for _, s := range skipped {
+			fmt.Fprintln(w, "Could not import "+s)
+		}
+	} else {
+		debug.DebugMessage(debugFlag, "Reading "+from)
+		contents, err := os.ReadFile(from)
+		if err != nil {
+			return err
+		}
+		imported, err = importer.parse(contents)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", from, err)
+		}
+	}
+	return ImportTickets(w, thisRepo, branchName, imported, source, importer.binaryState, dryRun, debugFlag)
+}
+
+// ImportTickets takes a writer, a pointer to a git repository, a branch name,
+// the tickets read from another tracker, the name of that tracker, whether it
+// only knows if tickets are open or closed, a dry run flag, and a debug flag.
+// Each ticket whose ExternalID matches a ticket imported before updates that
+// ticket (see mergeImported), and the rest become new tickets, see
+// CommitImport. Returns an error if there is one.
+func ImportTickets(w io.Writer, thisRepo *git.Repository, branchName string, imported []Ticket, source string, binaryState bool, dryRun bool, debugFlag bool) error {
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	byExternalID := make(map[string]Ticket)
+	for _, t := range tickets {
+		if t.ExternalID != "" {
+			byExternalID[t.ExternalID] = t
+		}
+	}
+
+	var changed []Ticket
+	for _, in := range imported {
+		if in.ExternalID == "" {
+			return errors.New("every imported ticket needs an external ID")
+		}
+		existing, ok := byExternalID[in.ExternalID]
+		if !ok {
+			in.ID = 0
+			changed = append(changed, in)
+			continue
+		}
+		changed = append(changed, mergeImported(existing, in, binaryState))
+	}
+	return CommitImport(w, thisRepo, branchName, tickets, changed, source, dryRun, debugFlag)
+}
+
+// CommitImport takes a writer, a pointer to a git repository, a branch name,
+// every ticket on the branch, the imported tickets, the name of where they
+// were imported from, a dry run flag, and a debug flag. Imported tickets with
+// an ID replace that ticket, and those without become new tickets. Milestones
+// the tickets are planned for that don't exist yet are created. Every
+// change is made in a single commit, and nothing is committed if nothing
+// changed, so importing the same tickets again does nothing. A dry run
+// writes what would change without committing it. Returns an error if there
+// is one.
+func CommitImport(w io.Writer, thisRepo *git.Repository, branchName string, tickets []Ticket, imported []Ticket, source string, dryRun bool, debugFlag bool) error {
+	byID := make(map[int]Ticket)
+	for _, t := range tickets {
+		byID[t.ID] = t
+	}
+
+	parentCommit, err := repo.GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	defer parentCommit.Free()
+	nextID, err := ReadNextTicketID(thisRepo, parentCommit)
+	if err != nil {
+		return err
+	}
+
+	milestones, err := missingMilestones(thisRepo, branchName, imported, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	createVerb, updateVerb := "Created", "Updated"
+	if dryRun {
+		createVerb, updateVerb = "Would create", "Would update"
+	}
+	files := make(map[string][]byte)
+	for _, m := range milestones {
+		contents, err := yaml.Marshal(m)
+		if err != nil {
+			return err
+		}
+		files[path.Join(milestonesDir, m.Name)] = contents
+		fmt.Fprintf(w, "%s milestone %s\n", createVerb, m.Name)
+	}
+	created, updated, unchanged := 0, 0, 0
+	for _, t := range imported {
+		from := ""
+		if t.ExternalID != "" {
+			from = " from " + t.ExternalID
+		}
+
+		existing, ok := byID[t.ID]
+		if t.ID == 0 {
+			t = newImportedTicket(t, nextID)
+			nextID++
+			files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+			created++
+			fmt.Fprintf(w, "%s ticket %d%s: %s\n", createVerb, t.ID, from, t.Title)
+			if dryRun {
+				writeDiff(w, nil, t.TicketToYaml())
+			}
+			byID[t.ID] = t
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("there is no ticket %d to update", t.ID)
+		}
+
+		if bytes.Equal(t.TicketToYaml(), existing.TicketToYaml()) {
+			unchanged++
+			continue
+		}
+		if t.TicketFilename() != existing.TicketFilename() {
+			files[path.Join("tickets", existing.TicketFilename())] = nil
+		}
+		files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+		updated++
+		fmt.Fprintf(w, "%s ticket %d%s: %s\n", updateVerb, t.ID, from, t.Title)
+		if dryRun {
+			writeDiff(w, existing.TicketToYaml(), t.TicketToYaml())
+		}
+		byID[t.ID] = t
+	}
+
+	if created == 0 && updated == 0 && len(milestones) == 0 {
+		fmt.Fprintf(w, "Nothing to import, all %d tickets are up to date\n", unchanged)
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(w, "Would import %d new and %d updated tickets from %s, %d unchanged\n", created, updated, source, unchanged)
+		return nil
+	}
+	if created > 0 {
+		files["next_ticket_id"] = []byte(strconv.Itoa(nextID))
+	}
+	err = repo.CommitFiles(thisRepo, branchName, files, fmt.Sprintf("Importing tickets from %s: %d created, %d updated", source, created, updated), debugFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Imported %d new and %d updated tickets from %s, %d unchanged\n", created, updated, source, unchanged)
+	return nil
+}
+
+// missingMilestones takes a pointer to a git repository, a branch name, the
+// imported tickets, and a debug flag, and returns the milestones the tickets
+// are planned for that don't exist yet, as new open milestones. Returns an
+// error if a ticket's milestone isn't a valid milestone name.
+func missingMilestones(thisRepo *git.Repository, branchName string, imported []Ticket, debugFlag bool) ([]Milestone, error) {
+	existing, err := ReadMilestones(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for _, m := range existing {
+		known[m.Name] = true
+	}
+
+	var missing []Milestone
+	for _, t := range imported {
+		if t.Milestone == "" || known[t.Milestone] {
+			continue
+		}
+		if !formatNamePattern.MatchString(t.Milestone) {
+			return nil, errors.New("ticket '" + t.Title + "' has an invalid milestone name '" + t.Milestone + "'")
+		}
+		known[t.Milestone] = true
+		missing = append(missing, Milestone{Name: t.Milestone, State: MilestoneOpen, Created: time.Now().Unix()})
+	}
+	return missing, nil
+}
+
+// writeDiff writes the lines that differ between two versions of a ticket's
+// YAML, prefixed with "-" if they were removed and "+" if they were added
+func writeDiff(w io.Writer, before []byte, after []byte) {
+	a := strings.Split(strings.TrimRight(string(before), "\n"), "\n")
+	b := strings.Split(strings.TrimRight(string(after), "\n"), "\n")
+	if len(before) == 0 {
+		a = nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintln(w, "    - "+a[i])
+			i++
+		default:
+			fmt.Fprintln(w, "    + "+b[j])
+			j++
+		}
+	}
+}
+
+// newImportedTicket returns an imported ticket as a new ticket with the given
+// ID, with its comments numbered from 1
+func newImportedTicket(in Ticket, id int) Ticket {
+	t := in
+	t.ID = id
+	if t.Priority == 0 {
+		t.Priority = 1
+	}
+	if t.Severity == 0 {
+		t.Severity = 1
+	}
+	if t.Status == "" {
+		t.Status = "new"
+	}
+	t.Comments = nil
+	t.NextCommentID = 1
+	for _, comment := range in.Comments {
+		comment.ID = t.NextCommentID
+		t.NextCommentID++
+		t.Comments = append(t.Comments, comment)
+	}
+	return t
+}
+
+// mergeImported returns a ticket imported before, updated with the ticket
+// read from the tracker again. The title, description, labels, assignees, and
+// milestone are replaced, and the priority and severity if the tracker has
+// them. The status is replaced too, unless binaryState is true and the
+// ticket is still open, or still closed, in the tracker, so a status set in
+// giticket such as "in progress" is kept. Comments are matched by author and
+// time: changed ones are updated, new ones added, and comments made in
+// giticket are kept.
+func mergeImported(existing Ticket, in Ticket, binaryState bool) Ticket {
+	t := existing
+	t.Title = in.Title
+	t.Description = in.Description
+	t.Labels = in.Labels
+	t.Assignees = in.Assignees
+	t.Milestone = in.Milestone
+	if in.Priority != 0 {
+		t.Priority = in.Priority
+	}
+	if in.Severity != 0 {
+		t.Severity = in.Severity
+	}
+	if in.Status != "" && (!binaryState || IsClosed(in) != IsClosed(existing)) {
+		t.Status = in.Status
+	}
+	if in.Created != 0 {
+		t.Created = in.Created
+	}
+
+	t.Comments = append([]Comment{}, existing.Comments...)
+	for _, comment := range in.Comments {
+		found := false
+		for i, c := range t.Comments {
+			if c.Author == comment.Author && c.Created == comment.Created {
+				t.Comments[i].Body = comment.Body
+				found = true
+				break
+			}
+		}
+		if !found {
+			comment.ID = t.NextCommentID
+			t.NextCommentID++
+			t.Comments = append(t.Comments, comment)
+		}
+	}
+	if len(t.Comments) == 0 {
+		t.Comments = existing.Comments
+	}
+	return t
+}
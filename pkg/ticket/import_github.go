@@ -0,0 +1,181 @@
+package ticket
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// githubUser is the author of a GitHub issue or comment. The REST API calls
+// it "user" and the gh CLI calls it "author", both have a login.
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubComment is a comment on a GitHub issue, in the format of the REST API
+// or of gh issue list --json comments
+type githubComment struct {
+	Body         string      `json:"body"`
+	User         *githubUser `json:"user"`
+	Author       *githubUser `json:"author"`
+	CreatedAt    string      `json:"created_at"`
+	CreatedAtCLI string      `json:"createdAt"`
+}
+
+// githubIssue is a GitHub issue, in the format of the REST API or of
+// gh issue list --json. Comments is a list of comments from gh, or only a
+// count from the REST API, which is ignored.
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	User      *githubUser  `json:"user"`
+	Author    *githubUser  `json:"author"`
+	Assignees []githubUser `json:"assignees"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	CreatedAt    string          `json:"created_at"`
+	CreatedAtCLI string          `json:"createdAt"`
+	Comments     json.RawMessage `json:"comments"`
+	PullRequest  json.RawMessage `json:"pull_request"`
+}
+
+// ParseGitHubIssues takes a JSON array of GitHub issues, as returned by the
+// REST API or by gh issue list --json with the fields number, title, body,
+// state, author, labels, assignees, milestone, createdAt, and comments, and
+// returns them as tickets. Pull requests are skipped. Each ticket's
+// ExternalID is "github:" followed by the issue number, open issues have the
+// status "new" and closed issues "closed", authors and assignees are shown
+// with their GitHub noreply address, and milestone titles are made into valid
+// milestone names, see githubMilestoneName. The issue's author is recorded at the end of the
+// description, see githubDescription. Returns an error if there is one.
+func ParseGitHubIssues(contents []byte) ([]Ticket, error) {
+	var issues []githubIssue
+	err := json.Unmarshal(contents, &issues)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []Ticket
+	for _, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			continue
+		}
+		if issue.Number <= 0 {
+			return nil, errors.New("issue '" + issue.Title + "' has no number")
+		}
+		number := strconv.Itoa(issue.Number)
+
+		t := Ticket{
+			Title:       issue.Title,
+			Description: githubDescription(issue.Body, githubAuthor(issue.User, issue.Author)),
+			Status:      "new",
+			ExternalID:  "github:" + number,
+		}
+		switch strings.ToLower(issue.State) {
+		case "closed":
+			t.Status = "closed"
+		case "open", "":
+		default:
+			return nil, errors.New("issue " + number + " has unknown state '" + issue.State + "'")
+		}
+		for _, label := range issue.Labels {
+			t.Labels = append(t.Labels, label.Name)
+		}
+		for _, assignee := range issue.Assignees {
+			if identity := githubAuthor(&assignee); identity != "" {
+				t.Assignees = append(t.Assignees, identity)
+			}
+		}
+		if issue.Milestone != nil {
+			t.Milestone = githubMilestoneName(issue.Milestone.Title)
+		}
+		t.Created, err = githubTime(issue.CreatedAt, issue.CreatedAtCLI)
+		if err != nil {
+			return nil, errors.New("issue " + number + ": " + err.Error())
+		}
+
+		// The REST API only gives the number of comments, gh gives the
+		// comments themselves
+		var comments []githubComment
+		if len(issue.Comments) > 0 && issue.Comments[0] == '[' {
+			err = json.Unmarshal(issue.Comments, &comments)
+			if err != nil {
+				return nil, errors.New("issue " + number + ": " + err.Error())
+			}
+		}
+		for _, c := range comments {
+			created, err := githubTime(c.CreatedAt, c.CreatedAtCLI)
+			if err != nil {
+				return nil, errors.New("comment on issue " + number + ": " + err.Error())
+			}
+			t.Comments = append(t.Comments, Comment{
+				Created: created,
+				Body:    c.Body,
+				Author:  githubAuthor(c.User, c.Author),
+			})
+		}
+		tickets = append(tickets, t)
+	}
+	return tickets, nil
+}
+
+// githubDescription returns the body of an issue followed by who reported
+// it, if anyone
+func githubDescription(body string, reporter string) string {
+	if reporter == "" {
+		return body
+	}
+	reported := "Reported on GitHub by " + reporter
+	if strings.TrimSpace(body) == "" {
+		return reported
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + reported
+}
+
+// githubMilestoneName returns the title of a GitHub milestone as a valid
+// milestone name, with each run of other characters replaced by '-', eg:
+// "v1.0 beta" becomes "v1.0-beta"
+func githubMilestoneName(title string) string {
+	name := githubMilestoneInvalid.ReplaceAllString(strings.TrimSpace(title), "-")
+	return strings.TrimLeft(name, "_.-")
+}
+
+// githubMilestoneInvalid matches the characters that can't be used in a
+// milestone name, see formatNamePattern
+var githubMilestoneInvalid = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// githubTime parses the first non-empty RFC 3339 time, returning 0 if both
+// are empty
+func githubTime(times ...string) (int64, error) {
+	for _, s := range times {
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return 0, err
+		}
+		return t.Unix(), nil
+	}
+	return 0, nil
+}
+
+// githubAuthor returns the first user given as a name and the GitHub
+// noreply email address for them, or "" if there are none
+func githubAuthor(users ...*githubUser) string {
+	for _, u := range users {
+		if u != nil && u.Login != "" {
+			return u.Login + " <" + u.Login + "@users.noreply.github.com>"
+		}
+	}
+	return ""
+}
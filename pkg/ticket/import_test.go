@@ -0,0 +1,221 @@
+package ticket
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+const githubIssuesJSON = `[
+	{"number": 12, "title": "Reverse the polarity", "body": "Of the neutron flow", "state": "open", "user": {"login": "drwho"},
+	 "labels": [{"name": "tardis"}], "assignees": [{"login": "jsmith"}], "milestone": {"title": "v1.0 beta"},
+	 "created_at": "2024-01-02T03:04:05Z", "comments": 1},
+	{"number": 13, "title": "Fix CI/CD", "body": "", "state": "CLOSED", "author": {"login": "rtyler"}, "labels": [], "assignees": [],
+	 "createdAt": "2024-01-03T00:00:00Z",
+	 "comments": [{"author": {"login": "rtyler"}, "body": "Fixed", "createdAt": "2024-01-04T00:00:00Z"}]},
+	{"number": 14, "title": "Add a feature", "state": "open", "pull_request": {"url": "https://example.com"}}
+]`
+
+func TestParseGitHubIssues(t *testing.T) {
+	got, err := ParseGitHubIssues([]byte(githubIssuesJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Ticket{
+		{Title: "Reverse the polarity", Description: "Of the neutron flow\n\nReported on GitHub by drwho <drwho@users.noreply.github.com>", Status: "new", Labels: []string{"tardis"},
+			Assignees: []string{"jsmith <jsmith@users.noreply.github.com>"}, Milestone: "v1.0-beta", Created: 1704164645, ExternalID: "github:12"},
+		{Title: "Fix CI/CD", Description: "Reported on GitHub by rtyler <rtyler@users.noreply.github.com>", Status: "closed", Created: 1704240000, ExternalID: "github:13",
+			Comments: []Comment{{Created: 1704326400, Body: "Fixed", Author: "rtyler <rtyler@users.noreply.github.com>"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGitHubIssues() = %+v, want %+v", got, want)
+	}
+
+	_, err = ParseGitHubIssues([]byte(`[{"number": 1, "state": "merged"}]`))
+	if err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+}
+
+func TestGitHubMilestoneName(t *testing.T) {
+	testCases := map[string]string{
+		"v1.0":              "v1.0",
+		"v1.0 beta":         "v1.0-beta",
+		" Sprint 3: Login ": "Sprint-3-Login",
+		"-next":             "next",
+	}
+	for title, expected := range testCases {
+		name := githubMilestoneName(title)
+		if name != expected || !formatNamePattern.MatchString(name) {
+			t.Errorf("githubMilestoneName(%q) = %q, want %q", title, name, expected)
+		}
+	}
+}
+
+func TestMergeImported(t *testing.T) {
+	existing := Ticket{ID: 3, Title: "Old", Status: "in progress", Priority: 2, Severity: 3, ExternalID: "github:12",
+		Comments:      []Comment{{ID: 1, Created: 10, Author: "a", Body: "old"}, {ID: 2, Created: 20, Author: "b", Body: "local"}},
+		NextCommentID: 3}
+	in := Ticket{Title: "New", Status: "new", ExternalID: "github:12",
+		Comments: []Comment{{Created: 10, Author: "a", Body: "edited"}, {Created: 30, Author: "c", Body: "added"}}}
+
+	got := mergeImported(existing, in, true)
+	if got.Title != "New" || got.Status != "in progress" || got.Priority != 2 || got.Severity != 3 {
+		t.Errorf("mergeImported() = %+v, want the new title, and the status, priority, and severity kept", got)
+	}
+	wantComments := []Comment{{ID: 1, Created: 10, Author: "a", Body: "edited"}, {ID: 2, Created: 20, Author: "b", Body: "local"}, {ID: 3, Created: 30, Author: "c", Body: "added"}}
+	if !reflect.DeepEqual(got.Comments, wantComments) || got.NextCommentID != 4 {
+		t.Errorf("mergeImported() comments = %+v next %d, want %+v next 4", got.Comments, got.NextCommentID, wantComments)
+	}
+	if existing.Comments[0].Body != "old" {
+		t.Error("mergeImported() changed the existing ticket's comments")
+	}
+
+	in.Status = "closed"
+	got = mergeImported(existing, in, true)
+	if got.Status != "closed" {
+		t.Errorf("mergeImported() status = %q, want closed once the issue is closed", got.Status)
+	}
+}
+
+func TestHandleImport(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile("issues.json", []byte(githubIssuesJSON), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = HandleImport(&out, common.BranchName, "github", "issues.json", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Imported 2 new and 0 updated tickets from github") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := len(tickets)
+	imported := make(map[string]Ticket)
+	for _, tk := range tickets {
+		if tk.ExternalID != "" {
+			imported[tk.ExternalID] = tk
+		}
+	}
+	if len(imported) != 2 || imported["github:13"].Title != "Fix CI-CD" || len(imported["github:13"].Comments) != 1 {
+		t.Fatalf("unexpected imported tickets %+v", imported)
+	}
+	m, err := ReadMilestone(thisRepo, common.BranchName, "v1.0-beta", true)
+	if err != nil || m.State != MilestoneOpen {
+		t.Errorf("expected the issue's milestone to be created, got %+v, %v", m, err)
+	}
+	head, err := repo.GetParentCommit(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer head.Free()
+
+	// Importing the same file again changes nothing
+	out.Reset()
+	err = HandleImport(&out, common.BranchName, "github", "issues.json", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Nothing to import") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	again, err := repo.GetParentCommit(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Free()
+	if !again.Id().Equal(head.Id()) {
+		t.Error("importing the same file again made a commit")
+	}
+
+	// Changed issues update their tickets instead of creating new ones
+	changed := strings.Replace(githubIssuesJSON, "Reverse the polarity", "Reverse the polarity again", 1)
+	err = os.WriteFile("issues.json", []byte(changed), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err = HandleImport(&out, common.BranchName, "github", "issues.json", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != count {
+		t.Errorf("expected %d tickets after re-importing, got %d", count, len(tickets))
+	}
+	for _, tk := range tickets {
+		if tk.ExternalID == "github:12" && (tk.Title != "Reverse the polarity again" || tk.ID != imported["github:12"].ID) {
+			t.Errorf("ticket %d wasn't updated: %+v", tk.ID, tk)
+		}
+	}
+
+	err = HandleImport(&out, common.BranchName, "jira", "issues.json", false, true)
+	if err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestParseTicgitTicket(t *testing.T) {
+	files := map[string]string{
+		"TICKET_ID":                             "1215549051_reverse-the-polarity_42",
+		"TICKET_TITLE":                          "Reverse the polarity\n",
+		"STATE_invalid":                         "",
+		"TAG_tardis":                            "",
+		"TAG_bug_fix":                           "",
+		"ASSIGNED_jsmith@example.com":           "",
+		"ASSIGNED_rtyler":                       "",
+		"COMMENT_1215549100_rtyler@example.com": "Second\n",
+		"COMMENT_1215549090_jsmith@example.com": "First",
+		"COMMENT_soon":                          "Unreadable",
+		"POINTS_3":                              "3",
+	}
+	got, skipped := parseTicgitTicket("1215549051_reverse-the-polarity_42", "abc123", files)
+	want := Ticket{
+		Title:      "Reverse the polarity",
+		Status:     "wontfix",
+		Labels:     []string{"bug_fix", "tardis"},
+		Assignees:  []string{"jsmith <jsmith@example.com>"},
+		Created:    1215549051,
+		ExternalID: "ticgit:abc123",
+		Comments: []Comment{
+			{Created: 1215549090, Author: "jsmith@example.com", Body: "First"},
+			{Created: 1215549100, Author: "rtyler@example.com", Body: "Second"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTicgitTicket() = %+v, want %+v", got, want)
+	}
+	wantSkipped := []string{
+		"1215549051_reverse-the-polarity_42/ASSIGNED_rtyler, the assignee isn't an email address",
+		"1215549051_reverse-the-polarity_42/COMMENT_soon, the comment's time and author are unreadable",
+		"1215549051_reverse-the-polarity_42/POINTS_3",
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("parseTicgitTicket() skipped %q, want %q", skipped, wantSkipped)
+	}
+}
@@ -0,0 +1,182 @@
+package ticket
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// ticgitBranches are the branches ticgit and ticgit-ng keep their tickets in,
+// in the order they're looked for
+var ticgitBranches = []string{"ticgit-ng", "ticgit", "origin/ticgit-ng", "origin/ticgit"}
+
+// ticgitStates maps ticgit's states to giticket statuses, other states are
+// used as they are
+var ticgitStates = map[string]string{
+	"open":     "new",
+	"resolved": "resolved",
+	"invalid":  "wontfix",
+	"hold":     "hold",
+}
+
+// ReadTicgitTickets takes a pointer to a git repository, the branch ticgit or
+// ticgit-ng keeps its tickets in or "" to look for it, and a debug flag. It
+// returns the tickets in that branch, and a description of anything in them
+// that couldn't be imported, such as ticgit-ng's points and attachments. Each
+// ticket's ExternalID is "ticgit:" followed by its ticgit ID. Returns an error
+// if there is one.
+func ReadTicgitTickets(thisRepo *git.Repository, from string, debugFlag bool) ([]Ticket, []string, error) {
+	branches := ticgitBranches
+	if from != "" {
+		branches = []string{from}
+	}
+
+	var commit *git.Commit
+	for _, branch := range branches {
+		debug.DebugMessage(debugFlag, "Looking for ticgit tickets in "+branch)
+		object, err := thisRepo.RevparseSingle(branch)
+		if err != nil {
+			continue
+		}
+		peeled, err := object.Peel(git.ObjectCommit)
+		object.Free()
+		if err != nil {
+			return nil, nil, err
+		}
+		commit, err = peeled.AsCommit()
+		peeled.Free()
+		if err != nil {
+			return nil, nil, err
+		}
+		break
+	}
+	if commit == nil {
+		return nil, nil, errors.New("unable to find the ticgit branch, tried: " + strings.Join(branches, ", "))
+	}
+	defer commit.Free()
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tree.Free()
+
+	var tickets []Ticket
+	var skipped []string
+	for i := uint64(0); i < tree.EntryCount(); i++ {
+		entry := tree.EntryByIndex(i)
+		if entry.Type != git.ObjectTree {
+			skipped = append(skipped, entry.Name+", it isn't a ticgit ticket")
+			continue
+		}
+
+		ticketTree, err := thisRepo.LookupTree(entry.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		id := entry.Name
+		files := make(map[string]string)
+		for j := uint64(0); j < ticketTree.EntryCount(); j++ {
+			file := ticketTree.EntryByIndex(j)
+			if file.Type != git.ObjectBlob {
+				skipped = append(skipped, entry.Name+"/"+file.Name)
+				continue
+			}
+			if file.Name == "TICKET_ID" {
+				// ticgit shows the ID of the TICKET_ID blob as the ticket's ID
+				id = file.Id.String()
+			}
+			blob, err := thisRepo.LookupBlob(file.Id)
+			if err != nil {
+				ticketTree.Free()
+				return nil, nil, err
+			}
+			files[file.Name] = string(blob.Contents())
+			blob.Free()
+		}
+		ticketTree.Free()
+
+		t, notMapped := parseTicgitTicket(entry.Name, id, files)
+		tickets = append(tickets, t)
+		skipped = append(skipped, notMapped...)
+	}
+	return tickets, skipped, nil
+}
+
+// parseTicgitTicket takes the name of a ticgit ticket's directory, its ticgit
+// ID, and the files in it by name, and returns it as a ticket along with the
+// files that couldn't be imported. Assignees are given the local part of
+// their email address as their name, see ticgitIdentity. ticgit keeps most of a ticket in the names
+// of empty files, eg: STATE_open, TAG_bug, ASSIGNED_jsmith@example.com, and
+// COMMENT_1215549051_jsmith@example.com holding the comment. The directory
+// name starts with the time the ticket was created.
+func parseTicgitTicket(name string, id string, files map[string]string) (Ticket, []string) {
+	t := Ticket{
+		Status:     "new",
+		ExternalID: "ticgit:" + id,
+	}
+	created, _, _ := strings.Cut(name, "_")
+	t.Created, _ = strconv.ParseInt(created, 10, 64)
+
+	var names []string
+	for fname := range files {
+		names = append(names, fname)
+	}
+	sort.Strings(names)
+
+	var skipped []string
+	for _, fname := range names {
+		kind, value, _ := strings.Cut(fname, "_")
+		switch {
+		case fname == "TICKET_ID":
+		case fname == "TICKET_TITLE", fname == "TITLE":
+			t.Title = strings.TrimSpace(files[fname])
+		case kind == "STATE" && value != "":
+			t.Status = value
+			if status, ok := ticgitStates[value]; ok {
+				t.Status = status
+			}
+		case kind == "TAG" && value != "":
+			t.Labels = append(t.Labels, value)
+		case kind == "ASSIGNED" && value != "":
+			identity, err := ticgitIdentity(value)
+			if err != nil {
+				skipped = append(skipped, name+"/"+fname+", "+err.Error())
+				continue
+			}
+			t.Assignees = append(t.Assignees, identity)
+		case kind == "COMMENT":
+			when, author, ok := strings.Cut(value, "_")
+			created, err := strconv.ParseInt(when, 10, 64)
+			if !ok || err != nil {
+				skipped = append(skipped, name+"/"+fname+", the comment's time and author are unreadable")
+				continue
+			}
+			t.Comments = append(t.Comments, Comment{Created: created, Author: author, Body: strings.TrimSpace(files[fname])})
+		default:
+			skipped = append(skipped, name+"/"+fname)
+		}
+	}
+	if t.Title == "" {
+		t.Title = name
+	}
+	sort.SliceStable(t.Comments, func(i, j int) bool { return t.Comments[i].Created < t.Comments[j].Created })
+	return t, skipped
+}
+
+// ticgitIdentity takes the email address ticgit assigned a ticket to and
+// returns it as an identity named after the local part of the address, eg:
+// jsmith@example.com becomes "jsmith <jsmith@example.com>". Returns an error
+// if it isn't an email address.
+func ticgitIdentity(email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+	identity, _, err := ParseIdentity(local + " <" + email + ">")
+	if err != nil {
+		return "", errors.New("the assignee isn't an email address")
+	}
+	return identity, nil
+}
@@ -0,0 +1,299 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// Link is a typed relationship from one ticket to another, such as "blocks
+// 9". Every link has a reverse link stored on the other ticket, see
+// LinkTypes.
+type Link struct {
+	Type string
+	ID   int
+}
+
+// LinkTypes maps each type of link to its reverse. When ticket 12 blocks
+// ticket 9, ticket 12 has the link "blocks 9" and ticket 9 has the link
+// "blocked-by 12".
+var LinkTypes = map[string]string{
+	"blocks":        "blocked-by",
+	"blocked-by":    "blocks",
+	"depends-on":    "required-by",
+	"required-by":   "depends-on",
+	"duplicates":    "duplicated-by",
+	"duplicated-by": "duplicates",
+	"parent-of":     "child-of",
+	"child-of":      "parent-of",
+}
+
+// LinkTypeNames are the names of LinkTypes, in the order they are listed in
+// help and shown on tickets
+var LinkTypeNames = []string{"blocks", "blocked-by", "depends-on", "required-by", "duplicates", "duplicated-by", "parent-of", "child-of"}
+
+// acyclicLinkTypes are the groups of link types that may not form a cycle,
+// each group is a relationship where a link of any of its types points from
+// the first ticket to the second, eg: from the blocker to the blocked ticket
+var acyclicLinkTypes = [][]string{
+	{"blocks", "required-by"},
+	{"parent-of"},
+	{"duplicates"},
+}
+
+// HandleLink takes a branch name, a ticket ID, a link type, a list of target
+// ticket IDs, an unlink flag, and a debug flag. It links the ticket to each
+// target with linkType, or removes those links if unlinkFlag is true, and
+// writes the reverse links on the targets, all in a single commit. Returns an
+// error if a ticket doesn't exist, if a link would create a cycle, or if there
+// was another error.
+func HandleLink(branchName string, ticketID int, linkType string, targetIDs []int, unlinkFlag bool, debugFlag bool) error {
+	if _, ok := LinkTypes[linkType]; !ok {
+		return fmt.Errorf("unknown link type '%s', valid types are: %s", linkType, strings.Join(LinkTypeNames, ", "))
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	index := make(map[int]int)
+	for i, t := range tickets {
+		index[t.ID] = i
+	}
+
+	from, ok := index[ticketID]
+	if !ok {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+	changed := []int{from}
+	var descriptions []string
+	for _, targetID := range targetIDs {
+		to, ok := index[targetID]
+		if !ok {
+			return fmt.Errorf("ticket %d not found", targetID)
+		}
+		if unlinkFlag {
+			RemoveLink(&tickets[from], &tickets[to], linkType)
+		} else {
+			err = AddLink(&tickets[from], &tickets[to], linkType)
+			if err != nil {
+				return err
+			}
+		}
+		changed = append(changed, to)
+		descriptions = append(descriptions, strconv.Itoa(ticketID)+" "+linkType+" "+strconv.Itoa(targetID))
+	}
+
+	if !unlinkFlag {
+		err = CheckLinkCycles(tickets)
+		if err != nil {
+			return err
+		}
+	}
+
+	var toCommit []common.TicketInterface
+	for _, i := range changed {
+		toCommit = append(toCommit, &tickets[i])
+	}
+	commitMessage := "Linking " + strings.Join(descriptions, ", ")
+	if unlinkFlag {
+		commitMessage = "Unlinking " + strings.Join(descriptions, ", ")
+	}
+	return repo.CommitTickets(thisRepo, branchName, toCommit, commitMessage, debugFlag)
+}
+
+// AddLink takes pointers to two tickets and a link type, and links from to to
+// with linkType and to to from with the reverse type. Links that already
+// exist are left alone. Returns an error if the link type is unknown or the
+// tickets are the same ticket.
+func AddLink(from *Ticket, to *Ticket, linkType string) error {
+	reverse, ok := LinkTypes[linkType]
+	if !ok {
+		return fmt.Errorf("unknown link type '%s', valid types are: %s", linkType, strings.Join(LinkTypeNames, ", "))
+	}
+	if from.ID == to.ID {
+		return errors.New("a ticket can't be linked to itself")
+	}
+	if !slices.Contains(from.Links, Link{Type: linkType, ID: to.ID}) {
+		from.Links = append(from.Links, Link{Type: linkType, ID: to.ID})
+	}
+	if !slices.Contains(to.Links, Link{Type: reverse, ID: from.ID}) {
+		to.Links = append(to.Links, Link{Type: reverse, ID: from.ID})
+	}
+	return nil
+}
+
+// RemoveLink takes pointers to two tickets and a link type, and removes the
+// link of linkType from from to to, and its reverse from to to from
+func RemoveLink(from *Ticket, to *Ticket, linkType string) {
+	reverse := LinkTypes[linkType]
+	from.Links = slices.DeleteFunc(from.Links, func(l Link) bool { return l.Type == linkType && l.ID == to.ID })
+	to.Links = slices.DeleteFunc(to.Links, func(l Link) bool { return l.Type == reverse && l.ID == from.ID })
+}
+
+// CheckLinkCycles takes a list of tickets and returns an error describing the
+// first cycle found in a relationship that can't have cycles, such as a
+// ticket that ends up blocking itself, or nil if there are none
+func CheckLinkCycles(tickets []Ticket) error {
+	for _, linkTypes := range acyclicLinkTypes {
+		edges := make(map[int][]int)
+		var ids []int
+		for _, t := range tickets {
+			ids = append(ids, t.ID)
+			for _, l := range t.Links {
+				if slices.Contains(linkTypes, l.Type) {
+					edges[t.ID] = append(edges[t.ID], l.ID)
+				}
+			}
+		}
+		slices.Sort(ids)
+
+		// Depth first search, a ticket seen again while still on the path is
+		// a cycle
+		state := make(map[int]int) // 0 unvisited, 1 on the path, 2 done
+		var path []int
+		var visit func(id int) []int
+		visit = func(id int) []int {
+			state[id] = 1
+			path = append(path, id)
+			for _, next := range edges[id] {
+				if state[next] == 1 {
+					start := slices.Index(path, next)
+					return append(slices.Clone(path[start:]), next)
+				}
+				if state[next] == 0 {
+					if cycle := visit(next); cycle != nil {
+						return cycle
+					}
+				}
+			}
+			path = path[:len(path)-1]
+			state[id] = 2
+			return nil
+		}
+		for _, id := range ids {
+			if state[id] != 0 {
+				continue
+			}
+			if cycle := visit(id); cycle != nil {
+				steps := make([]string, len(cycle))
+				for i, id := range cycle {
+					steps[i] = strconv.Itoa(id)
+				}
+				return fmt.Errorf("link would create a cycle: %s", strings.Join(steps, " "+linkTypes[0]+" "))
+			}
+		}
+	}
+	return nil
+}
+
+// Blockers takes a ticket and returns the IDs of the tickets blocking it
+func Blockers(t Ticket) []int {
+	var ids []int
+	for _, l := range t.Links {
+		if l.Type == "blocked-by" || l.Type == "depends-on" {
+			ids = append(ids, l.ID)
+		}
+	}
+	return ids
+}
+
+// IsBlocked takes a ticket and every ticket, and returns true if any of the
+// tickets blocking it is not closed. Blockers that have been deleted don't
+// count.
+func IsBlocked(t Ticket, allTickets []Ticket) bool {
+	for _, id := range Blockers(t) {
+		for _, blocker := range allTickets {
+			if blocker.ID == id && !IsClosed(blocker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterUnblockedTickets takes a list of tickets and every ticket, and returns
+// the tickets that are not blocked by an open ticket
+func FilterUnblockedTickets(tickets []Ticket, allTickets []Ticket) []Ticket {
+	unblocked := []Ticket{}
+	for _, t := range tickets {
+		if !IsBlocked(t, allTickets) {
+			unblocked = append(unblocked, t)
+		}
+	}
+	return unblocked
+}
+
+// LinkNode is a node in the tree of a ticket's relationships returned by
+// LinkTree. Ticket is the zero Ticket if the linked ticket no longer exists.
+type LinkNode struct {
+	Type     string
+	ID       int
+	Ticket   Ticket
+	Children []LinkNode
+}
+
+// LinkTree takes a ticket and every ticket, and returns the ticket's links
+// sorted by type and ID. Each linked ticket's links of the same type are
+// followed in turn, so that a whole chain of blockers or a hierarchy of child
+// tickets can be shown.
+func LinkTree(t Ticket, allTickets []Ticket) []LinkNode {
+	byID := make(map[int]Ticket)
+	for _, ticket := range allTickets {
+		byID[ticket.ID] = ticket
+	}
+
+	var children func(t Ticket, linkType string, seen map[int]bool) []LinkNode
+	children = func(t Ticket, linkType string, seen map[int]bool) []LinkNode {
+		var nodes []LinkNode
+		for _, l := range sortedLinks(t.Links) {
+			if linkType != "" && l.Type != linkType {
+				continue
+			}
+			node := LinkNode{Type: l.Type, ID: l.ID, Ticket: byID[l.ID]}
+			if !seen[l.ID] && node.Ticket.ID != 0 {
+				seen[l.ID] = true
+				node.Children = children(node.Ticket, l.Type, seen)
+				delete(seen, l.ID)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return children(t, "", map[int]bool{t.ID: true})
+}
+
+// sortedLinks returns a copy of links sorted in the order of LinkTypeNames,
+// then by ticket ID
+func sortedLinks(links []Link) []Link {
+	sorted := slices.Clone(links)
+	slices.SortFunc(sorted, func(a, b Link) int {
+		if c := slices.Index(LinkTypeNames, a.Type) - slices.Index(LinkTypeNames, b.Type); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	return sorted
+}
+
+// describe returns a one line description of the linked ticket, eg: "blocks
+// 9: Fix the build (open)"
+func (node LinkNode) describe() string {
+	if node.Ticket.ID == 0 {
+		return fmt.Sprintf("%s %d (deleted)", node.Type, node.ID)
+	}
+	return fmt.Sprintf("%s %d: %s (%s)", node.Type, node.ID, node.Ticket.Title, node.Ticket.Status)
+}
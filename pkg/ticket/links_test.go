@@ -0,0 +1,158 @@
+package ticket
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestAddLink(t *testing.T) {
+	a := Ticket{ID: 1}
+	b := Ticket{ID: 2}
+
+	err := AddLink(&a, &b, "blocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Adding the same link twice has no effect
+	err = AddLink(&a, &b, "blocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Links, []Link{{Type: "blocks", ID: 2}}) || !reflect.DeepEqual(b.Links, []Link{{Type: "blocked-by", ID: 1}}) {
+		t.Errorf("Unexpected links after AddLink: %v, %v", a.Links, b.Links)
+	}
+
+	RemoveLink(&b, &a, "blocked-by")
+	if len(a.Links) != 0 || len(b.Links) != 0 {
+		t.Errorf("Unexpected links after RemoveLink: %v, %v", a.Links, b.Links)
+	}
+
+	if AddLink(&a, &a, "blocks") == nil {
+		t.Errorf("Expected an error linking a ticket to itself")
+	}
+	if AddLink(&a, &b, "relates") == nil {
+		t.Errorf("Expected an error adding an unknown type of link")
+	}
+}
+
+func TestCheckLinkCycles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		links    [][3]interface{}
+		expected string
+	}{
+		{name: "chain", links: [][3]interface{}{{1, "blocks", 2}, {2, "blocks", 3}}, expected: ""},
+		{name: "blocking cycle", links: [][3]interface{}{{1, "blocks", 2}, {2, "blocks", 3}, {3, "blocks", 1}}, expected: "1 blocks 2 blocks 3 blocks 1"},
+		{name: "depends-on cycle", links: [][3]interface{}{{1, "blocks", 2}, {1, "depends-on", 2}}, expected: "1 blocks 2 blocks 1"},
+		{name: "parent cycle", links: [][3]interface{}{{1, "parent-of", 2}, {2, "parent-of", 1}}, expected: "1 parent-of 2 parent-of 1"},
+		{name: "unrelated types", links: [][3]interface{}{{1, "blocks", 2}, {2, "parent-of", 1}}, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		tickets := []Ticket{{ID: 1}, {ID: 2}, {ID: 3}}
+		for _, l := range tc.links {
+			err := AddLink(&tickets[l[0].(int)-1], &tickets[l[2].(int)-1], l[1].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := CheckLinkCycles(tickets)
+		if tc.expected == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if tc.expected != "" && (err == nil || !strings.Contains(err.Error(), tc.expected)) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestFilterUnblockedTickets(t *testing.T) {
+	tickets := []Ticket{{ID: 1, Status: "open"}, {ID: 2, Status: "open"}, {ID: 3, Status: "Closed"}, {ID: 4, Status: "new"}}
+	AddLink(&tickets[0], &tickets[1], "blocks")
+	AddLink(&tickets[2], &tickets[3], "blocks")
+
+	var ids []int
+	for _, ticket := range FilterUnblockedTickets(tickets, tickets) {
+		ids = append(ids, ticket.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 3, 4}) {
+		t.Errorf("FilterUnblockedTickets returned tickets %v, want [1 3 4]", ids)
+	}
+}
+
+func TestLinkTree(t *testing.T) {
+	tickets := []Ticket{{ID: 1, Title: "One", Status: "open"}, {ID: 2, Title: "Two", Status: "open"}, {ID: 3, Title: "Three", Status: "new"}}
+	AddLink(&tickets[0], &tickets[1], "blocks")
+	AddLink(&tickets[1], &tickets[2], "blocks")
+	AddLink(&tickets[2], &tickets[0], "duplicates")
+	tickets[0].Links = append(tickets[0].Links, Link{Type: "child-of", ID: 9})
+
+	expected := []string{
+		"* blocks 2: Two (open)",
+		"  * blocks 3: Three (new)",
+		"* duplicated-by 3: Three (new)",
+		"* child-of 9 (deleted)",
+	}
+	actual := linkTreeLines(LinkTree(tickets[0], tickets), "* ", "  ")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("LinkTree rendered\n%s\nwant\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestHandleLink(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = HandleCreate(common.BranchName, 0, "My second ticket", "", []string{}, 1, 1, "open", []Comment{}, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		ticketID    int
+		linkType    string
+		targetIDs   []int
+		unlinkFlag  bool
+		expectedErr bool
+		expected1   []Link
+		expected2   []Link
+	}{
+		{ticketID: 1, linkType: "blocks", targetIDs: []int{2}, expected1: []Link{{"blocks", 2}}, expected2: []Link{{"blocked-by", 1}}},
+		{ticketID: 2, linkType: "blocks", targetIDs: []int{1}, expectedErr: true, expected1: []Link{{"blocks", 2}}, expected2: []Link{{"blocked-by", 1}}},
+		{ticketID: 1, linkType: "blocks", targetIDs: []int{3}, expectedErr: true, expected1: []Link{{"blocks", 2}}, expected2: []Link{{"blocked-by", 1}}},
+		{ticketID: 2, linkType: "blocked-by", targetIDs: []int{1}, unlinkFlag: true},
+	}
+
+	for _, tc := range testCases {
+		err := HandleLink(common.BranchName, tc.ticketID, tc.linkType, tc.targetIDs, tc.unlinkFlag, true)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("HandleLink(%d %s %v) returned error %v, expected an error: %t", tc.ticketID, tc.linkType, tc.targetIDs, err, tc.expectedErr)
+		}
+
+		thisRepo, err := git.OpenRepository(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		links1 := FilterTicketsByID(tickets, 1).Links
+		links2 := FilterTicketsByID(tickets, 2).Links
+		if len(links1) != len(tc.expected1) || len(links2) != len(tc.expected2) ||
+			(len(tc.expected1) > 0 && !reflect.DeepEqual(links1, tc.expected1)) ||
+			(len(tc.expected2) > 0 && !reflect.DeepEqual(links2, tc.expected2)) {
+			t.Errorf("After HandleLink(%d %s %v) links are %v and %v, want %v and %v", tc.ticketID, tc.linkType, tc.targetIDs, links1, links2, tc.expected1, tc.expected2)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 
 	git "github.com/jeffwelling/git2go/v37"
 	"github.com/jeffwelling/giticket/pkg/common"
@@ -12,7 +13,11 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-func HandleList(w io.Writer, windowWidth int, branchName string, filterName string, filterSet bool, debugFlag bool) error {
+// HandleList takes a writer, the window width, a branch name, a filter name, a
+// flag to save the filter as the current filter, ListOptions describing how to
+// sort, group and page the tickets, and a debug flag. It writes the list of
+// tickets to the writer, and returns an error if there was one.
+func HandleList(w io.Writer, windowWidth int, branchName string, filterName string, filterSet bool, listOptions ListOptions, debugFlag bool) error {
 	debug.DebugMessage(debugFlag, "Opening git repository")
 	thisRepo, err := git.OpenRepository(".")
 	if err != nil {
@@ -20,7 +25,7 @@ func HandleList(w io.Writer, windowWidth int, branchName string, filterName stri
 	}
 
 	output, err := ListTickets(
-		thisRepo, branchName, windowWidth, filterName, filterSet, debugFlag)
+		thisRepo, branchName, windowWidth, filterName, filterSet, listOptions, debugFlag)
 	if err != nil {
 		return err
 	}
@@ -28,20 +33,31 @@ func HandleList(w io.Writer, windowWidth int, branchName string, filterName stri
 	return nil
 }
 
-// GetTicketsList takes no parameters and returns a list of tickets
+// GetTicketsList takes no parameters and returns a list of tickets sorted by ID
 // It is intended to be used by giticket-webui, GetListOfTickets requires a repo
-// parameter, this creates the repo and calls GetListOfTickets
+// parameter, this creates the repo and calls GetListOfTickets. Callers wanting
+// a different order or a page of tickets can use ApplyListOptions.
 func GetTicketsList() ([]Ticket, error) {
 	thisRepo, err := git.OpenRepository(".")
 	if err != nil {
 		return nil, err
 	}
-	return GetListOfTickets(thisRepo, common.BranchName, false)
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, false)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyListOptions(tickets, ListOptions{})
 }
 
-func ListTickets(thisRepo *git.Repository, branchName string, windowWidth int, filterName string, filterSet bool, debugFlag bool) (string, error) {
-	output := ""
-
+// ListTickets returns the list of tickets on branchName rendered in a string,
+// after filtering them with the named filter (or the current filter) and
+// sorting, grouping, paging and rendering them as described by listOptions.
+// An empty output format or columns in listOptions fall back to the user's
+// settings. If filterSet is true, the named filter is saved as the user's
+// current filter. Table output is truncated to fit windowWidth, if it is
+// greater than 0. A Format in listOptions renders each ticket with that
+// template instead of the output format and columns.
+func ListTickets(thisRepo *git.Repository, branchName string, windowWidth int, filterName string, filterSet bool, listOptions ListOptions, debugFlag bool) (string, error) {
 	// Get a list of tickets from the repo
 	var ticketsList []Ticket
 	ticketsList, err := GetListOfTickets(thisRepo, branchName, debugFlag)
@@ -49,19 +65,36 @@ func ListTickets(thisRepo *git.Repository, branchName string, windowWidth int, f
 		return "", fmt.Errorf("unable to list tickets: %s", err) // TODO: err
 	}
 
-	// Sanity check that a filter has been set before attempting to set
-	// preferred filter
-	currentFilter, err := GetCurrentFilter(debugFlag)
-	if err != nil {
-		return "", err
-	}
-
 	// If the user is trying to set the preferred filter, but the filter name is
 	// empty, that's an error.
 	if filterName == "" && filterSet {
 		return "", fmt.Errorf("cannot set preferred filter when no filter has been configured yet, create one with the filter subcommand")
 	}
 
+	// Save the preferred filter for this user before listing with it
+	if filterSet {
+		err = SetCurrentFilter(thisRepo, branchName, filterName, debugFlag)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Flags take precedence over settings, see LoadSettings
+	settings, err := LoadSettings(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return "", err
+	}
+	currentFilter := settings.CurrentFilter
+	if listOptions.Output == "" {
+		listOptions.Output = settings.Output
+	}
+	if len(listOptions.Columns) == 0 && settings.Columns != "" {
+		listOptions.Columns, err = ParseListColumns(settings.Columns)
+		if err != nil {
+			return "", fmt.Errorf("invalid columns setting: %s", err)
+		}
+	}
+
 	// Filter tickets
 	filteredTicketsList := new([]Ticket)
 	if filterName != "" {
@@ -78,48 +111,93 @@ func ListTickets(thisRepo *git.Repository, branchName string, windowWidth int, f
 		filteredTicketsList = &ticketsList
 	}
 
-	widthOfID := widest(ticketsList, "ID")
-	if widthOfID < 3 {
-		widthOfID = 3
-	}
-	widthOfTitle := widest(ticketsList, "Title")
-	if widthOfTitle < 20 {
-		widthOfTitle = 20
-	}
-	widthOfSeverity := widest(ticketsList, "Severity")
-	if widthOfSeverity < 9 {
-		widthOfSeverity = 9
+	// Only list the current user's tickets
+	if listOptions.Mine {
+		author, err := common.GetAuthor(thisRepo)
+		if err != nil {
+			return "", err
+		}
+		assigned := FilterTicketsByAssignee(*filteredTicketsList, author.Email)
+		filteredTicketsList = &assigned
 	}
-	widthOfStatus := widest(ticketsList, "Status")
-	if widthOfStatus < 10 {
-		widthOfStatus = 10
+
+	// Only list tickets that can be worked on now, blockers may have been
+	// filtered out so check against every ticket
+	if listOptions.Unblocked {
+		unblocked := FilterUnblockedTickets(*filteredTicketsList, ticketsList)
+		filteredTicketsList = &unblocked
 	}
 
-	// Print the header
-	output += padRight("ID", widthOfID) + " | " + padRight("Title", widthOfTitle) + " | " + padRight("Severity", widthOfSeverity) + " | " + padRight("Status", widthOfStatus) + "\n"
-	output += strings.Repeat("-", widthOfID+widthOfTitle+widthOfSeverity+widthOfStatus+4) + "\n"
+	// Sort and page the tickets
+	pageOfTickets, err := ApplyListOptions(*filteredTicketsList, listOptions)
+	if err != nil {
+		return "", err
+	}
 
-	// Print the tickets
-	for _, t := range *filteredTicketsList {
-		IDAsString := fmt.Sprintf("%d", t.ID)
-		SeverityAsString := fmt.Sprintf("%d", t.Severity)
-		output += fmt.Sprintf("%s | %s | %s | %s\n", padRight(IDAsString, widthOfID), padRight(t.Title, widthOfTitle), padRight(SeverityAsString, widthOfSeverity), padRight(t.Status, widthOfStatus))
+	output := &strings.Builder{}
+	if listOptions.Format != "" {
+		format, err := ResolveFormat(thisRepo, branchName, listOptions.Format, debugFlag)
+		if err != nil {
+			return "", err
+		}
+		tmpl, err := ParseFormat(format)
+		if err != nil {
+			return "", err
+		}
+		err = RenderFormat(output, pageOfTickets, tmpl)
+		if err != nil {
+			return "", err
+		}
+		return output.String(), nil
 	}
 
-	return output, nil
+	err = RenderTickets(output, pageOfTickets, listOptions.Columns, listOptions.Output, listOptions.GroupBy, windowWidth)
+	if err != nil {
+		return "", err
+	}
+	return output.String(), nil
 }
 
 // padRight() takes string s and width int, it finds the difference in length
-// between len(s) and width and adds that many spaces to the string to ensure
-// the returned string is exactly width len long
+// between the number of runes in s and width and adds that many spaces to the
+// string to ensure the returned string is exactly width runes long
 func padRight(s string, width int) string {
-	diff := width - len(s)
+	diff := width - utf8.RuneCountInString(s)
 	if diff <= 0 {
-		return s[0:width]
+		return string([]rune(s)[0:width])
 	}
 	return s + strings.Repeat(" ", diff)
 }
 
+// writeTable writes rows to w as a table with columns separated by " | ",
+// and a line of dashes under the first row, its header. It returns the line of
+// dashes so a footer can be separated the same way.
+func writeTable(w io.Writer, rows [][]string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	width := 3 * (len(widths) - 1)
+	for _, columnWidth := range widths {
+		width += columnWidth
+	}
+	separator := strings.Repeat("-", width)
+
+	for n, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = padRight(cell, widths[i])
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " | "), " "))
+		if n == 0 {
+			fmt.Fprintln(w, separator)
+		}
+	}
+	return separator
+}
+
 // widest() takes a list of tickets and a string representing the attribute name
 // of each ticket to check to find the widest string, and return that value
 func widest(tickets []Ticket, attr string) int {
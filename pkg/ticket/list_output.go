@@ -0,0 +1,440 @@
+package ticket
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultListColumns are the columns printed by the list subcommand when no
+// columns are requested
+var DefaultListColumns = []string{"id", "title", "severity", "status"}
+
+// ListOutputFormats are the output formats accepted by RenderTickets
+var ListOutputFormats = []string{"table", "json", "yaml", "csv", "tsv", "markdown"}
+
+// listColumn describes a column that can be printed when listing tickets
+type listColumn struct {
+	// header is the column title used in table, csv, tsv and markdown output
+	header string
+	// text returns the value of the column as a string for table output
+	text func(t Ticket) string
+	// value returns the value of the column for json and yaml output
+	value func(t Ticket) interface{}
+	// flexible columns are shrunk first when the table is too wide
+	flexible bool
+}
+
+// listColumns maps the name of each column accepted by --columns to its
+// listColumn
+var listColumns = map[string]listColumn{
+	"id": {
+		header: "ID",
+		text:   func(t Ticket) string { return strconv.Itoa(t.ID) },
+		value:  func(t Ticket) interface{} { return t.ID },
+	},
+	"title": {
+		header:   "Title",
+		text:     func(t Ticket) string { return t.Title },
+		value:    func(t Ticket) interface{} { return t.Title },
+		flexible: true,
+	},
+	"priority": {
+		header: "Priority",
+		text:   func(t Ticket) string { return strconv.Itoa(t.Priority) },
+		value:  func(t Ticket) interface{} { return t.Priority },
+	},
+	"severity": {
+		header: "Severity",
+		text:   func(t Ticket) string { return strconv.Itoa(t.Severity) },
+		value:  func(t Ticket) interface{} { return t.Severity },
+	},
+	"status": {
+		header: "Status",
+		text:   func(t Ticket) string { return t.Status },
+		value:  func(t Ticket) interface{} { return t.Status },
+	},
+	"labels": {
+		header:   "Labels",
+		text:     func(t Ticket) string { return strings.Join(t.Labels, ",") },
+		value:    func(t Ticket) interface{} { return t.Labels },
+		flexible: true,
+	},
+	"assignees": {
+		header:   "Assignees",
+		text:     func(t Ticket) string { return strings.Join(t.Assignees, ", ") },
+		value:    func(t Ticket) interface{} { return t.Assignees },
+		flexible: true,
+	},
+	"milestone": {
+		header: "Milestone",
+		text:   func(t Ticket) string { return t.Milestone },
+		value:  func(t Ticket) interface{} { return t.Milestone },
+	},
+	"due": {
+		header: "Due",
+		text:   func(t Ticket) string { return describeDue(t, time.Now()) },
+		value:  func(t Ticket) interface{} { return t.Due },
+	},
+	"links": {
+		header: "Links",
+		text: func(t Ticket) string {
+			links := make([]string, len(t.Links))
+			for i, l := range sortedLinks(t.Links) {
+				links[i] = l.Type + " " + strconv.Itoa(l.ID)
+			}
+			return strings.Join(links, ", ")
+		},
+		value:    func(t Ticket) interface{} { return t.Links },
+		flexible: true,
+	},
+	"checklist": {
+		header: "Checklist",
+		text:   func(t Ticket) string { return ChecklistProgress(t) },
+		value:  func(t Ticket) interface{} { return ChecklistProgress(t) },
+	},
+	"estimate": {
+		header: "Estimate",
+		text: func(t Ticket) string {
+			if t.Estimate == 0 {
+				return ""
+			}
+			return formatWork(t.Estimate)
+		},
+		value: func(t Ticket) interface{} { return t.Estimate },
+	},
+	"logged": {
+		header: "Logged",
+		text: func(t Ticket) string {
+			if len(t.WorkLog) == 0 {
+				return ""
+			}
+			return formatWork(LoggedWork(t))
+		},
+		value: func(t Ticket) interface{} { return LoggedWork(t) },
+	},
+	"created": {
+		header: "Created",
+		text:   func(t Ticket) string { return time.Unix(t.Created, 0).Format("2006-01-02") },
+		value:  func(t Ticket) interface{} { return t.Created },
+	},
+	"comments": {
+		header: "Comments",
+		text:   func(t Ticket) string { return strconv.Itoa(len(t.Comments)) },
+		value:  func(t Ticket) interface{} { return len(t.Comments) },
+	},
+}
+
+// ParseListColumns takes a comma separated list of column names and returns
+// them as a slice, or DefaultListColumns if columns is empty. Returns an error
+// if a column name is not recognized.
+func ParseListColumns(columns string) ([]string, error) {
+	if strings.TrimSpace(columns) == "" {
+		return DefaultListColumns, nil
+	}
+
+	var parsed []string
+	for _, column := range strings.Split(columns, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if _, ok := listColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column '%s', valid columns are: %s", column, strings.Join(validKeys(listColumns), ", "))
+		}
+		parsed = append(parsed, column)
+	}
+	return parsed, nil
+}
+
+// RenderTickets takes a writer, a list of tickets, the names of the columns to
+// print, an output format, the name of the attribute to group by (which may
+// be empty), and the window width. It writes the tickets to the writer in the
+// requested format, truncating table output to fit windowWidth if it is
+// greater than 0. Returns an error if there is one.
+func RenderTickets(w io.Writer, tickets []Ticket, columns []string, outputFormat string, groupBy string, windowWidth int) error {
+	if len(columns) == 0 {
+		columns = DefaultListColumns
+	}
+	for _, column := range columns {
+		if _, ok := listColumns[column]; !ok {
+			return fmt.Errorf("unknown column '%s', valid columns are: %s", column, strings.Join(validKeys(listColumns), ", "))
+		}
+	}
+	if outputFormat == "" {
+		outputFormat = "table"
+	}
+	if !slices.Contains(ListOutputFormats, outputFormat) {
+		return fmt.Errorf("unknown output format '%s', valid formats are: %s", outputFormat, strings.Join(ListOutputFormats, ", "))
+	}
+
+	groups := []TicketGroup{{Tickets: tickets}}
+	if groupBy != "" {
+		var err error
+		groups, err = GroupTickets(tickets, groupBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch outputFormat {
+	case "json", "yaml":
+		return renderTicketsStructured(w, groups, columns, outputFormat, groupBy)
+	case "csv", "tsv":
+		return renderTicketsDelimited(w, groups, columns, outputFormat, groupBy)
+	}
+
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if groupBy != "" {
+			heading := fmt.Sprintf("%s: %s (%d)", strings.ToLower(groupBy), group.Name, len(group.Tickets))
+			if outputFormat == "markdown" {
+				heading = "## " + heading + "\n"
+			}
+			fmt.Fprintln(w, heading)
+		}
+		if outputFormat == "markdown" {
+			renderTicketsMarkdown(w, group.Tickets, columns)
+		} else {
+			renderTicketsTable(w, group.Tickets, columns, windowWidth)
+		}
+	}
+	return nil
+}
+
+// renderTicketsTable writes tickets to w as a table of the given columns. Each
+// column is as wide as its widest value, and if the table is wider than
+// windowWidth the flexible columns are shrunk first, then the others, with
+// values that no longer fit truncated. A windowWidth of 0 disables truncation.
+func renderTicketsTable(w io.Writer, tickets []Ticket, columns []string, windowWidth int) {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = utf8.RuneCountInString(listColumns[column].header)
+		for _, t := range tickets {
+			widths[i] = max(widths[i], utf8.RuneCountInString(listColumns[column].text(t)))
+		}
+	}
+	fitColumns(columns, widths, windowWidth)
+
+	separatorWidth := 3 * (len(columns) - 1)
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = padRight(listColumns[column].header, widths[i])
+	}
+	fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " | "), " "))
+	total := separatorWidth
+	for _, width := range widths {
+		total += width
+	}
+	fmt.Fprintln(w, strings.Repeat("-", total))
+
+	for _, t := range tickets {
+		for i, column := range columns {
+			cells[i] = padRight(truncate(listColumns[column].text(t), widths[i]), widths[i])
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " | "), " "))
+	}
+}
+
+// fitColumns shrinks widths in place so the table of columns fits within
+// windowWidth, shrinking flexible columns before the others. Columns are never
+// shrunk below the width of a short header, so very narrow windows may still
+// overflow. A windowWidth of 0 leaves widths unchanged.
+func fitColumns(columns []string, widths []int, windowWidth int) {
+	if windowWidth <= 0 {
+		return
+	}
+	total := 3 * (len(columns) - 1)
+	for _, width := range widths {
+		total += width
+	}
+
+	for _, flexible := range []bool{true, false} {
+		for total > windowWidth {
+			// Shrink the widest column of this kind by one
+			widest := -1
+			for i, column := range columns {
+				if listColumns[column].flexible != flexible || widths[i] <= 3 {
+					continue
+				}
+				if widest == -1 || widths[i] > widths[widest] {
+					widest = i
+				}
+			}
+			if widest == -1 {
+				break
+			}
+			widths[widest]--
+			total--
+		}
+	}
+}
+
+// renderTicketsMarkdown writes tickets to w as a markdown table of the given
+// columns
+func renderTicketsMarkdown(w io.Writer, tickets []Ticket, columns []string) {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = listColumns[column].header
+	}
+	fmt.Fprintln(w, "| "+strings.Join(cells, " | ")+" |")
+	for i := range columns {
+		cells[i] = "---"
+	}
+	fmt.Fprintln(w, "| "+strings.Join(cells, " | ")+" |")
+
+	for _, t := range tickets {
+		for i, column := range columns {
+			cell := listColumns[column].text(t)
+			cell = strings.ReplaceAll(cell, "|", "\\|")
+			cells[i] = strings.ReplaceAll(cell, "\n", " ")
+		}
+		fmt.Fprintln(w, "| "+strings.Join(cells, " | ")+" |")
+	}
+}
+
+// renderTicketsDelimited writes tickets to w as comma or tab separated values,
+// with a header row. When grouping, the group name is written as the first
+// column.
+func renderTicketsDelimited(w io.Writer, groups []TicketGroup, columns []string, outputFormat string, groupBy string) error {
+	writer := csv.NewWriter(w)
+	if outputFormat == "tsv" {
+		writer.Comma = '\t'
+	}
+
+	var record []string
+	if groupBy != "" {
+		record = append(record, strings.ToLower(groupBy))
+	}
+	for _, column := range columns {
+		record = append(record, column)
+	}
+	err := writer.Write(record)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		for _, t := range group.Tickets {
+			record = record[:0]
+			if groupBy != "" {
+				record = append(record, group.Name)
+			}
+			for _, column := range columns {
+				record = append(record, listColumns[column].text(t))
+			}
+			err = writer.Write(record)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// renderTicketsStructured writes tickets to w as json or yaml. Each ticket is
+// written as an object keyed by column name, and when grouping the tickets are
+// nested in a list of groups with their name and count.
+func renderTicketsStructured(w io.Writer, groups []TicketGroup, columns []string, outputFormat string, groupBy string) error {
+	toRows := func(tickets []Ticket) []yaml.MapSlice {
+		rows := []yaml.MapSlice{}
+		for _, t := range tickets {
+			row := yaml.MapSlice{}
+			for _, column := range columns {
+				row = append(row, yaml.MapItem{Key: column, Value: listColumns[column].value(t)})
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	var document interface{}
+	if groupBy == "" {
+		document = toRows(groups[0].Tickets)
+	} else {
+		grouped := []yaml.MapSlice{}
+		for _, group := range groups {
+			grouped = append(grouped, yaml.MapSlice{
+				{Key: "name", Value: group.Name},
+				{Key: "count", Value: len(group.Tickets)},
+				{Key: "tickets", Value: toRows(group.Tickets)},
+			})
+		}
+		document = grouped
+	}
+
+	if outputFormat == "yaml" {
+		return yaml.NewEncoder(w).Encode(document)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(orderedJSON(document))
+}
+
+// orderedMap is a yaml.MapSlice that marshals to a JSON object with its keys
+// in order
+type orderedMap yaml.MapSlice
+
+// MarshalJSON writes the orderedMap as a JSON object, preserving key order
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, item := range m {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		key, err := json.Marshal(fmt.Sprint(item.Key))
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(orderedJSON(item.Value))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return []byte(b.String()), nil
+}
+
+// orderedJSON converts yaml.MapSlices within v into orderedMaps so they can be
+// marshalled to JSON without losing key order
+func orderedJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		return orderedMap(v)
+	case []yaml.MapSlice:
+		converted := make([]interface{}, len(v))
+		for i := range v {
+			converted[i] = orderedMap(v[i])
+		}
+		return converted
+	}
+	return v
+}
+
+// truncate shortens s to at most width runes, replacing the last rune with an
+// ellipsis if anything was cut off
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,147 @@
+package ticket
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseListColumns(t *testing.T) {
+	columns, err := ParseListColumns("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(columns, ",") != strings.Join(DefaultListColumns, ",") {
+		t.Errorf("ParseListColumns(\"\") = %v, want %v", columns, DefaultListColumns)
+	}
+
+	columns, err = ParseListColumns(" ID, title,labels ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(columns, ",") != "id,title,labels" {
+		t.Errorf("ParseListColumns() = %v, want [id title labels]", columns)
+	}
+
+	_, err = ParseListColumns("id,nonexistent")
+	if err == nil {
+		t.Error("ParseListColumns() expected an error for an unknown column but got none")
+	}
+}
+
+func TestRenderTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Title: "First ticket", Labels: []string{"bugfix", "ux"}, Status: "new"},
+		{ID: 2, Title: "Pipes | in the title", Status: "closed", Comments: []Comment{{ID: 1}}},
+	}
+
+	testCases := []struct {
+		name         string
+		columns      []string
+		outputFormat string
+		groupBy      string
+		expected     string
+	}{
+		{
+			name:         "csv",
+			columns:      []string{"id", "title", "labels"},
+			outputFormat: "csv",
+			expected:     "id,title,labels\n1,First ticket,\"bugfix,ux\"\n2,Pipes | in the title,\n",
+		},
+		{
+			name:         "tsv grouped by status",
+			columns:      []string{"id", "comments"},
+			outputFormat: "tsv",
+			groupBy:      "status",
+			expected:     "status\tid\tcomments\nclosed\t2\t1\nnew\t1\t0\n",
+		},
+		{
+			name:         "markdown",
+			columns:      []string{"id", "title"},
+			outputFormat: "markdown",
+			expected:     "| ID | Title |\n| --- | --- |\n| 1 | First ticket |\n| 2 | Pipes \\| in the title |\n",
+		},
+		{
+			name:         "json",
+			columns:      []string{"id", "labels"},
+			outputFormat: "json",
+			expected:     "[\n  {\n    \"id\": 1,\n    \"labels\": [\n      \"bugfix\",\n      \"ux\"\n    ]\n  },\n  {\n    \"id\": 2,\n    \"labels\": null\n  }\n]\n",
+		},
+		{
+			name:         "yaml",
+			columns:      []string{"id", "status"},
+			outputFormat: "yaml",
+			expected:     "- id: 1\n  status: new\n- id: 2\n  status: closed\n",
+		},
+		{
+			name:         "table",
+			columns:      []string{"id", "status"},
+			outputFormat: "table",
+			expected:     "ID | Status\n-----------\n1  | new\n2  | closed\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		w := &strings.Builder{}
+		err := RenderTickets(w, tickets, tc.columns, tc.outputFormat, tc.groupBy, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.String() != tc.expected {
+			t.Errorf("RenderTickets() %s output was:\n%q\nwant:\n%q", tc.name, w.String(), tc.expected)
+		}
+	}
+
+	err := RenderTickets(&strings.Builder{}, tickets, nil, "xml", "", 0)
+	if err == nil {
+		t.Error("RenderTickets() expected an error for an unknown output format but got none")
+	}
+}
+
+func TestRenderTicketsTableFitsWindow(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Title: strings.Repeat("A very long title ", 10), Status: "in progress", Severity: 1},
+	}
+
+	for _, windowWidth := range []int{80, 40, 30} {
+		w := &strings.Builder{}
+		err := RenderTickets(w, tickets, DefaultListColumns, "table", "", windowWidth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimRight(w.String(), "\n"), "\n") {
+			if utf8.RuneCountInString(line) > windowWidth {
+				t.Errorf("RenderTickets() with windowWidth %d printed a line %d runes wide: %q", windowWidth, utf8.RuneCountInString(line), line)
+			}
+		}
+		if !strings.Contains(w.String(), "…") {
+			t.Errorf("RenderTickets() with windowWidth %d did not truncate the title", windowWidth)
+		}
+		// The status column isn't flexible and should only be shrunk once the
+		// title can't be shrunk any further
+		if windowWidth >= 40 && !strings.Contains(w.String(), "in progress") {
+			t.Errorf("RenderTickets() with windowWidth %d truncated the status before the title", windowWidth)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	testCases := []struct {
+		s        string
+		width    int
+		expected string
+	}{
+		{s: "short", width: 10, expected: "short"},
+		{s: "exact", width: 5, expected: "exact"},
+		{s: "truncated", width: 5, expected: "trun…"},
+		{s: "héllo wörld", width: 6, expected: "héllo…"},
+		{s: "anything", width: 0, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		actual := truncate(tc.s, tc.width)
+		if actual != tc.expected {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.s, tc.width, actual, tc.expected)
+		}
+	}
+}
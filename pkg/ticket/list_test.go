@@ -83,7 +83,7 @@ func TestHandleList(t *testing.T) {
 		w := &strings.Builder{}
 
 		// list tickets
-		err := HandleList(w, 0, testCase.branchName, "", false, testCase.debugFlag)
+		err := HandleList(w, 0, testCase.branchName, "", false, ListOptions{}, testCase.debugFlag)
 		if err != nil {
 			t.Fatal(err)
 		}
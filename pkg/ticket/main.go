@@ -5,22 +5,38 @@ package ticket
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 
 	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/repo"
 	"gopkg.in/yaml.v2"
 )
 
 type Ticket struct {
-	Title         string
-	Description   string
-	Labels        []string
-	Priority      int
-	Severity      int
-	Status        string
+	Title       string
+	Description string
+	Labels      []string
+	Priority    int
+	Severity    int
+	Status      string
+	Assignees   []string
+	Milestone   string
+	// Due is the unix time the ticket is due, or 0 if it has no due date
+	Due       int64
+	Links     []Link
+	Checklist []ChecklistItem
+	// Estimate is the expected work in seconds, or 0 if there is none
+	Estimate      int64
+	WorkLog       []WorkEntry `yaml:"work_log" json:"work_log"`
+	Commits       []CommitLink
+	CodeRefs      []CodeRef `yaml:"code_refs" json:"code_refs"`
 	Comments      []Comment
 	NextCommentID int `yaml:"next_comment_id" json:"next_comment_id"`
+	// ExternalID identifies the ticket in the tracker it was imported from,
+	// such as "github:12", or is empty if it wasn't imported
+	ExternalID string `yaml:"external_id" json:"external_id"`
 
 	// Set automatically
 	ID      int
@@ -75,13 +91,38 @@ func ReadNextTicketID(repo *git.Repository, commit *git.Commit) (int, error) {
 }
 
 // TicketFilename() returns the filename of the ticket by cating the ticket
-// ID and the ticket title, with spaces replaced by underscores
+// ID and the ticket title, with spaces replaced by underscores and slashes
+// replaced by dashes so the title can't leave the tickets directory
 func (t *Ticket) TicketFilename() string {
-	// turn spaces into underscores
-	title := strings.ReplaceAll(t.Title, " ", "_")
+	// turn spaces into underscores and slashes into dashes
+	title := strings.NewReplacer(" ", "_", "/", "-").Replace(t.Title)
 	return fmt.Sprintf("%d__%s", t.ID, title)
 }
 
+// TicketVersion takes a pointer to a git repository, a branch name, a ticket,
+// and a debug flag, and returns the ID of the blob the ticket is stored in at
+// the tip of branchName. The ID changes whenever the ticket does, so it can be
+// used to detect changes made by someone else.
+func TicketVersion(thisRepo *git.Repository, branchName string, t Ticket, debugFlag bool) (string, error) {
+	parentCommit, err := repo.GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return "", err
+	}
+	defer parentCommit.Free()
+
+	tree, err := parentCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+	defer tree.Free()
+
+	entry, err := tree.EntryByPath(path.Join(".giticket", "tickets", t.TicketFilename()))
+	if err != nil {
+		return "", err
+	}
+	return entry.Id.String(), nil
+}
+
 // TicketToYaml() returns the ticket as a YAML string
 // which is used to save to disk
 func (t *Ticket) TicketToYaml() []byte {
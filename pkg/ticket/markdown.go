@@ -0,0 +1,345 @@
+package ticket
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// ANSI escape codes used when rendering markdown for the terminal
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiRed       = "\x1b[31m"
+	ansiYellow    = "\x1b[33m"
+	ansiMagenta   = "\x1b[35m"
+	ansiCyan      = "\x1b[36m"
+)
+
+// markdownBlock is a single block of a markdown document as parsed by
+// parseMarkdown
+type markdownBlock struct {
+	// kind is one of heading, paragraph, item, ordered, quote, code or rule
+	kind string
+	// level is the heading level for headings, and the number of spaces the
+	// item is indented by for list items
+	level int
+	// marker is the number of an ordered list item, eg: "1."
+	marker string
+	// lines are the lines of text in the block, without their markers
+	lines []string
+}
+
+// markdownSpan is a run of inline markdown as parsed by parseInlineMarkdown
+type markdownSpan struct {
+	// kind is one of text, code, bold, italic or link
+	kind string
+	text string
+	// url is the destination of a link
+	url string
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownItem    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownOrdered = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	markdownRule    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	markdownLink    = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// parseMarkdown takes a markdown document and splits it into blocks. Only the
+// commonly used subset of markdown is supported: ATX headings, paragraphs,
+// bullet and numbered lists, block quotes, fenced code blocks and horizontal
+// rules.
+func parseMarkdown(s string) []markdownBlock {
+	var (
+		blocks []markdownBlock
+		fence  string
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			last := &blocks[len(blocks)-1]
+			last.lines = append(last.lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+			blocks = append(blocks, markdownBlock{kind: "code"})
+		case trimmed == "":
+			// Blank lines end the current paragraph or quote
+			blocks = append(blocks, markdownBlock{kind: "blank"})
+		case markdownHeading.MatchString(trimmed):
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			blocks = append(blocks, markdownBlock{kind: "heading", level: len(match[1]), lines: []string{match[2]}})
+		case markdownRule.MatchString(line):
+			blocks = append(blocks, markdownBlock{kind: "rule"})
+		case markdownItem.MatchString(line):
+			match := markdownItem.FindStringSubmatch(line)
+			blocks = append(blocks, markdownBlock{kind: "item", level: len(match[1]), lines: []string{match[2]}})
+		case markdownOrdered.MatchString(line):
+			match := markdownOrdered.FindStringSubmatch(line)
+			blocks = append(blocks, markdownBlock{kind: "ordered", level: len(match[1]), marker: match[2], lines: []string{match[3]}})
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			if len(blocks) > 0 && blocks[len(blocks)-1].kind == "quote" {
+				last := &blocks[len(blocks)-1]
+				last.lines = append(last.lines, text)
+			} else {
+				blocks = append(blocks, markdownBlock{kind: "quote", lines: []string{text}})
+			}
+		default:
+			if len(blocks) > 0 && blocks[len(blocks)-1].kind == "paragraph" {
+				last := &blocks[len(blocks)-1]
+				last.lines = append(last.lines, trimmed)
+			} else {
+				blocks = append(blocks, markdownBlock{kind: "paragraph", lines: []string{trimmed}})
+			}
+		}
+	}
+
+	// Drop the blank blocks, they were only needed to separate paragraphs
+	parsed := blocks[:0]
+	for _, block := range blocks {
+		if block.kind != "blank" {
+			parsed = append(parsed, block)
+		}
+	}
+	return parsed
+}
+
+// parseInlineMarkdown splits a line of markdown into spans of plain text,
+// `code`, **bold**, *italic* or _italic_ text, and [links](url). Spans are
+// not nested.
+func parseInlineMarkdown(s string) []markdownSpan {
+	var (
+		spans []markdownSpan
+		text  strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			spans = append(spans, markdownSpan{kind: "text", text: text.String()})
+			text.Reset()
+		}
+	}
+	// closeAt returns the index of the closing delimiter after i, or -1
+	closeAt := func(i int, delimiter string) int {
+		end := strings.Index(s[i+len(delimiter):], delimiter)
+		if end <= 0 {
+			return -1
+		}
+		return i + len(delimiter) + end
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && strings.ContainsRune("\\`*_[]()#<>|-", rune(s[i+1])):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case s[i] == '`':
+			if end := closeAt(i, "`"); end != -1 {
+				flush()
+				spans = append(spans, markdownSpan{kind: "code", text: s[i+1 : end]})
+				i = end + 1
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__"):
+			if end := closeAt(i, s[i:i+2]); end != -1 {
+				flush()
+				spans = append(spans, markdownSpan{kind: "bold", text: s[i+2 : end]})
+				i = end + 2
+				continue
+			}
+		case s[i] == '*' || (s[i] == '_' && (i == 0 || s[i-1] == ' ')):
+			if end := closeAt(i, s[i:i+1]); end != -1 && s[i+1] != ' ' {
+				flush()
+				spans = append(spans, markdownSpan{kind: "italic", text: s[i+1 : end]})
+				i = end + 1
+				continue
+			}
+		case s[i] == '[':
+			if match := markdownLink.FindStringSubmatch(s[i:]); match != nil {
+				flush()
+				spans = append(spans, markdownSpan{kind: "link", text: match[1], url: match[2]})
+				i += len(match[0])
+				continue
+			}
+		}
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+	return spans
+}
+
+// RenderMarkdownTerminal takes a markdown document and renders it for display
+// in a terminal, indenting every line by indent. Markdown syntax is removed,
+// and if color is true headings, emphasis, code and links are highlighted with
+// ANSI escape codes.
+func RenderMarkdownTerminal(s string, indent string, color bool) string {
+	style := func(text string, codes ...string) string {
+		if !color || text == "" {
+			return text
+		}
+		return strings.Join(codes, "") + text + ansiReset
+	}
+	inline := func(line string) string {
+		var b strings.Builder
+		for _, span := range parseInlineMarkdown(line) {
+			switch span.kind {
+			case "code":
+				b.WriteString(style(span.text, ansiCyan))
+			case "bold":
+				b.WriteString(style(span.text, ansiBold))
+			case "italic":
+				b.WriteString(style(span.text, ansiItalic))
+			case "link":
+				b.WriteString(style(span.text, ansiUnderline) + " " + style("("+span.url+")", ansiDim))
+			default:
+				b.WriteString(span.text)
+			}
+		}
+		return b.String()
+	}
+
+	var (
+		lines    []string
+		previous string
+	)
+	for i, block := range parseMarkdown(s) {
+		// Separate blocks with a blank line, except for items in the same list
+		isItem := block.kind == "item" || block.kind == "ordered"
+		if i > 0 && !(isItem && previous == block.kind) {
+			lines = append(lines, "")
+		}
+		previous = block.kind
+
+		switch block.kind {
+		case "heading":
+			lines = append(lines, style(inline(block.lines[0]), ansiBold, ansiMagenta))
+		case "paragraph":
+			for _, line := range block.lines {
+				lines = append(lines, inline(line))
+			}
+		case "item":
+			lines = append(lines, strings.Repeat(" ", block.level)+"• "+inline(block.lines[0]))
+		case "ordered":
+			lines = append(lines, strings.Repeat(" ", block.level)+block.marker+" "+inline(block.lines[0]))
+		case "quote":
+			for _, line := range block.lines {
+				lines = append(lines, style("│ ", ansiDim)+style(inline(line), ansiItalic))
+			}
+		case "code":
+			for _, line := range block.lines {
+				lines = append(lines, "  "+style(line, ansiYellow))
+			}
+		case "rule":
+			lines = append(lines, style(strings.Repeat("─", 20), ansiDim))
+		}
+	}
+
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderMarkdownHTML takes a markdown document and renders it as HTML. All
+// text is escaped, so the result is safe to embed in a page even if the
+// markdown came from an untrusted source.
+func RenderMarkdownHTML(s string) string {
+	inline := func(line string) string {
+		var b strings.Builder
+		for _, span := range parseInlineMarkdown(line) {
+			text := html.EscapeString(span.text)
+			switch span.kind {
+			case "code":
+				b.WriteString("<code>" + text + "</code>")
+			case "bold":
+				b.WriteString("<strong>" + text + "</strong>")
+			case "italic":
+				b.WriteString("<em>" + text + "</em>")
+			case "link":
+				b.WriteString(`<a href="` + html.EscapeString(safeURL(span.url)) + `">` + text + "</a>")
+			default:
+				b.WriteString(text)
+			}
+		}
+		return b.String()
+	}
+
+	var (
+		b        strings.Builder
+		openList string
+	)
+	closeList := func() {
+		if openList != "" {
+			b.WriteString("</" + openList + ">\n")
+			openList = ""
+		}
+	}
+	for _, block := range parseMarkdown(s) {
+		list := map[string]string{"item": "ul", "ordered": "ol"}[block.kind]
+		if list != openList {
+			closeList()
+			if list != "" {
+				b.WriteString("<" + list + ">\n")
+				openList = list
+			}
+		}
+
+		switch block.kind {
+		case "heading":
+			level := string(rune('0' + block.level))
+			b.WriteString("<h" + level + ">" + inline(block.lines[0]) + "</h" + level + ">\n")
+		case "paragraph":
+			lines := make([]string, len(block.lines))
+			for i, line := range block.lines {
+				lines[i] = inline(line)
+			}
+			b.WriteString("<p>" + strings.Join(lines, "\n") + "</p>\n")
+		case "item", "ordered":
+			b.WriteString("<li>" + inline(block.lines[0]) + "</li>\n")
+		case "quote":
+			lines := make([]string, len(block.lines))
+			for i, line := range block.lines {
+				lines[i] = inline(line)
+			}
+			b.WriteString("<blockquote><p>" + strings.Join(lines, "\n") + "</p></blockquote>\n")
+		case "code":
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(block.lines, "\n")) + "</code></pre>\n")
+		case "rule":
+			b.WriteString("<hr>\n")
+		}
+	}
+	closeList()
+	return b.String()
+}
+
+// safeURL returns url if it uses a scheme that is safe to link to, or "#"
+// otherwise, so that markdown can't be used to inject javascript: links
+func safeURL(url string) string {
+	scheme, _, found := strings.Cut(url, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		// Relative URLs are safe
+		return url
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto":
+		return url
+	}
+	return "#"
+}
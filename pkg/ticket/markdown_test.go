@@ -0,0 +1,45 @@
+package ticket
+
+import (
+	"testing"
+)
+
+func TestRenderMarkdownTerminal(t *testing.T) {
+	testCases := []struct {
+		markdown string
+		expected string
+	}{
+		{markdown: "plain text", expected: "> plain text"},
+		{markdown: "## Heading ##\nSome **bold** and _italic_ text", expected: "> Heading\n\n> Some bold and italic text"},
+		{markdown: "1. one\n2. two\n\n---", expected: "> 1. one\n> 2. two\n\n> ────────────────────"},
+		{markdown: "> quoted\n\n```go\nfmt.Println(\"*hi*\")\n```", expected: "> │ quoted\n\n>   fmt.Println(\"*hi*\")"},
+		{markdown: "A [link](https://example.com) and a \\*literal\\*", expected: "> A link (https://example.com) and a *literal*"},
+		{markdown: "snake_case_name and 2 * 3 * 4", expected: "> snake_case_name and 2 * 3 * 4"},
+	}
+
+	for _, tc := range testCases {
+		actual := RenderMarkdownTerminal(tc.markdown, "> ", false)
+		if actual != tc.expected {
+			t.Errorf("RenderMarkdownTerminal(%q) = %q, want %q", tc.markdown, actual, tc.expected)
+		}
+	}
+}
+
+func TestRenderMarkdownHTML(t *testing.T) {
+	testCases := []struct {
+		markdown string
+		expected string
+	}{
+		{markdown: "# Title\ntext <b>", expected: "<h1>Title</h1>\n<p>text &lt;b&gt;</p>\n"},
+		{markdown: "- a\n- `b`\n\n1. c", expected: "<ul>\n<li>a</li>\n<li><code>b</code></li>\n</ul>\n<ol>\n<li>c</li>\n</ol>\n"},
+		{markdown: "[x](javascript:void) [y](/tickets/2)", expected: "<p><a href=\"#\">x</a> <a href=\"/tickets/2\">y</a></p>\n"},
+		{markdown: "```\n<tag>\n```", expected: "<pre><code>&lt;tag&gt;</code></pre>\n"},
+	}
+
+	for _, tc := range testCases {
+		actual := RenderMarkdownHTML(tc.markdown)
+		if actual != tc.expected {
+			t.Errorf("RenderMarkdownHTML(%q) = %q, want %q", tc.markdown, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+	"gopkg.in/yaml.v2"
+)
+
+// milestonesDir is the directory milestones are stored in, relative to the
+// .giticket directory
+const milestonesDir = "milestones"
+
+// The states a milestone can be in
+const (
+	MilestoneOpen   = "open"
+	MilestoneClosed = "closed"
+)
+
+// MilestoneActions are the actions accepted by the milestone subcommand
+var MilestoneActions = []string{"create", "list", "show", "close", "set"}
+
+// Milestone is a release or other goal that tickets are planned for. Each
+// milestone is stored as yaml in .giticket/milestones/<Name>, and tickets
+// refer to it by name in Ticket.Milestone.
+type Milestone struct {
+	Name        string
+	Description string
+	// Due is the unix time the milestone is due, or 0 if it has no due date
+	Due   int64
+	State string
+
+	// Set automatically
+	Created int64
+	Closed  int64
+}
+
+// MilestoneProgress summarizes the tickets planned for a milestone
+type MilestoneProgress struct {
+	Milestone Milestone
+	Open      int
+	Closed    int
+	// Overdue are the IDs of the open tickets in the milestone that are
+	// past their due date or the milestone's
+	Overdue []int
+}
+
+// HandleMilestoneCreate takes a branch name, the name of a milestone, its
+// description, its due date as a unix time (0 for none), and a debug flag, and
+// commits the new open milestone. Returns an error if the name is invalid or a
+// milestone with that name already exists.
+func HandleMilestoneCreate(branchName string, name string, description string, due int64, debugFlag bool) error {
+	if !formatNamePattern.MatchString(name) {
+		return errors.New("invalid milestone name '" + name + "', names may contain letters, numbers, '.', '_' and '-'")
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.ReadFile(thisRepo, branchName, path.Join(milestonesDir, name), debugFlag)
+	if err == nil {
+		return errors.New("milestone '" + name + "' already exists")
+	}
+	if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return err
+	}
+
+	m := Milestone{
+		Name:        name,
+		Description: description,
+		Due:         due,
+		State:       MilestoneOpen,
+		Created:     time.Now().Unix(),
+	}
+	return commitMilestone(thisRepo, branchName, m, "Creating milestone "+name, debugFlag)
+}
+
+// HandleMilestoneClose takes a branch name, the name of a milestone, and a
+// debug flag, and closes the milestone. Tickets still open in the milestone
+// are left alone. Returns an error if the milestone doesn't exist or is
+// already closed.
+func HandleMilestoneClose(branchName string, name string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	m, err := ReadMilestone(thisRepo, branchName, name, debugFlag)
+	if err != nil {
+		return err
+	}
+	if m.State == MilestoneClosed {
+		return errors.New("milestone '" + name + "' is already closed")
+	}
+	m.State = MilestoneClosed
+	m.Closed = time.Now().Unix()
+	return commitMilestone(thisRepo, branchName, m, "Closing milestone "+name, debugFlag)
+}
+
+// HandleMilestoneSet takes a branch name, a list of ticket IDs, the name of a
+// milestone, and a debug flag, and plans the tickets for the milestone in a
+// single commit. An empty name removes the tickets from their milestone.
+// Returns an error if a ticket or the milestone doesn't exist, or if the
+// milestone is closed.
+func HandleMilestoneSet(branchName string, ticketIDs []int, name string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	if name != "" {
+		m, err := ReadMilestone(thisRepo, branchName, name, debugFlag)
+		if err != nil {
+			return err
+		}
+		if m.State == MilestoneClosed {
+			return errors.New("milestone '" + name + "' is closed")
+		}
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	var toCommit []common.TicketInterface
+	var ids []string
+	for _, ticketID := range ticketIDs {
+		t := FilterTicketsByID(tickets, ticketID)
+		if t.ID == 0 {
+			return fmt.Errorf("ticket %d not found", ticketID)
+		}
+		t.Milestone = name
+		toCommit = append(toCommit, &t)
+		ids = append(ids, strconv.Itoa(ticketID))
+	}
+
+	commitMessage := "Setting milestone of tickets " + strings.Join(ids, ", ") + " to " + name
+	if name == "" {
+		commitMessage = "Removing tickets " + strings.Join(ids, ", ") + " from their milestone"
+	}
+	return repo.CommitTickets(thisRepo, branchName, toCommit, commitMessage, debugFlag)
+}
+
+// HandleMilestoneList takes a writer, a branch name, and a debug flag, and
+// writes a table of every milestone with its progress to w
+func HandleMilestoneList(w io.Writer, branchName string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	milestones, err := ReadMilestones(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var progress []MilestoneProgress
+	for _, m := range milestones {
+		progress = append(progress, GetMilestoneProgress(m, tickets, now))
+	}
+	WriteMilestoneTable(w, progress)
+	return nil
+}
+
+// HandleMilestoneShow takes a writer, a branch name, the name of a milestone,
+// and a debug flag, and writes the milestone, its progress, and its tickets to
+// w. Returns an error if the milestone doesn't exist.
+func HandleMilestoneShow(w io.Writer, branchName string, name string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	m, err := ReadMilestone(thisRepo, branchName, name, debugFlag)
+	if err != nil {
+		return err
+	}
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	ShowMilestone(w, GetMilestoneProgress(m, tickets, time.Now()), FilterTicketsByMilestone(tickets, name))
+	return nil
+}
+
+// ReadMilestone takes a pointer to a git repository, a branch name, the name
+// of a milestone, and a debug flag, and returns the milestone. Returns an
+// error if the milestone doesn't exist.
+func ReadMilestone(thisRepo *git.Repository, branchName string, name string, debugFlag bool) (Milestone, error) {
+	var m Milestone
+	if !formatNamePattern.MatchString(name) {
+		return m, errors.New("invalid milestone name '" + name + "'")
+	}
+	contents, err := repo.ReadFile(thisRepo, branchName, path.Join(milestonesDir, name), debugFlag)
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return m, errors.New("no milestone named '" + name + "', see giticket milestone list")
+		}
+		return m, err
+	}
+	err = yaml.Unmarshal(contents, &m)
+	return m, err
+}
+
+// ReadMilestones takes a pointer to a git repository, a branch name, and a
+// debug flag, and returns every milestone sorted by name
+func ReadMilestones(thisRepo *git.Repository, branchName string, debugFlag bool) ([]Milestone, error) {
+	names, err := repo.ListFiles(thisRepo, branchName, milestonesDir, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	var milestones []Milestone
+	for _, name := range names {
+		m, err := ReadMilestone(thisRepo, branchName, name, debugFlag)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, nil
+}
+
+// commitMilestone writes the milestone to .giticket/milestones and commits it
+// with commitMessage
+func commitMilestone(thisRepo *git.Repository, branchName string, m Milestone, commitMessage string, debugFlag bool) error {
+	contents, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{path.Join(milestonesDir, m.Name): contents}
+	return repo.CommitFiles(thisRepo, branchName, files, commitMessage, debugFlag)
+}
+
+// FilterTicketsByMilestone takes a list of tickets and the name of a
+// milestone, and returns the tickets planned for that milestone
+func FilterTicketsByMilestone(tickets []Ticket, name string) []Ticket {
+	filtered := []Ticket{}
+	for _, t := range tickets {
+		if t.Milestone == name {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// GetMilestoneProgress takes a milestone, every ticket, and the current time,
+// and counts the milestone's open and closed tickets. Open tickets are overdue
+// if they are past their own due date, or if the milestone is still open and
+// its due date has passed.
+func GetMilestoneProgress(m Milestone, tickets []Ticket, now time.Time) MilestoneProgress {
+	progress := MilestoneProgress{Milestone: m}
+	pastDue := m.State != MilestoneClosed && m.Due != 0 && now.Unix() > m.Due
+	for _, t := range FilterTicketsByMilestone(tickets, m.Name) {
+		if IsClosed(t) {
+			progress.Closed++
+			continue
+		}
+		progress.Open++
+		if pastDue || IsOverdue(t, now) {
+			progress.Overdue = append(progress.Overdue, t.ID)
+		}
+	}
+	return progress
+}
+
+// Summary returns the progress as a short description such as "3/5 closed
+// (60%), 2 overdue"
+func (progress MilestoneProgress) Summary() string {
+	total := progress.Open + progress.Closed
+	if total == 0 {
+		return "no tickets"
+	}
+	summary := fmt.Sprintf("%d/%d closed (%d%%)", progress.Closed, total, progress.Closed*100/total)
+	if len(progress.Overdue) > 0 {
+		summary += fmt.Sprintf(", %d overdue", len(progress.Overdue))
+	}
+	return summary
+}
+
+// WriteMilestoneTable writes a table of milestones and their progress to w
+func WriteMilestoneTable(w io.Writer, progress []MilestoneProgress) {
+	rows := [][]string{{"Name", "State", "Due", "Progress"}}
+	for _, p := range progress {
+		rows = append(rows, []string{p.Milestone.Name, p.Milestone.State, formatDue(p.Milestone.Due), p.Summary()})
+	}
+	writeTable(w, rows)
+}
+
+// ShowMilestone writes a milestone, its progress, and a table of its tickets
+// to w
+func ShowMilestone(w io.Writer, progress MilestoneProgress, tickets []Ticket) {
+	m := progress.Milestone
+	fmt.Fprintf(w, "Name: %s\n", m.Name)
+	fmt.Fprintf(w, "Description: %s\n", m.Description)
+	fmt.Fprintf(w, "State: %s\n", m.State)
+	fmt.Fprintf(w, "Due: %s\n", formatDue(m.Due))
+	fmt.Fprintf(w, "Created: %s\n", formatTime(m.Created))
+	if m.Closed != 0 {
+		fmt.Fprintf(w, "Closed: %s\n", formatTime(m.Closed))
+	}
+	fmt.Fprintf(w, "Progress: %s\n", progress.Summary())
+	if len(progress.Overdue) > 0 {
+		overdue := make([]string, len(progress.Overdue))
+		for i, id := range progress.Overdue {
+			overdue[i] = strconv.Itoa(id)
+		}
+		fmt.Fprintf(w, "Overdue: %s\n", strings.Join(overdue, ", "))
+	}
+	if len(tickets) > 0 {
+		fmt.Fprintln(w)
+		renderTicketsTable(w, tickets, []string{"id", "title", "status"}, 0)
+	}
+}
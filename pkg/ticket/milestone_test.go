@@ -0,0 +1,150 @@
+package ticket
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestParseDate(t *testing.T) {
+	due, err := ParseDate("2024-07-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Unix(due, 0).Format("2006-01-02 15:04:05"); got != "2024-07-01 23:59:59" {
+		t.Errorf("ParseDate(\"2024-07-01\") = %s, want the end of that day", got)
+	}
+
+	_, err = ParseDate("July 1st")
+	if err == nil {
+		t.Errorf("Expected an error parsing \"July 1st\"")
+	}
+}
+
+func TestGetMilestoneProgress(t *testing.T) {
+	due, _ := ParseDate("2024-07-01")
+	tickets := []Ticket{
+		{ID: 1, Status: "closed", Milestone: "v1.0"},
+		{ID: 2, Status: "open", Milestone: "v1.0"},
+		{ID: 3, Status: "new", Milestone: "v1.0"},
+		{ID: 4, Status: "open", Milestone: "v2.0"},
+		{ID: 5, Status: "done", Milestone: "v1.0"},
+	}
+
+	testCases := []struct {
+		name            string
+		milestone       Milestone
+		now             time.Time
+		expected        MilestoneProgress
+		expectedSummary string
+	}{
+		{
+			name:            "before the due date",
+			milestone:       Milestone{Name: "v1.0", Due: due, State: MilestoneOpen},
+			now:             time.Date(2024, 7, 1, 12, 0, 0, 0, time.Local),
+			expected:        MilestoneProgress{Open: 2, Closed: 2},
+			expectedSummary: "2/4 closed (50%)",
+		},
+		{
+			name:            "after the due date",
+			milestone:       Milestone{Name: "v1.0", Due: due, State: MilestoneOpen},
+			now:             time.Date(2024, 7, 2, 0, 0, 0, 0, time.Local),
+			expected:        MilestoneProgress{Open: 2, Closed: 2, Overdue: []int{2, 3}},
+			expectedSummary: "2/4 closed (50%), 2 overdue",
+		},
+		{
+			name:            "closed after the due date",
+			milestone:       Milestone{Name: "v1.0", Due: due, State: MilestoneClosed},
+			now:             time.Date(2024, 7, 2, 0, 0, 0, 0, time.Local),
+			expected:        MilestoneProgress{Open: 2, Closed: 2},
+			expectedSummary: "2/4 closed (50%)",
+		},
+		{
+			name:            "without tickets",
+			milestone:       Milestone{Name: "v3.0", State: MilestoneOpen},
+			now:             time.Date(2024, 7, 2, 0, 0, 0, 0, time.Local),
+			expectedSummary: "no tickets",
+		},
+	}
+
+	for _, tc := range testCases {
+		progress := GetMilestoneProgress(tc.milestone, tickets, tc.now)
+		tc.expected.Milestone = tc.milestone
+		if !reflect.DeepEqual(progress, tc.expected) {
+			t.Errorf("%s: GetMilestoneProgress = %+v, want %+v", tc.name, progress, tc.expected)
+		}
+		if progress.Summary() != tc.expectedSummary {
+			t.Errorf("%s: Summary() = %q, want %q", tc.name, progress.Summary(), tc.expectedSummary)
+		}
+	}
+}
+
+func TestHandleMilestone(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = HandleMilestoneCreate(common.BranchName, "v1.0", "First release", 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleMilestoneCreate(common.BranchName, "v1.0", "", 0, true)
+	if err == nil {
+		t.Errorf("Expected an error creating a milestone that already exists")
+	}
+	err = HandleMilestoneSet(common.BranchName, []int{1}, "v2.0", true)
+	if err == nil {
+		t.Errorf("Expected an error planning a ticket for a milestone that doesn't exist")
+	}
+	err = HandleMilestoneSet(common.BranchName, []int{1}, "v1.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = HandleMilestoneList(&b, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "v1.0") || !strings.Contains(b.String(), "0/1 closed (0%)") {
+		t.Errorf("Unexpected milestone list:\n%s", b.String())
+	}
+
+	err = HandleMilestoneClose(common.BranchName, "v1.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleMilestoneSet(common.BranchName, []int{1}, "v1.0", true)
+	if err == nil {
+		t.Errorf("Expected an error planning a ticket for a closed milestone")
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadMilestone(thisRepo, common.BranchName, "v1.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != MilestoneClosed || m.Description != "First release" || m.Closed == 0 {
+		t.Errorf("Unexpected milestone after closing: %+v", m)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if milestone := FilterTicketsByID(tickets, 1).Milestone; milestone != "v1.0" {
+		t.Errorf("Ticket 1 has milestone %q, want v1.0", milestone)
+	}
+}
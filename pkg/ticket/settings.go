@@ -0,0 +1,409 @@
+package ticket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// The scopes settings can be stored in, from highest to lowest precedence.
+// Flags given on the command line take precedence over every scope.
+const (
+	// SettingsScopeLocal settings are per-user and per-repository, stored in
+	// the giticket section of .git/config
+	SettingsScopeLocal = "local"
+	// SettingsScopeGlobal settings are per-user and apply to every
+	// repository, stored in $XDG_CONFIG_HOME/giticket/config
+	SettingsScopeGlobal = "global"
+	// SettingsScopeShared settings are shared with everyone using the
+	// repository, stored in .giticket/settings.json on the giticket branch.
+	// The CurrentFilter in .giticket/filters.json is also a shared setting.
+	SettingsScopeShared = "shared"
+	// SettingsScopeDefault is the scope of settings that have not been set
+	SettingsScopeDefault = "default"
+)
+
+// SettingsScopes are the scopes settings can be written to
+var SettingsScopes = []string{SettingsScopeLocal, SettingsScopeGlobal, SettingsScopeShared}
+
+// SettingKeys are the names of the settings, as used in git config files
+var SettingKeys = []string{"currentFilter", "output", "columns", "editor", "startStatus", "reviewStatus"}
+
+// Settings holds the preferences used when giticket is run without flags
+// overriding them
+type Settings struct {
+	// CurrentFilter is the name of the filter used when listing tickets
+	CurrentFilter string
+	// Output is the default output format when listing tickets
+	Output string
+	// Columns is the default comma separated list of columns to list
+	Columns string
+	// Editor is the command used to edit comments, if unset the VISUAL and
+	// EDITOR environment variables are used
+	Editor string
+	// StartStatus is the status giticket start moves tickets to, if unset
+	// DefaultStartStatus is used
+	StartStatus string
+	// ReviewStatus is the status giticket finish moves tickets to, if unset
+	// DefaultReviewStatus is used
+	ReviewStatus string
+
+	// Sources records which scope each setting was loaded from, keyed by
+	// setting name
+	Sources map[string]string `json:"-" yaml:"-"`
+}
+
+// Get returns the value of the setting identified by key
+func (s *Settings) Get(key string) string {
+	switch strings.ToLower(key) {
+	case "currentfilter":
+		return s.CurrentFilter
+	case "output":
+		return s.Output
+	case "columns":
+		return s.Columns
+	case "editor":
+		return s.Editor
+	case "startstatus":
+		return s.StartStatus
+	case "reviewstatus":
+		return s.ReviewStatus
+	}
+	return ""
+}
+
+// Set sets the setting identified by key to value
+func (s *Settings) Set(key string, value string) {
+	switch strings.ToLower(key) {
+	case "currentfilter":
+		s.CurrentFilter = value
+	case "output":
+		s.Output = value
+	case "columns":
+		s.Columns = value
+	case "editor":
+		s.Editor = value
+	case "startstatus":
+		s.StartStatus = value
+	case "reviewstatus":
+		s.ReviewStatus = value
+	}
+}
+
+// merge copies each setting that is set in other into s, recording scope as
+// the source of those settings
+func (s *Settings) merge(other Settings, scope string) {
+	for _, key := range SettingKeys {
+		value := other.Get(key)
+		if value != "" {
+			s.Set(key, value)
+			s.Sources[key] = scope
+		}
+	}
+}
+
+// canonicalSettingKey returns the name of the setting identified by key as it
+// is spelled in SettingKeys, or an empty string if key is not a setting
+func canonicalSettingKey(key string) string {
+	for _, settingKey := range SettingKeys {
+		if strings.EqualFold(settingKey, key) {
+			return settingKey
+		}
+	}
+	return ""
+}
+
+// GlobalSettingsPath returns the path of the per-user settings file that
+// applies to every repository, $XDG_CONFIG_HOME/giticket/config, falling back
+// to ~/.config/giticket/config when XDG_CONFIG_HOME is not set.
+func GlobalSettingsPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "giticket", "config"), nil
+}
+
+// LoadSettings takes a pointer to a git repository, a branch name, and a debug
+// flag, and returns the settings merged from every scope. Local settings take
+// precedence over global settings, which take precedence over shared
+// settings. Returns an error if there is one.
+func LoadSettings(thisRepo *git.Repository, branchName string, debugFlag bool) (Settings, error) {
+	settings := Settings{Sources: make(map[string]string)}
+	for _, key := range SettingKeys {
+		settings.Sources[key] = SettingsScopeDefault
+	}
+
+	// Lowest precedence first, so each scope overrides the one before it
+	shared, err := loadSharedSettings(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return settings, err
+	}
+	settings.merge(shared, SettingsScopeShared)
+
+	globalPath, err := GlobalSettingsPath()
+	if err != nil {
+		return settings, err
+	}
+	_, err = os.Stat(globalPath)
+	if err == nil {
+		debug.DebugMessage(debugFlag, "Loading global settings from "+globalPath)
+		globalConfig, err := git.OpenOndisk(globalPath)
+		if err != nil {
+			return settings, err
+		}
+		loadSettingsFromConfig(&settings, globalConfig, SettingsScopeGlobal)
+		globalConfig.Free()
+	}
+
+	// The repository config merges .git/config with the user's git config,
+	// only .git/config is the local scope
+	debug.DebugMessage(debugFlag, "Loading local settings from the repository config")
+	repoConfig, err := thisRepo.Config()
+	if err != nil {
+		return settings, err
+	}
+	defer repoConfig.Free()
+	localConfig, err := repoConfig.OpenLevel(repoConfig, git.ConfigLevelLocal)
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	defer localConfig.Free()
+	loadSettingsFromConfig(&settings, localConfig, SettingsScopeLocal)
+
+	return settings, nil
+}
+
+// loadSettingsFromConfig reads each setting from the giticket section of a git
+// config into settings, recording scope as the source of those it finds
+func loadSettingsFromConfig(settings *Settings, config *git.Config, scope string) {
+	var fromConfig Settings
+	for _, key := range SettingKeys {
+		value, err := config.LookupString("giticket." + key)
+		if err == nil {
+			fromConfig.Set(key, value)
+		}
+	}
+	settings.merge(fromConfig, scope)
+}
+
+// loadSharedSettings reads the shared settings from .giticket/settings.json,
+// with the current filter from .giticket/filters.json if settings.json doesn't
+// set one. Missing files are treated as empty.
+func loadSharedSettings(thisRepo *git.Repository, branchName string, debugFlag bool) (Settings, error) {
+	var shared Settings
+
+	contents, err := repo.ReadFile(thisRepo, branchName, "settings.json", debugFlag)
+	if err == nil {
+		err = json.Unmarshal(contents, &shared)
+		if err != nil {
+			return shared, fmt.Errorf("unable to read .giticket/settings.json: %s", err)
+		}
+	} else if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return shared, err
+	}
+
+	if shared.CurrentFilter == "" {
+		contents, err = repo.ReadFile(thisRepo, branchName, "filters.json", debugFlag)
+		if err == nil {
+			var filters FilterList
+			err = json.Unmarshal(contents, &filters)
+			if err != nil {
+				return shared, fmt.Errorf("unable to read .giticket/filters.json: %s", err)
+			}
+			shared.CurrentFilter = filters.CurrentFilter
+		} else if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return shared, err
+		}
+	}
+
+	return shared, nil
+}
+
+// SetSetting takes a pointer to a git repository, a branch name, the scope to
+// write to, the name of a setting, its new value, and a debug flag. It writes
+// the setting to the given scope, an empty value removes the setting from that
+// scope. Writing to the shared scope creates a commit on branchName. Returns an
+// error if there is one.
+func SetSetting(thisRepo *git.Repository, branchName string, scope string, key string, value string, debugFlag bool) error {
+	settingKey := canonicalSettingKey(key)
+	if settingKey == "" {
+		return fmt.Errorf("unknown setting '%s', valid settings are: %s", key, strings.Join(SettingKeys, ", "))
+	}
+	err := validateSetting(settingKey, value)
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case SettingsScopeLocal:
+		return setConfigSetting(filepath.Join(thisRepo.Path(), "config"), settingKey, value, debugFlag)
+	case SettingsScopeGlobal:
+		globalPath, err := GlobalSettingsPath()
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(filepath.Dir(globalPath), 0755)
+		if err != nil {
+			return err
+		}
+		return setConfigSetting(globalPath, settingKey, value, debugFlag)
+	case SettingsScopeShared:
+		var shared Settings
+		contents, err := repo.ReadFile(thisRepo, branchName, "settings.json", debugFlag)
+		if err == nil {
+			err = json.Unmarshal(contents, &shared)
+			if err != nil {
+				return fmt.Errorf("unable to read .giticket/settings.json: %s", err)
+			}
+		} else if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return err
+		}
+
+		shared.Set(settingKey, value)
+
+		contents, err = json.MarshalIndent(shared, "", "  ")
+		if err != nil {
+			return err
+		}
+		message := "Setting shared setting " + settingKey + " to '" + value + "'"
+		if value == "" {
+			message = "Unsetting shared setting " + settingKey
+		}
+		return repo.CommitFiles(thisRepo, branchName, map[string][]byte{"settings.json": contents}, message, debugFlag)
+	}
+	return fmt.Errorf("unknown settings scope '%s', valid scopes are: %s", scope, strings.Join(SettingsScopes, ", "))
+}
+
+// setConfigSetting writes the setting identified by key to the git config file
+// at configPath, or removes it if value is empty
+func setConfigSetting(configPath string, key string, value string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Writing giticket."+key+" to "+configPath)
+	config, err := git.OpenOndisk(configPath)
+	if err != nil {
+		return err
+	}
+	defer config.Free()
+
+	if value == "" {
+		err = config.Delete("giticket." + key)
+		if err != nil && !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return err
+		}
+		return nil
+	}
+	return config.SetString("giticket."+key, value)
+}
+
+// validateSetting returns an error if value is not valid for the setting
+// identified by key. Empty values are always valid, they unset the setting.
+func validateSetting(key string, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch key {
+	case "output":
+		if !slices.Contains(ListOutputFormats, value) {
+			return fmt.Errorf("output must be one of: %s", strings.Join(ListOutputFormats, ", "))
+		}
+	case "columns":
+		_, err := ParseListColumns(value)
+		return err
+	}
+	return nil
+}
+
+// HandleConfigList takes a writer and a debug flag, and writes every setting
+// with its value and the scope it was loaded from to the writer. Returns an
+// error if there is one.
+func HandleConfigList(w io.Writer, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	settings, err := LoadSettings(thisRepo, common.BranchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range SettingKeys {
+		fmt.Fprintf(w, "%s=%s (%s)\n", key, settings.Get(key), settings.Sources[key])
+	}
+	return nil
+}
+
+// HandleConfigGet takes a writer, the name of a setting, and a debug flag, and
+// writes the value of the setting to the writer. Returns an error if there is
+// one.
+func HandleConfigGet(w io.Writer, key string, debugFlag bool) error {
+	if canonicalSettingKey(key) == "" {
+		return fmt.Errorf("unknown setting '%s', valid settings are: %s", key, strings.Join(SettingKeys, ", "))
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	settings, err := LoadSettings(thisRepo, common.BranchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, settings.Get(key))
+	return nil
+}
+
+// HandleConfigSet takes the scope to write to, the name of a setting, its new
+// value, and a debug flag, and writes the setting. An empty value removes the
+// setting from that scope. Returns an error if there is one.
+func HandleConfigSet(scope string, key string, value string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	return SetSetting(thisRepo, common.BranchName, scope, key, value, debugFlag)
+}
+
+// errNoFilter is returned by SetCurrentFilter when the named filter does not
+// exist
+var errNoFilter = errors.New("no filter exists with that name, create one with the filter subcommand")
+
+// SetCurrentFilter takes a pointer to a git repository, a branch name, the name
+// of a filter, and a debug flag. It saves the filter as the current filter in
+// the per-user local settings, so choosing a filter doesn't change it for
+// everyone else or create a commit. Returns an error if the filter does not
+// exist.
+func SetCurrentFilter(thisRepo *git.Repository, branchName string, filterName string, debugFlag bool) error {
+	filters, err := GetFilters(branchName, debugFlag)
+	if err != nil {
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return errNoFilter
+		}
+		return err
+	}
+	if _, ok := filters.Filters[filterName]; !ok {
+		return errNoFilter
+	}
+	return SetSetting(thisRepo, branchName, SettingsScopeLocal, "currentFilter", filterName, debugFlag)
+}
@@ -0,0 +1,154 @@
+package ticket
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestSettingsMerge(t *testing.T) {
+	settings := Settings{Sources: make(map[string]string)}
+	settings.merge(Settings{Output: "csv", Editor: "nano"}, SettingsScopeShared)
+	settings.merge(Settings{Output: "json"}, SettingsScopeGlobal)
+	settings.merge(Settings{CurrentFilter: "mine"}, SettingsScopeLocal)
+
+	testCases := []struct {
+		key            string
+		expectedValue  string
+		expectedSource string
+	}{
+		{key: "output", expectedValue: "json", expectedSource: SettingsScopeGlobal},
+		{key: "editor", expectedValue: "nano", expectedSource: SettingsScopeShared},
+		{key: "currentFilter", expectedValue: "mine", expectedSource: SettingsScopeLocal},
+		{key: "CURRENTFILTER", expectedValue: "mine", expectedSource: SettingsScopeLocal},
+	}
+
+	for _, tc := range testCases {
+		if settings.Get(tc.key) != tc.expectedValue {
+			t.Errorf("Get(%q) = %q, want %q", tc.key, settings.Get(tc.key), tc.expectedValue)
+		}
+		if settings.Sources[canonicalSettingKey(tc.key)] != tc.expectedSource {
+			t.Errorf("Sources[%q] = %q, want %q", tc.key, settings.Sources[canonicalSettingKey(tc.key)], tc.expectedSource)
+		}
+	}
+}
+
+func TestValidateSetting(t *testing.T) {
+	testCases := []struct {
+		key       string
+		value     string
+		expectErr bool
+	}{
+		{key: "output", value: "markdown", expectErr: false},
+		{key: "output", value: "xml", expectErr: true},
+		{key: "columns", value: "id,title", expectErr: false},
+		{key: "columns", value: "id,nonexistent", expectErr: true},
+		{key: "output", value: "", expectErr: false},
+		{key: "editor", value: "code --wait", expectErr: false},
+	}
+
+	for _, tc := range testCases {
+		err := validateSetting(tc.key, tc.value)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("validateSetting(%q, %q) returned error %v, expected an error: %t", tc.key, tc.value, err, tc.expectErr)
+		}
+	}
+}
+
+func TestGlobalSettingsPath(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	globalPath, err := GlobalSettingsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globalPath != filepath.Join(configHome, "giticket", "config") {
+		t.Errorf("GlobalSettingsPath() = %q, want it under %q", globalPath, configHome)
+	}
+}
+
+func TestHandleConfigSet(t *testing.T) {
+	common.UseTempDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		scope          string
+		key            string
+		value          string
+		expectedValue  string
+		expectedSource string
+	}{
+		{scope: SettingsScopeShared, key: "output", value: "csv", expectedValue: "csv", expectedSource: SettingsScopeShared},
+		{scope: SettingsScopeGlobal, key: "output", value: "json", expectedValue: "json", expectedSource: SettingsScopeGlobal},
+		{scope: SettingsScopeLocal, key: "output", value: "markdown", expectedValue: "markdown", expectedSource: SettingsScopeLocal},
+		{scope: SettingsScopeLocal, key: "output", value: "", expectedValue: "json", expectedSource: SettingsScopeGlobal},
+		{scope: SettingsScopeGlobal, key: "output", value: "", expectedValue: "csv", expectedSource: SettingsScopeShared},
+	}
+
+	for _, tc := range testCases {
+		err := HandleConfigSet(tc.scope, tc.key, tc.value, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		thisRepo, err := git.OpenRepository(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings, err := LoadSettings(thisRepo, common.BranchName, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if settings.Get(tc.key) != tc.expectedValue || settings.Sources[tc.key] != tc.expectedSource {
+			t.Errorf("After setting %s %s to %q got %q from %s, want %q from %s", tc.scope, tc.key, tc.value, settings.Get(tc.key), settings.Sources[tc.key], tc.expectedValue, tc.expectedSource)
+		}
+	}
+}
+
+func TestHandleConfigIgnoresGitconfig(t *testing.T) {
+	common.UseTempDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A giticket setting in ~/.gitconfig isn't a local setting
+	home := t.TempDir()
+	err = os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[giticket]\n\toutput = yaml\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalPath, err := git.SearchPath(git.ConfigLevelGlobal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = git.SetSearchPath(git.ConfigLevelGlobal, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { git.SetSearchPath(git.ConfigLevelGlobal, globalPath) })
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := LoadSettings(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Output == "yaml" || settings.Sources["output"] != SettingsScopeDefault {
+		t.Errorf("Got output %q from %s, want it unset", settings.Output, settings.Sources["output"])
+	}
+}
@@ -3,6 +3,9 @@ package ticket
 import (
 	"encoding/json"
 	"fmt"
+	"html"
+	"html/template"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -13,8 +16,17 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-// HandleShow is used to print a list of giticket tickets in a number of formats
-func HandleShow(ticketID int, output string, debugFlag bool, helpFlag bool) error {
+// ShowOutputFormats are the output formats accepted by ShowTickets
+var ShowOutputFormats = []string{"text", "yaml", "json", "markdown", "html"}
+
+// HandleShow takes a writer, a list of ticket IDs, the IDs that were only
+// given as part of a range, an output format, a --format template (which may
+// be empty), whether to render the description and comments as markdown,
+// whether to use color, a debug flag, and a help flag. It writes each ticket
+// to w in the order given, with the template if there is one, or in the
+// requested output format. Returns an error if a ticket doesn't exist, unless
+// it was only part of a range, or if there was another error.
+func HandleShow(w io.Writer, ticketIDs []int, fromRange map[int]bool, output string, format string, renderMarkdown bool, color bool, debugFlag bool, helpFlag bool) error {
 	debug.DebugMessage(debugFlag, "Opening git repository")
 	thisRepo, err := git.OpenRepository(".")
 	if err != nil {
@@ -29,65 +41,515 @@ func HandleShow(ticketID int, output string, debugFlag bool, helpFlag bool) erro
 	if err != nil {
 		return err
 	}
-	t := FilterTicketsByID(tickets, ticketID)
-	ShowTicket(t, output, debugFlag)
 
-	return nil
+	toShow, err := selectTickets(tickets, ticketIDs, fromRange)
+	if err != nil {
+		return err
+	}
+	LoadCodeSnippets(thisRepo, toShow, debugFlag)
+
+	if format != "" {
+		format, err = ResolveFormat(thisRepo, common.BranchName, format, debugFlag)
+		if err != nil {
+			return err
+		}
+		tmpl, err := ParseFormat(format)
+		if err != nil {
+			return err
+		}
+		return RenderFormat(w, toShow, tmpl)
+	}
+
+	return ShowTickets(w, toShow, tickets, output, renderMarkdown, color, debugFlag)
+}
+
+// maxTicketIDRange is the most ticket IDs a range such as 4-6 can include
+const maxTicketIDRange = 10000
+
+// selectTickets returns the tickets with the given IDs in order. Tickets that
+// don't exist are skipped if their ID was only part of a range, otherwise they
+// are an error.
+func selectTickets(tickets []Ticket, ticketIDs []int, fromRange map[int]bool) ([]Ticket, error) {
+	var selected []Ticket
+	for _, ticketID := range ticketIDs {
+		t := FilterTicketsByID(tickets, ticketID)
+		if t.ID == 0 {
+			if fromRange[ticketID] {
+				continue
+			}
+			return nil, fmt.Errorf("ticket %d not found", ticketID)
+		}
+		selected = append(selected, t)
+	}
+	return selected, nil
+}
+
+// ParseTicketIDs takes a comma separated list of ticket IDs or ranges of
+// ticket IDs such as "1,4-6", and returns the IDs in the order given. Returns
+// an error if an ID is not a positive integer or a range is backwards or too
+// long, see ParseTicketIDRanges.
+func ParseTicketIDs(ids string) ([]int, error) {
+	parsed, _, err := ParseTicketIDRanges(ids)
+	return parsed, err
 }
 
-// ShowTicket takes a ticket, an output type, and a debug flag and prints the
-// ticket details in the given format.
-func ShowTicket(ticket Ticket, output string, debug bool) {
-	// switch on output type
+// ParseTicketIDRanges is ParseTicketIDs, also returning the IDs that were
+// only given as part of a range. Ranges can include at most 10000 IDs.
+func ParseTicketIDRanges(ids string) ([]int, map[int]bool, error) {
+	var parsed []int
+	explicit := make(map[int]bool)
+	fromRange := make(map[int]bool)
+	for _, field := range strings.Split(ids, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(field, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || start < 1 {
+			return nil, nil, fmt.Errorf("invalid ticket ID '%s'", field)
+		}
+		if !isRange {
+			parsed = append(parsed, start)
+			explicit[start] = true
+			continue
+		}
+		end, err := strconv.Atoi(strings.TrimSpace(last))
+		if err != nil || end < start {
+			return nil, nil, fmt.Errorf("invalid range of ticket IDs '%s'", field)
+		}
+		if end-start >= maxTicketIDRange {
+			return nil, nil, fmt.Errorf("the range of ticket IDs '%s' is too long, ranges can include at most %d IDs", field, maxTicketIDRange)
+		}
+		for id := start; id <= end; id++ {
+			parsed = append(parsed, id)
+			fromRange[id] = true
+		}
+	}
+	for id := range explicit {
+		delete(fromRange, id)
+	}
+	return parsed, fromRange, nil
+}
+
+// ShowTickets takes a writer, a list of tickets, every ticket (used to show
+// the tickets each ticket is linked to), an output format, whether to render
+// the description and comments as markdown, whether to use color, and a debug
+// flag, and writes the ticket details to w in the given format.
+// renderMarkdown and color only apply to text output. Returns an error if the
+// output format is not recognized, or if there is an error writing.
+func ShowTickets(w io.Writer, tickets []Ticket, allTickets []Ticket, output string, renderMarkdown bool, color bool, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Showing "+strconv.Itoa(len(tickets))+" tickets as "+output)
 	switch output {
-	case "text":
-		ShowTicketsText(ticket, debug)
+	case "", "text":
+		for _, t := range tickets {
+			ShowTicketText(w, t, allTickets, renderMarkdown, color)
+		}
+		return nil
 	case "yaml":
-		ShowTicketsYaml(ticket, debug)
+		return ShowTicketsYaml(w, tickets)
 	case "json":
-		ShowTicketsJson(ticket, debug)
-	default:
-		ShowTicketsText(ticket, debug)
+		return ShowTicketsJson(w, tickets)
+	case "markdown":
+		ShowTicketsMarkdown(w, tickets, allTickets)
+		return nil
+	case "html":
+		return ShowTicketsHTML(w, tickets, allTickets)
 	}
+	return fmt.Errorf("unknown output format '%s', valid formats are: %s", output, strings.Join(ShowOutputFormats, ", "))
 }
 
-// ShowTicketsText is used to print a list of giticket tickets in text format
-func ShowTicketsText(t Ticket, debug bool) {
-	fmt.Println("ID: " + strconv.Itoa(t.ID))
-	fmt.Println("Title: " + t.Title)
-	fmt.Println("Description: " + t.Description)
-	fmt.Println("Status: " + t.Status)
-	fmt.Println("Severity: " + strconv.Itoa(t.Severity))
-	fmt.Println("Labels: " + strings.Join(t.Labels, ", "))
-	fmt.Println("Created: " + time.Unix(t.Created, 0).String())
-	fmt.Println("NextTicketID: " + strconv.Itoa(t.NextCommentID))
-	fmt.Println("Comments: ")
+// ShowTicketText writes a ticket to w in text format, using allTickets to
+// describe the tickets it is linked to. If renderMarkdown is true the
+// description and comment bodies are rendered as markdown, and if color is
+// true the field names and markdown are highlighted.
+func ShowTicketText(w io.Writer, t Ticket, allTickets []Ticket, renderMarkdown bool, color bool) {
+	field := func(name string) string {
+		if color {
+			return ansiBold + name + ":" + ansiReset
+		}
+		return name + ":"
+	}
+	body := func(text string, indent string) string {
+		if !renderMarkdown {
+			return " " + text
+		}
+		return "\n" + RenderMarkdownTerminal(text, indent, color)
+	}
+
+	fmt.Fprintln(w, field("ID")+" "+strconv.Itoa(t.ID))
+	fmt.Fprintln(w, field("Title")+" "+t.Title)
+	fmt.Fprintln(w, field("Description")+body(t.Description, "    "))
+	fmt.Fprintln(w, field("Status")+" "+t.Status)
+	fmt.Fprintln(w, field("Priority")+" "+strconv.Itoa(t.Priority))
+	fmt.Fprintln(w, field("Severity")+" "+strconv.Itoa(t.Severity))
+	fmt.Fprintln(w, field("Labels")+" "+strings.Join(t.Labels, ", "))
+	fmt.Fprintln(w, field("Assignees")+" "+strings.Join(t.Assignees, ", "))
+	fmt.Fprintln(w, field("Milestone")+" "+t.Milestone)
+	if IsOverdue(t, time.Now()) && color {
+		fmt.Fprintln(w, field("Due")+" "+ansiBold+ansiRed+describeDue(t, time.Now())+ansiReset)
+	} else {
+		fmt.Fprintln(w, field("Due")+" "+describeDue(t, time.Now()))
+	}
+	fmt.Fprintln(w, field("Created")+" "+time.Unix(t.Created, 0).String())
+	fmt.Fprintln(w, field("NextCommentID")+" "+strconv.Itoa(t.NextCommentID))
+	fmt.Fprintln(w, field("Links")+" ")
+	for _, line := range linkTreeLines(LinkTree(t, allTickets), "    ", "    ") {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, field("Checklist")+" "+ChecklistProgress(t))
+	for i, item := range t.Checklist {
+		fmt.Fprintln(w, "    "+checklistItemLine(i+1, item))
+	}
+	fmt.Fprintln(w, field("Time")+" "+WorkSummary(t))
+	for _, entry := range t.WorkLog {
+		fmt.Fprintln(w, "    "+workEntryLine(entry))
+	}
+	fmt.Fprintln(w, field("Commits")+" ")
+	for _, link := range t.Commits {
+		fmt.Fprintln(w, "    "+commitLinkLine(link))
+	}
+	fmt.Fprintln(w, field("Code")+" ")
+	for _, ref := range t.CodeRefs {
+		fmt.Fprintln(w, "    "+codeRefLine(ref))
+		for _, line := range numberedSnippet(ref) {
+			fmt.Fprintln(w, "        "+line)
+		}
+	}
+	fmt.Fprintln(w, field("Comments")+" ")
 
 	for _, comment := range t.Comments {
-		fmt.Println("    Comment ID: " + strconv.Itoa(t.ID) + "-" + strconv.Itoa(comment.ID))
-		fmt.Println("    Created: " + time.Unix(comment.Created, 0).String())
-		fmt.Println("    Author: " + comment.Author)
-		fmt.Println("    Body: " + comment.Body)
+		fmt.Fprintln(w, "    "+field("Comment ID")+" "+strconv.Itoa(t.ID)+"-"+strconv.Itoa(comment.ID))
+		fmt.Fprintln(w, "    "+field("Created")+" "+time.Unix(comment.Created, 0).String())
+		fmt.Fprintln(w, "    "+field("Author")+" "+comment.Author)
+		fmt.Fprintln(w, "    "+field("Body")+body(comment.Body, "        "))
+	}
+	fmt.Fprintln(w, "")
+}
+
+// workEntryLine returns a line describing logged work, eg: "1h30m by John
+// Smith <jsmith@example.com> on 2024-05-24 01:11:03 -0700: investigating"
+func workEntryLine(entry WorkEntry) string {
+	line := formatWork(entry.Duration) + " by " + entry.Author + " on " + formatTime(entry.Created)
+	if entry.Note != "" {
+		line += ": " + entry.Note
 	}
-	fmt.Println("")
+	return line
 }
 
-// ShowTicketsYaml is used to print a list of giticket tickets in yaml format
-func ShowTicketsYaml(t Ticket, debug bool) {
-	// turn the ticket into a yaml string
-	yamlTicket, err := yaml.Marshal(t)
+// ShowTicketsYaml writes tickets to w in yaml format, as one yaml document
+// per ticket
+func ShowTicketsYaml(w io.Writer, tickets []Ticket) error {
+	for i, t := range tickets {
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+		yamlTicket, err := yaml.Marshal(t)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, string(yamlTicket))
+	}
+	return nil
+}
+
+// ShowTicketsJson writes tickets to w in json format. A single ticket is
+// written as an object, and more than one as an array of objects.
+func ShowTicketsJson(w io.Writer, tickets []Ticket) error {
+	var document interface{} = tickets
+	if len(tickets) == 1 {
+		document = tickets[0]
+	}
+	jsonTickets, err := json.Marshal(document)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	fmt.Println(string(yamlTicket))
+	fmt.Fprintln(w, string(jsonTickets))
+	return nil
 }
 
-// ShowTicketsJson is used to print a list of giticket tickets in json format
-func ShowTicketsJson(t Ticket, debug bool) {
-	// turn the ticket into a json string
-	jsonTicket, err := json.Marshal(t)
+// ShowTicketsMarkdown writes tickets to w as a markdown document, with a
+// horizontal rule between tickets, using allTickets to describe the tickets
+// each ticket is linked to
+func ShowTicketsMarkdown(w io.Writer, tickets []Ticket, allTickets []Ticket) {
+	// escape stops titles and authors such as "Name <email>" being read as
+	// markdown or html
+	escape := strings.NewReplacer(`\`, `\\`, "<", `\<`, ">", `\>`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`)
+
+	for i, t := range tickets {
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %d: %s\n\n", t.ID, escape.Replace(t.Title))
+		fmt.Fprintln(w, "- **Status:** "+escape.Replace(t.Status))
+		fmt.Fprintln(w, "- **Priority:** "+strconv.Itoa(t.Priority))
+		fmt.Fprintln(w, "- **Severity:** "+strconv.Itoa(t.Severity))
+		fmt.Fprintln(w, "- **Labels:** "+escape.Replace(strings.Join(t.Labels, ", ")))
+		fmt.Fprintln(w, "- **Assignees:** "+escape.Replace(strings.Join(t.Assignees, ", ")))
+		fmt.Fprintln(w, "- **Milestone:** "+escape.Replace(t.Milestone))
+		fmt.Fprintln(w, "- **Due:** "+describeDue(t, time.Now()))
+		fmt.Fprintln(w, "- **Created:** "+formatTime(t.Created))
+		fmt.Fprintln(w)
+
+		if t.Description != "" {
+			fmt.Fprintln(w, "## Description")
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, strings.TrimSpace(t.Description))
+			fmt.Fprintln(w)
+		}
+
+		if len(t.Checklist) > 0 {
+			fmt.Fprintln(w, "## Checklist ("+ChecklistProgress(t)+")")
+			fmt.Fprintln(w)
+			for _, item := range t.Checklist {
+				box := "[ ]"
+				if item.Done {
+					box = "[x]"
+				}
+				line := "- " + box + " " + escape.Replace(item.Text)
+				if item.TicketID != 0 {
+					line += " (ticket " + strconv.Itoa(item.TicketID) + ")"
+				}
+				fmt.Fprintln(w, line)
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(t.WorkLog) > 0 || t.Estimate != 0 {
+			fmt.Fprintln(w, "## Time ("+WorkSummary(t)+")")
+			fmt.Fprintln(w)
+			for _, entry := range t.WorkLog {
+				fmt.Fprintln(w, "- "+escape.Replace(workEntryLine(entry)))
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(t.Commits) > 0 {
+			fmt.Fprintln(w, "## Commits")
+			fmt.Fprintln(w)
+			for _, link := range t.Commits {
+				fmt.Fprintln(w, "- "+escape.Replace(commitLinkLine(link)))
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(t.CodeRefs) > 0 {
+			fmt.Fprintln(w, "## Code")
+			fmt.Fprintln(w)
+			for _, ref := range t.CodeRefs {
+				fmt.Fprintln(w, "- "+escape.Replace(codeRefLine(ref)))
+				if len(ref.Snippet) > 0 {
+					fmt.Fprintln(w)
+					fmt.Fprintln(w, "  ```")
+					for _, line := range numberedSnippet(ref) {
+						fmt.Fprintln(w, "  "+line)
+					}
+					fmt.Fprintln(w, "  ```")
+				}
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(t.Links) > 0 {
+			fmt.Fprintln(w, "## Links")
+			fmt.Fprintln(w)
+			for _, line := range linkTreeLines(LinkTree(t, allTickets), "- ", "  ") {
+				fmt.Fprintln(w, escape.Replace(line))
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(t.Comments) > 0 {
+			fmt.Fprintln(w, "## Comments")
+			fmt.Fprintln(w)
+			for _, comment := range t.Comments {
+				fmt.Fprintf(w, "### %d-%d: %s, %s\n\n", t.ID, comment.ID, escape.Replace(comment.Author), formatTime(comment.Created))
+				fmt.Fprintln(w, strings.TrimSpace(comment.Body))
+				fmt.Fprintln(w)
+			}
+		}
+	}
+}
+
+// showHTMLTemplate is the page written by ShowTicketsHTML
+var showHTMLTemplate = template.Must(template.New("show").Funcs(template.FuncMap{
+	"markdown":  func(s string) template.HTML { return template.HTML(RenderMarkdownHTML(s)) },
+	"time":      formatTime,
+	"due":       func(t Ticket) string { return describeDue(t, time.Now()) },
+	"join":      strings.Join,
+	"work":      WorkSummary,
+	"workEntry": workEntryLine,
+	"commit":    commitLinkLine,
+	"codeRef":   codeRefLine,
+	"snippet":   func(ref CodeRef) string { return strings.Join(numberedSnippet(ref), "\n") },
+	// links and checklist are replaced by ShowTicketsHTML, which knows every
+	// ticket
+	"links":     func(t Ticket) template.HTML { return "" },
+	"checklist": func(t Ticket) template.HTML { return "" },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if eq (len .) 1}}{{with index . 0}}{{.ID}}: {{.Title}}{{end}}{{else}}Tickets{{end}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.25em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+.comment { border-left: 3px solid #ccc; padding-left: 1em; margin-bottom: 1em; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+</style>
+</head>
+<body>
+{{- range .}}
+<article class="ticket" id="ticket-{{.ID}}">
+<h1>{{.ID}}: {{.Title}}</h1>
+<dl>
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>Priority</dt><dd>{{.Priority}}</dd>
+<dt>Severity</dt><dd>{{.Severity}}</dd>
+<dt>Labels</dt><dd>{{join .Labels ", "}}</dd>
+<dt>Assignees</dt><dd>{{join .Assignees ", "}}</dd>
+<dt>Milestone</dt><dd>{{.Milestone}}</dd>
+<dt>Due</dt><dd>{{due .}}</dd>
+<dt>Created</dt><dd>{{time .Created}}</dd>
+</dl>
+{{- with .Description}}
+<section class="description">
+{{markdown .}}</section>
+{{- end}}
+{{- if .Checklist}}
+<h2>Checklist</h2>
+{{checklist .}}
+{{- end}}
+{{- if or .WorkLog .Estimate}}
+<h2>Time</h2>
+<p>{{work .}}</p>
+{{- with .WorkLog}}
+<ul>
+{{- range .}}
+<li>{{workEntry .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- with .Commits}}
+<h2>Commits</h2>
+<ul>
+{{- range .}}
+<li>{{commit .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .CodeRefs}}
+<h2>Code</h2>
+<ul>
+{{- range .}}
+<li>{{codeRef .}}{{if .Snippet}}<pre>{{snippet .}}</pre>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Links}}
+<h2>Links</h2>
+{{links .}}
+{{- end}}
+{{- if .Comments}}
+<h2>Comments</h2>
+{{- $id := .ID}}
+{{- range .Comments}}
+<section class="comment" id="comment-{{$id}}-{{.ID}}">
+<h3>{{$id}}-{{.ID}}: {{.Author}}, {{time .Created}}</h3>
+{{markdown .Body}}</section>
+{{- end}}
+{{- end}}
+</article>
+{{- end}}
+</body>
+</html>
+`))
+
+// ShowTicketsHTML writes tickets to w as an HTML page, with the description
+// and comments rendered as markdown, using allTickets to describe the tickets
+// each ticket is linked to
+func ShowTicketsHTML(w io.Writer, tickets []Ticket, allTickets []Ticket) error {
+	tmpl, err := showHTMLTemplate.Clone()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"links":     func(t Ticket) template.HTML { return template.HTML(linkTreeHTML(LinkTree(t, allTickets))) },
+		"checklist": func(t Ticket) template.HTML { return template.HTML(checklistHTML(t)) },
+	})
+	return tmpl.Execute(w, tickets)
+}
+
+// checklistItemLine returns a checklist item as a line of text, eg:
+// "[x] 2. Write the docs (ticket 12)"
+func checklistItemLine(position int, item ChecklistItem) string {
+	box := "[ ]"
+	if item.Done {
+		box = "[x]"
+	}
+	line := box + " " + strconv.Itoa(position) + ". " + item.Text
+	if item.TicketID != 0 {
+		line += " (ticket " + strconv.Itoa(item.TicketID) + ")"
 	}
-	fmt.Println(string(jsonTicket))
+	return line
+}
+
+// checklistHTML returns a ticket's checklist as an HTML list of disabled
+// checkboxes, with its progress
+func checklistHTML(t Ticket) string {
+	var b strings.Builder
+	b.WriteString("<p>" + html.EscapeString(ChecklistProgress(t)) + "</p>")
+	b.WriteString("<ul class=\"checklist\">")
+	for _, item := range t.Checklist {
+		checked := ""
+		if item.Done {
+			checked = " checked"
+		}
+		b.WriteString("<li><input type=\"checkbox\" disabled" + checked + "> " + html.EscapeString(item.Text))
+		if item.TicketID != 0 {
+			b.WriteString(" (ticket " + strconv.Itoa(item.TicketID) + ")")
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// linkTreeLines returns the nodes of a LinkTree as lines of text, one per
+// node, each starting with prefix and indented by indent for each level
+func linkTreeLines(nodes []LinkNode, prefix string, indent string) []string {
+	var lines []string
+	var walk func(nodes []LinkNode, depth int)
+	walk = func(nodes []LinkNode, depth int) {
+		for _, node := range nodes {
+			lines = append(lines, strings.Repeat(indent, depth)+prefix+node.describe())
+			walk(node.Children, depth+1)
+		}
+	}
+	walk(nodes, 0)
+	return lines
+}
+
+// linkTreeHTML returns the nodes of a LinkTree as nested HTML lists
+func linkTreeHTML(nodes []LinkNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<ul class=\"links\">")
+	for _, node := range nodes {
+		b.WriteString("<li>" + html.EscapeString(node.describe()) + linkTreeHTML(node.Children) + "</li>")
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// formatTime formats a unix timestamp for markdown and html output
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).Format("2006-01-02 15:04:05 -0700")
 }
@@ -0,0 +1,159 @@
+package ticket
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTicketIDs(t *testing.T) {
+	testCases := []struct {
+		ids         string
+		expected    []int
+		expectedErr bool
+	}{
+		{ids: "1", expected: []int{1}},
+		{ids: "3,1", expected: []int{3, 1}},
+		{ids: "1, 4-6", expected: []int{1, 4, 5, 6}},
+		{ids: ",2,", expected: []int{2}},
+		{ids: "", expected: nil},
+		{ids: "0", expectedErr: true},
+		{ids: "abc", expectedErr: true},
+		{ids: "6-4", expectedErr: true},
+		{ids: "1-2000000000", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ParseTicketIDs(tc.ids)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ParseTicketIDs(%q) returned error %v, expected an error: %t", tc.ids, err, tc.expectedErr)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("ParseTicketIDs(%q) = %v, want %v", tc.ids, actual, tc.expected)
+		}
+	}
+}
+
+func TestParseTicketIDRanges(t *testing.T) {
+	ids, fromRange, err := ParseTicketIDRanges("2, 1-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{2, 1, 2, 3}) || !reflect.DeepEqual(fromRange, map[int]bool{1: true, 3: true}) {
+		t.Errorf("ParseTicketIDRanges() = %v, %v", ids, fromRange)
+	}
+	ids, _, err = ParseTicketIDRanges("1-10000")
+	if err != nil || len(ids) != 10000 {
+		t.Errorf("ParseTicketIDRanges(\"1-10000\") returned %d IDs and error %v, want 10000 IDs", len(ids), err)
+	}
+}
+
+func TestSelectTickets(t *testing.T) {
+	tickets := []Ticket{{ID: 1}, {ID: 3}}
+	selected, err := selectTickets(tickets, []int{1, 2, 3}, map[int]bool{2: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0].ID != 1 || selected[1].ID != 3 {
+		t.Errorf("selectTickets() = %+v, want tickets 1 and 3", selected)
+	}
+	_, err = selectTickets(tickets, []int{1, 2}, nil)
+	if err == nil {
+		t.Error("selectTickets() with a missing ticket given explicitly didn't return an error")
+	}
+}
+
+func TestShowTickets(t *testing.T) {
+	tickets := []Ticket{
+		{
+			ID:          1,
+			Title:       "My <first> ticket",
+			Description: "Fix the **flux** capacitor",
+			Priority:    2,
+			Severity:    3,
+			Status:      "open",
+			Labels:      []string{"bug"},
+			Comments: []Comment{
+				{ID: 1, Body: "See [docs](javascript:alert(1))", Author: "John Smith <jsmith@example.com>"},
+			},
+			NextCommentID: 2,
+		},
+		{ID: 2, Title: "Second", Status: "closed"},
+	}
+
+	testCases := []struct {
+		output      string
+		contains    []string
+		notContains []string
+		expectedErr bool
+	}{
+		{
+			output:   "text",
+			contains: []string{"ID: 1\n", "Priority: 2\n", "NextCommentID: 2\n", "Body: See [docs]", "ID: 2\n"},
+		},
+		{
+			output:   "json",
+			contains: []string{`[{"Title":"My \u003cfirst\u003e ticket"`, `"next_comment_id":2`, `{"Title":"Second"`},
+		},
+		{
+			output:   "yaml",
+			contains: []string{"title: My <first> ticket\n", "\n---\ntitle: Second\n"},
+		},
+		{
+			output:   "markdown",
+			contains: []string{"# 1: My \\<first\\> ticket\n", "- **Priority:** 2\n", "### 1-1: John Smith \\<jsmith@example.com\\>, ", "\n---\n\n# 2: Second\n"},
+		},
+		{
+			output:      "html",
+			contains:    []string{"<title>Tickets</title>", "<h1>1: My &lt;first&gt; ticket</h1>", "<p>Fix the <strong>flux</strong> capacitor</p>", `<a href="#">docs</a>`, `id="ticket-2"`},
+			notContains: []string{"<first>", "javascript:"},
+		},
+		{
+			output:      "xml",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := ShowTickets(&b, tickets, tickets, tc.output, false, false, false)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("ShowTickets(%s) returned error %v, expected an error: %t", tc.output, err, tc.expectedErr)
+			continue
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(b.String(), s) {
+				t.Errorf("ShowTickets(%s) output does not contain %q:\n%s", tc.output, s, b.String())
+			}
+		}
+		for _, s := range tc.notContains {
+			if strings.Contains(b.String(), s) {
+				t.Errorf("ShowTickets(%s) output contains %q:\n%s", tc.output, s, b.String())
+			}
+		}
+	}
+}
+
+func TestShowTicketsRenderMarkdown(t *testing.T) {
+	tickets := []Ticket{{ID: 1, Title: "Rendered", Description: "# Steps\n\n- run `make`\n- see *error*"}}
+
+	var b strings.Builder
+	err := ShowTickets(&b, tickets, tickets, "text", true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Description:\n    Steps\n\n    • run make\n    • see error\n"
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Expected rendered description %q, got:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	err = ShowTickets(&b, tickets, tickets, "text", true, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), ansiCyan+"make"+ansiReset) {
+		t.Errorf("Expected colored code span, got:\n%q", b.String())
+	}
+}
@@ -0,0 +1,590 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// siteMarker is the file export-site writes to mark a directory as an exported
+// site, so the site can be exported to it again without removing anything
+// else
+const siteMarker = ".giticket-site"
+
+// siteOutputs are the files and directories export-site writes to the output
+// directory, which are removed before the site is exported again
+var siteOutputs = []string{siteMarker, "index.html", "search.html", "search.js", "search-index.js", "style.css", "tickets", "filters", "labels", "milestones"}
+
+// siteLink is a link to a page of the exported site listing tickets
+type siteLink struct {
+	Name  string
+	Href  string
+	Count int
+}
+
+// sitePage is the data every page of the exported site has. Root is the path
+// from the page to the top of the site, eg: "../" for a ticket's page.
+type sitePage struct {
+	Title string
+	Root  string
+}
+
+// siteIndexPage is the data for the front page of the exported site
+type siteIndexPage struct {
+	sitePage
+	Tickets    []Ticket
+	Filters    []siteLink
+	Labels     []siteLink
+	Milestones []siteLink
+}
+
+// siteListPage is the data for a page listing the tickets of a filter, label,
+// or milestone
+type siteListPage struct {
+	sitePage
+	Query       string
+	Description string
+	Details     string
+	Tickets     []Ticket
+}
+
+// siteTicketPage is the data for a ticket's page of the exported site
+type siteTicketPage struct {
+	sitePage
+	Ticket        Ticket
+	History       []HistoryEntry
+	LabelHrefs    map[string]string
+	MilestoneHref string
+	Links         template.HTML
+	Checklist     template.HTML
+}
+
+// siteSearchEntry is a ticket in the exported site's search index. Text is
+// everything the ticket can be found by, in lower case.
+type siteSearchEntry struct {
+	ID     int
+	Title  string
+	Status string
+	Href   string
+	Text   string
+}
+
+// HandleExportSite takes a writer, a branch name, an output directory, and a
+// debug flag, and writes a static HTML site of the tickets to outDir: an
+// index of every ticket, a page for each saved filter, label, and milestone
+// listing its tickets, a page for each ticket with its comments and the
+// commits that changed it, and a search page. Exporting the same tickets
+// always writes the same files, so an exported site can be kept in git and
+// diffed. outDir must be empty or hold a site exported before. Returns an
+// error if there is one.
+func HandleExportSite(w io.Writer, branchName string, outDir string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	filters, err := GetFilters(branchName, debugFlag)
+	if err != nil {
+		if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return err
+		}
+		filters = &FilterList{}
+	}
+	milestones, err := ReadMilestones(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	history, err := TicketHistory(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	files, err := SiteFiles(tickets, filters.Filters, milestones, history)
+	if err != nil {
+		return err
+	}
+
+	err = prepareSiteDir(outDir, debugFlag)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		debug.DebugMessage(debugFlag, "Writing "+name)
+		target := filepath.Join(outDir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(target, files[name], 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Exported %d tickets to %s\n", len(tickets), outDir)
+	return nil
+}
+
+// SiteFiles takes every ticket, the saved filters, the milestones, and the
+// history of each ticket, and returns the files of the exported site by their
+// path in the site, such as "tickets/12.html". Returns an error if a filter
+// can't be used.
+func SiteFiles(tickets []Ticket, filters map[string]Filter, milestones []Milestone, history map[int][]HistoryEntry) (map[string][]byte, error) {
+	tickets = slices.Clone(tickets)
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+
+	files := map[string][]byte{
+		siteMarker:  []byte("This directory was written by giticket export-site, which replaces its contents each time it runs.\n"),
+		"style.css": []byte(siteStyle),
+		"search.js": []byte(siteSearchScript),
+	}
+	render := func(name string, page string, data interface{}) error {
+		var b bytes.Buffer
+		err := siteTemplates.ExecuteTemplate(&b, page, data)
+		if err != nil {
+			return err
+		}
+		files[name] = b.Bytes()
+		return nil
+	}
+
+	index := siteIndexPage{sitePage: sitePage{Title: "Tickets"}, Tickets: tickets}
+
+	// Filters
+	var filterNames []string
+	for name := range filters {
+		filterNames = append(filterNames, name)
+	}
+	filterSlugs := siteSlugs(filterNames)
+	for _, name := range filterNames {
+		filtered, err := QueryTickets(tickets, filters[name].Filter, false)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", name, err)
+		}
+		href := "filters/" + filterSlugs[name] + ".html"
+		index.Filters = append(index.Filters, siteLink{Name: name, Href: href, Count: len(filtered)})
+		err = render(href, "list", siteListPage{
+			sitePage: sitePage{Title: "Filter: " + name, Root: "../"},
+			Query:    filters[name].Filter,
+			Tickets:  filtered,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Labels
+	var labelNames []string
+	for _, t := range tickets {
+		for _, label := range t.Labels {
+			if !slices.Contains(labelNames, label) {
+				labelNames = append(labelNames, label)
+			}
+		}
+	}
+	labelSlugs := siteSlugs(labelNames)
+	labelHrefs := make(map[string]string)
+	for _, name := range labelNames {
+		var labelled []Ticket
+		for _, t := range tickets {
+			if slices.Contains(t.Labels, name) {
+				labelled = append(labelled, t)
+			}
+		}
+		href := "labels/" + labelSlugs[name] + ".html"
+		labelHrefs[name] = href
+		index.Labels = append(index.Labels, siteLink{Name: name, Href: href, Count: len(labelled)})
+		err := render(href, "list", siteListPage{
+			sitePage: sitePage{Title: "Label: " + name, Root: "../"},
+			Tickets:  labelled,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Milestones, including those tickets are planned for that don't exist
+	byName := make(map[string]Milestone)
+	var milestoneNames []string
+	for _, m := range milestones {
+		byName[m.Name] = m
+		milestoneNames = append(milestoneNames, m.Name)
+	}
+	for _, t := range tickets {
+		if t.Milestone != "" && !slices.Contains(milestoneNames, t.Milestone) {
+			milestoneNames = append(milestoneNames, t.Milestone)
+		}
+	}
+	milestoneSlugs := siteSlugs(milestoneNames)
+	milestoneHrefs := make(map[string]string)
+	for _, name := range milestoneNames {
+		planned := FilterTicketsByMilestone(tickets, name)
+		href := "milestones/" + milestoneSlugs[name] + ".html"
+		milestoneHrefs[name] = href
+		index.Milestones = append(index.Milestones, siteLink{Name: name, Href: href, Count: len(planned)})
+		m := byName[name]
+		details := m.State
+		if m.Due != 0 {
+			details += ", due " + formatDue(m.Due)
+		}
+		err := render(href, "list", siteListPage{
+			sitePage:    sitePage{Title: "Milestone: " + name, Root: "../"},
+			Description: m.Description,
+			Details:     strings.TrimPrefix(details, ", "),
+			Tickets:     planned,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Tickets and the search index
+	var search []siteSearchEntry
+	for _, t := range tickets {
+		href := "tickets/" + strconv.Itoa(t.ID) + ".html"
+		page := siteTicketPage{
+			sitePage:      sitePage{Title: strconv.Itoa(t.ID) + ": " + t.Title, Root: "../"},
+			Ticket:        t,
+			History:       history[t.ID],
+			LabelHrefs:    labelHrefs,
+			MilestoneHref: milestoneHrefs[t.Milestone],
+			Links:         template.HTML(linkTreeHTML(LinkTree(t, tickets))),
+			Checklist:     template.HTML(checklistHTML(t)),
+		}
+		err := render(href, "ticket", page)
+		if err != nil {
+			return nil, err
+		}
+
+		text := []string{strconv.Itoa(t.ID), t.Title, t.Description, t.Status, t.Milestone}
+		text = append(text, t.Labels...)
+		text = append(text, t.Assignees...)
+		for _, comment := range t.Comments {
+			text = append(text, comment.Body)
+		}
+		search = append(search, siteSearchEntry{ID: t.ID, Title: t.Title, Status: t.Status, Href: href, Text: strings.ToLower(strings.Join(text, "\n"))})
+	}
+	searchIndex, err := json.Marshal(search)
+	if err != nil {
+		return nil, err
+	}
+	// The index is a script rather than JSON so the search page works when
+	// opened from the file system, where browsers refuse to fetch files
+	files["search-index.js"] = []byte("var giticketSearchIndex = " + string(searchIndex) + ";\n")
+
+	err = render("index.html", "index", index)
+	if err != nil {
+		return nil, err
+	}
+	err = render("search.html", "search", sitePage{Title: "Search"})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// siteSlugs takes names of filters, labels, or milestones and returns a file
+// name for each one, see slugify. Names that would have the same file name
+// have "-2", "-3" and so on added in the order the names sort in.
+func siteSlugs(names []string) map[string]string {
+	sort.Strings(names)
+	slugs := make(map[string]string)
+	used := make(map[string]bool)
+	for _, name := range names {
+		base := slugify(name)
+		if base == "" {
+			base = "unnamed"
+		}
+		slug := base
+		for i := 2; used[slug]; i++ {
+			slug = base + "-" + strconv.Itoa(i)
+		}
+		used[slug] = true
+		slugs[name] = slug
+	}
+	return slugs
+}
+
+// prepareSiteDir creates outDir if it doesn't exist, or removes the files of
+// the site exported to it before. Returns an error if outDir holds anything
+// else, so nothing but an exported site is ever removed.
+func prepareSiteDir(outDir string, debugFlag bool) error {
+	entries, err := os.ReadDir(outDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(outDir, 0755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err = os.Stat(filepath.Join(outDir, siteMarker))
+	if err != nil {
+		return errors.New(outDir + " isn't empty and wasn't written by export-site, choose another directory")
+	}
+	for _, name := range siteOutputs {
+		debug.DebugMessage(debugFlag, "Removing "+filepath.Join(outDir, name)+" from the previous export")
+		err = os.RemoveAll(filepath.Join(outDir, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// siteTime formats a unix timestamp for the exported site, in UTC so the site
+// is the same wherever it is exported
+func siteTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+// siteTemplates are the pages of the exported site
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"markdown": func(s string) template.HTML { return template.HTML(RenderMarkdownHTML(s)) },
+	"time":     siteTime,
+	"due":      formatDue,
+	"join":     strings.Join,
+	"short":    shortOID,
+	"work":     WorkSummary,
+	"summary":  func(message string) string { summary, _, _ := strings.Cut(message, "\n"); return summary },
+}).Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">Tickets</a> <a href="{{.Root}}search.html">Search</a></nav>
+<main>
+<h1>{{.Title}}</h1>
+{{- end}}
+
+{{- define "footer"}}
+</main>
+</body>
+</html>
+{{end}}
+
+{{- define "tickets"}}
+{{- if .Tickets}}
+<table>
+<thead>
+<tr><th>ID</th><th>Title</th><th>Status</th><th>Priority</th><th>Severity</th><th>Labels</th><th>Assignees</th></tr>
+</thead>
+<tbody>
+{{- range .Tickets}}
+<tr><td>{{.ID}}</td><td><a href="{{$.Root}}tickets/{{.ID}}.html">{{.Title}}</a></td><td>{{.Status}}</td><td>{{.Priority}}</td><td>{{.Severity}}</td><td>{{join .Labels ", "}}</td><td>{{join .Assignees ", "}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No tickets.</p>
+{{- end}}
+{{- end}}
+
+{{- define "links"}}
+{{- if .}}
+<ul>
+{{- range .}}
+<li><a href="{{.Href}}">{{.Name}}</a> ({{.Count}})</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>None.</p>
+{{- end}}
+{{- end}}
+
+{{- define "index"}}
+{{- template "header" .}}
+<h2>Filters</h2>
+{{- template "links" .Filters}}
+<h2>Labels</h2>
+{{- template "links" .Labels}}
+<h2>Milestones</h2>
+{{- template "links" .Milestones}}
+<h2>All tickets</h2>
+{{- template "tickets" .}}
+{{- template "footer"}}
+{{- end}}
+
+{{- define "list"}}
+{{- template "header" .}}
+{{- with .Query}}
+<p>Tickets matching <code>{{.}}</code></p>
+{{- end}}
+{{- with .Details}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Description}}
+<section class="description">
+{{markdown .}}</section>
+{{- end}}
+{{- template "tickets" .}}
+{{- template "footer"}}
+{{- end}}
+
+{{- define "ticket"}}
+{{- template "header" .}}
+{{- $root := .Root}}
+{{- $labelHrefs := .LabelHrefs}}
+{{- with .Ticket}}
+<dl>
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>Priority</dt><dd>{{.Priority}}</dd>
+<dt>Severity</dt><dd>{{.Severity}}</dd>
+<dt>Labels</dt><dd>{{range $i, $label := .Labels}}{{if $i}}, {{end}}<a href="{{$root}}{{index $labelHrefs $label}}">{{$label}}</a>{{end}}</dd>
+<dt>Assignees</dt><dd>{{join .Assignees ", "}}</dd>
+<dt>Milestone</dt><dd>{{if .Milestone}}<a href="{{$root}}{{$.MilestoneHref}}">{{.Milestone}}</a>{{end}}</dd>
+<dt>Due</dt><dd>{{if .Due}}{{due .Due}}{{end}}</dd>
+<dt>Created</dt><dd>{{time .Created}}</dd>
+</dl>
+{{- with .Description}}
+<section class="description">
+{{markdown .}}</section>
+{{- end}}
+{{- if .Checklist}}
+<h2>Checklist</h2>
+{{$.Checklist}}
+{{- end}}
+{{- if or .WorkLog .Estimate}}
+<h2>Time</h2>
+<p>{{work .}}</p>
+{{- end}}
+{{- with .Commits}}
+<h2>Commits</h2>
+<ul>
+{{- range .}}
+<li><code>{{short .OID}}</code> {{if .Closes}}fixes{{else}}refs{{end}}: {{.Summary}} ({{.Author}}, {{time .Created}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .CodeRefs}}
+<h2>Code</h2>
+<ul>
+{{- range .}}
+<li><code>{{.Path}}:{{.StartLine}}-{{.EndLine}}</code> at <code>{{short .Commit}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Links}}
+<h2>Links</h2>
+{{$.Links}}
+{{- end}}
+{{- if .Comments}}
+<h2>Comments</h2>
+{{- $id := .ID}}
+{{- range .Comments}}
+<section class="comment" id="comment-{{$id}}-{{.ID}}">
+<h3>{{$id}}-{{.ID}}: {{.Author}}, {{time .Created}}</h3>
+{{markdown .Body}}</section>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .History}}
+<h2>History</h2>
+<table>
+<thead>
+<tr><th>Commit</th><th>Date</th><th>Author</th><th>Change</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr><td><code>{{short .Commit}}</code></td><td>{{time .When}}</td><td>{{.Author}}</td><td>{{summary .Message}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- template "footer"}}
+{{- end}}
+
+{{- define "search"}}
+{{- template "header" .}}
+<form id="search">
+<input id="query" type="search" placeholder="Search tickets" autofocus>
+</form>
+<ul id="results"></ul>
+<script src="search-index.js"></script>
+<script src="search.js"></script>
+{{- template "footer"}}
+{{- end}}
+`))
+
+// siteStyle is the stylesheet of the exported site
+const siteStyle = `body { font-family: sans-serif; max-width: 60em; margin: 0 auto; padding: 0 1em; line-height: 1.5; }
+nav { padding: 1em 0; border-bottom: 1px solid #ccc; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.25em 0.5em; border-bottom: 1px solid #eee; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.25em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+.comment { border-left: 3px solid #ccc; padding-left: 1em; margin-bottom: 1em; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+#query { width: 100%; font-size: 1.2em; }
+`
+
+// siteSearchScript searches giticketSearchIndex, from search-index.js, for
+// the tickets containing every word typed into the search page
+const siteSearchScript = `(function () {
+  var query = document.getElementById("query");
+  var results = document.getElementById("results");
+
+  function search() {
+    var words = query.value.toLowerCase().split(/\s+/).filter(function (word) { return word !== ""; });
+    results.textContent = "";
+    if (words.length === 0) {
+      return;
+    }
+    giticketSearchIndex.forEach(function (ticket) {
+      var found = words.every(function (word) { return ticket.Text.indexOf(word) !== -1; });
+      if (!found) {
+        return;
+      }
+      var link = document.createElement("a");
+      link.href = ticket.Href;
+      link.textContent = ticket.ID + ": " + ticket.Title;
+      var item = document.createElement("li");
+      item.appendChild(link);
+      item.appendChild(document.createTextNode(" (" + ticket.Status + ")"));
+      results.appendChild(item);
+    });
+    if (!results.firstChild) {
+      var none = document.createElement("li");
+      none.textContent = "No tickets found.";
+      results.appendChild(none);
+    }
+  }
+
+  document.getElementById("search").addEventListener("submit", function (event) {
+    event.preventDefault();
+  });
+  query.addEventListener("input", search);
+})();
+`
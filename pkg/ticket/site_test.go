@@ -0,0 +1,196 @@
+package ticket
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestSiteSlugs(t *testing.T) {
+	got := siteSlugs([]string{"in progress", "Bug", "bug", "!!!", "v1.0"})
+	want := map[string]string{
+		"!!!":         "unnamed",
+		"Bug":         "bug",
+		"bug":         "bug-2",
+		"in progress": "in-progress",
+		"v1.0":        "v1-0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("siteSlugs() = %v, want %v", got, want)
+	}
+}
+
+func TestSiteFiles(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 2, Title: "Fix <the> build", Status: "closed", Labels: []string{"ci"}, Milestone: "v2"},
+		{ID: 1, Title: "Reverse the polarity", Status: "new", Labels: []string{"tardis", "ci"}, Description: "Use the *sonic* screwdriver",
+			Comments: []Comment{{ID: 1, Author: "John Smith <jsmith@example.com>", Body: "Polarity **reversed**"}}},
+	}
+	filters := map[string]Filter{"Open tickets": {Name: "Open tickets", Filter: `.[] | select(.Status != "closed")`}}
+	milestones := []Milestone{{Name: "v1", Description: "The *first* release", State: MilestoneOpen}}
+	history := map[int][]HistoryEntry{1: {{Commit: "0123456789abcdef", Author: "John Smith <jsmith@example.com>", When: 0, Message: "Creating ticket 1__Reverse_the_polarity\n\nDetails"}}}
+
+	files, err := SiteFiles(tickets, filters, milestones, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	wantNames := []string{
+		siteMarker, "filters/open-tickets.html", "index.html", "labels/ci.html", "labels/tardis.html",
+		"milestones/v1.html", "milestones/v2.html", "search-index.js", "search.html", "search.js",
+		"style.css", "tickets/1.html", "tickets/2.html",
+	}
+	for _, name := range wantNames {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s, got %v", name, names)
+		}
+	}
+	if len(files) != len(wantNames) {
+		t.Errorf("got %d files %v, want %d", len(files), names, len(wantNames))
+	}
+
+	contains := map[string][]string{
+		"index.html":                {`<a href="filters/open-tickets.html">Open tickets</a> (1)`, `<a href="labels/ci.html">ci</a> (2)`, `<a href="tickets/2.html">Fix &lt;the&gt; build</a>`},
+		"filters/open-tickets.html": {`<a href="../tickets/1.html">Reverse the polarity</a>`},
+		"milestones/v1.html":        {"<p>open</p>", "The <em>first</em> release", "<p>No tickets.</p>"},
+		"milestones/v2.html":        {`<a href="../tickets/2.html">`},
+		"tickets/1.html": {
+			"Use the <em>sonic</em> screwdriver", "Polarity <strong>reversed</strong>",
+			`<a href="../labels/tardis.html">tardis</a>, <a href="../labels/ci.html">ci</a>`,
+			"<td><code>0123456</code></td><td>1970-01-01 00:00:00 UTC</td><td>John Smith &lt;jsmith@example.com&gt;</td><td>Creating ticket 1__Reverse_the_polarity</td>",
+		},
+		"tickets/2.html":  {`<a href="../milestones/v2.html">v2</a>`},
+		"search-index.js": {`"Href":"tickets/1.html"`, "polarity **reversed**"},
+	}
+	for name, wants := range contains {
+		for _, want := range wants {
+			if !strings.Contains(string(files[name]), want) {
+				t.Errorf("%s doesn't contain %q:\n%s", name, want, files[name])
+			}
+		}
+	}
+	if strings.Contains(string(files["filters/open-tickets.html"]), "tickets/2.html") {
+		t.Error("the filter page lists a closed ticket")
+	}
+
+	// The same tickets always produce the same site
+	again, err := SiteFiles(tickets, filters, milestones, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, again) {
+		t.Error("exporting the same tickets twice produced different files")
+	}
+
+	_, err = SiteFiles(tickets, map[string]Filter{"broken": {Filter: "map(("}}, nil, nil)
+	if err == nil {
+		t.Error("expected an error for an invalid filter")
+	}
+}
+
+func TestPrepareSiteDir(t *testing.T) {
+	dir := t.TempDir()
+
+	// A missing directory is created
+	outDir := filepath.Join(dir, "site")
+	err := prepareSiteDir(outDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(outDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory holding something else is refused
+	err = os.WriteFile(filepath.Join(outDir, "notes.txt"), []byte("notes"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = prepareSiteDir(outDir, false)
+	if err == nil {
+		t.Fatal("expected an error for a directory that isn't an exported site")
+	}
+
+	// An exported site has its files removed, but not anything else
+	for _, name := range []string{siteMarker, "index.html", "tickets/9.html"} {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(outDir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(outDir, name), []byte("old"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = prepareSiteDir(outDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "notes.txt" {
+		t.Errorf("unexpected files left after removing the old site: %v", entries)
+	}
+}
+
+func TestHandleExportSite(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleStatus("in progress", 1, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := TicketHistory(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history[1]) != 2 {
+		t.Fatalf("expected ticket 1 to have been changed by 2 commits, got %+v", history[1])
+	}
+
+	err = HandleExportSite(&bytes.Buffer{}, common.BranchName, "site", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(filepath.Join("site", "tickets", "1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(first), "<h2>History</h2>") {
+		t.Errorf("the ticket page has no history:\n%s", first)
+	}
+
+	// Exporting again replaces the site with the same files
+	err = HandleExportSite(&bytes.Buffer{}, common.BranchName, "site", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(filepath.Join("site", "tickets", "1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("exporting the same tickets twice produced different pages")
+	}
+}
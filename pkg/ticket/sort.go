@@ -0,0 +1,200 @@
+package ticket
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ListOptions holds the sorting, grouping, paging and output options used when
+// listing tickets. The zero value lists every ticket sorted by ID, without
+// grouping, as a table of DefaultListColumns.
+type ListOptions struct {
+	// SortBy is a comma separated list of sort keys, a key prefixed with '-'
+	// is sorted in descending order. eg: "priority,-created"
+	SortBy string
+	// GroupBy is the name of the attribute to group tickets by, one of
+	// status, label, severity, assignee, or milestone. Empty means no grouping.
+	GroupBy string
+	// Mine limits the tickets to those assigned to the current user
+	Mine bool
+	// Unblocked limits the tickets to those not blocked by an open ticket
+	Unblocked bool
+	// Limit is the maximum number of tickets to return, 0 means no limit
+	Limit int
+	// Offset is the number of tickets to skip before returning any
+	Offset int
+	// Columns are the names of the columns to print, see ParseListColumns
+	Columns []string
+	// Output is the output format, one of ListOutputFormats
+	Output string
+	// Format is a --format template used instead of Output and Columns, or
+	// the name of a stored format prefixed with '@', see ParseFormat
+	Format string
+}
+
+// TicketGroup is a named group of tickets, as returned by GroupTickets
+type TicketGroup struct {
+	Name    string
+	Tickets []Ticket
+}
+
+// sortKeys maps the name of each key accepted by SortTickets to a function
+// comparing two tickets by that key in ascending order
+var sortKeys = map[string]func(a, b Ticket) int{
+	"id":       func(a, b Ticket) int { return cmp.Compare(a.ID, b.ID) },
+	"title":    func(a, b Ticket) int { return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) },
+	"priority": func(a, b Ticket) int { return cmp.Compare(a.Priority, b.Priority) },
+	"severity": func(a, b Ticket) int { return cmp.Compare(a.Severity, b.Severity) },
+	"status":   func(a, b Ticket) int { return cmp.Compare(a.Status, b.Status) },
+	"created":  func(a, b Ticket) int { return cmp.Compare(a.Created, b.Created) },
+	// Tickets without a due date sort after those with one
+	"due": func(a, b Ticket) int {
+		if (a.Due == 0) != (b.Due == 0) {
+			return cmp.Compare(b.Due, a.Due)
+		}
+		return cmp.Compare(a.Due, b.Due)
+	},
+}
+
+// groupKeys maps the name of each attribute accepted by GroupTickets to a
+// function returning the names of the groups a ticket belongs to
+var groupKeys = map[string]func(t Ticket) []string{
+	"status":   func(t Ticket) []string { return []string{t.Status} },
+	"severity": func(t Ticket) []string { return []string{strconv.Itoa(t.Severity)} },
+	"label": func(t Ticket) []string {
+		if len(t.Labels) == 0 {
+			return []string{"(none)"}
+		}
+		return t.Labels
+	},
+	"assignee": func(t Ticket) []string {
+		if len(t.Assignees) == 0 {
+			return []string{"(unassigned)"}
+		}
+		return t.Assignees
+	},
+	"milestone": func(t Ticket) []string {
+		if t.Milestone == "" {
+			return []string{"(none)"}
+		}
+		return []string{t.Milestone}
+	},
+}
+
+// SortTickets takes a list of tickets and a comma separated list of sort keys
+// and sorts the tickets in place. Keys prefixed with '-' sort in descending
+// order, later keys are used to break ties in earlier keys, and ties left over
+// are broken by ticket ID. An empty sortBy sorts by ticket ID. Returns an error
+// if a key is not recognized.
+func SortTickets(tickets []Ticket, sortBy string) error {
+	var comparisons []func(a, b Ticket) int
+	for _, key := range strings.Split(sortBy, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+
+		descending := strings.HasPrefix(key, "-")
+		key = strings.TrimLeft(key, "-+")
+		compare, ok := sortKeys[key]
+		if !ok {
+			return fmt.Errorf("unknown sort key '%s', valid keys are: %s", key, strings.Join(validKeys(sortKeys), ", "))
+		}
+		if descending {
+			ascending := compare
+			compare = func(a, b Ticket) int { return ascending(b, a) }
+		}
+		comparisons = append(comparisons, compare)
+	}
+	comparisons = append(comparisons, sortKeys["id"])
+
+	slices.SortStableFunc(tickets, func(a, b Ticket) int {
+		for _, compare := range comparisons {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// GroupTickets takes a list of tickets and the name of the attribute to group
+// them by, and returns the groups sorted by name. The order of tickets within
+// each group is preserved. A ticket with more than one label appears in the
+// group for each of its labels. Returns an error if groupBy is not recognized.
+func GroupTickets(tickets []Ticket, groupBy string) ([]TicketGroup, error) {
+	groupsOf, ok := groupKeys[strings.ToLower(groupBy)]
+	if !ok {
+		return nil, fmt.Errorf("unknown group-by attribute '%s', valid attributes are: %s", groupBy, strings.Join(validKeys(groupKeys), ", "))
+	}
+
+	var groups []TicketGroup
+	index := make(map[string]int)
+	for _, t := range tickets {
+		for _, name := range groupsOf(t) {
+			i, ok := index[name]
+			if !ok {
+				i = len(groups)
+				index[name] = i
+				groups = append(groups, TicketGroup{Name: name})
+			}
+			groups[i].Tickets = append(groups[i].Tickets, t)
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b TicketGroup) int {
+		// Sort numeric group names such as severity numerically
+		x, errA := strconv.Atoi(a.Name)
+		y, errB := strconv.Atoi(b.Name)
+		if errA == nil && errB == nil {
+			return cmp.Compare(x, y)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return groups, nil
+}
+
+// PageTickets takes a list of tickets, a limit, and an offset, and returns at
+// most limit tickets starting offset tickets into the list. A limit of 0 means
+// no limit.
+func PageTickets(tickets []Ticket, limit int, offset int) []Ticket {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(tickets) {
+		return []Ticket{}
+	}
+	tickets = tickets[offset:]
+	if limit > 0 && limit < len(tickets) {
+		tickets = tickets[:limit]
+	}
+	return tickets
+}
+
+// ApplyListOptions takes a list of tickets and ListOptions, and returns the
+// sorted page of tickets described by the options. Grouping is left to the
+// caller, see GroupTickets, as is resolving the current user for Mine, see
+// FilterTicketsByAssignee, and finding the blockers for Unblocked, see
+// FilterUnblockedTickets.
+func ApplyListOptions(tickets []Ticket, listOptions ListOptions) ([]Ticket, error) {
+	sorted := slices.Clone(tickets)
+	err := SortTickets(sorted, listOptions.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	return PageTickets(sorted, listOptions.Limit, listOptions.Offset), nil
+}
+
+// validKeys returns the sorted keys of m, for use in error messages
+func validKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package ticket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 10, Priority: 2, Created: 300},
+		{ID: 2, Priority: 1, Created: 100},
+		{ID: 1, Priority: 2, Created: 200},
+		{ID: 3, Priority: 1, Created: 400},
+	}
+
+	testCases := []struct {
+		sortBy      string
+		expectedIDs []int
+		expectErr   bool
+	}{
+		{ // The default order is by ID, numerically
+			sortBy:      "",
+			expectedIDs: []int{1, 2, 3, 10},
+		},
+		{
+			sortBy:      "-id",
+			expectedIDs: []int{10, 3, 2, 1},
+		},
+		{
+			sortBy:      "priority,-created",
+			expectedIDs: []int{3, 2, 10, 1},
+		},
+		{ // Ties are broken by ID
+			sortBy:      "-priority",
+			expectedIDs: []int{1, 10, 2, 3},
+		},
+		{
+			sortBy:    "nonexistent",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		sorted := append([]Ticket{}, tickets...)
+		err := SortTickets(sorted, tc.sortBy)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("SortTickets(%q) expected an error but got none", tc.sortBy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []int
+		for _, ticket := range sorted {
+			ids = append(ids, ticket.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.expectedIDs) {
+			t.Errorf("SortTickets(%q) = %v, want %v", tc.sortBy, ids, tc.expectedIDs)
+		}
+	}
+}
+
+func TestGroupTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Status: "new", Severity: 10, Labels: []string{"bugfix", "ux"}},
+		{ID: 2, Status: "closed", Severity: 2},
+		{ID: 3, Status: "new", Severity: 2, Labels: []string{"ux"}},
+	}
+
+	testCases := []struct {
+		groupBy  string
+		expected map[string][]int
+		order    []string
+	}{
+		{
+			groupBy:  "status",
+			expected: map[string][]int{"closed": {2}, "new": {1, 3}},
+			order:    []string{"closed", "new"},
+		},
+		{
+			groupBy:  "label",
+			expected: map[string][]int{"(none)": {2}, "bugfix": {1}, "ux": {1, 3}},
+			order:    []string{"(none)", "bugfix", "ux"},
+		},
+		{ // Severity groups sort numerically
+			groupBy:  "severity",
+			expected: map[string][]int{"2": {2, 3}, "10": {1}},
+			order:    []string{"2", "10"},
+		},
+	}
+
+	for _, tc := range testCases {
+		groups, err := GroupTickets(tickets, tc.groupBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var order []string
+		for _, group := range groups {
+			order = append(order, group.Name)
+			var ids []int
+			for _, ticket := range group.Tickets {
+				ids = append(ids, ticket.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expected[group.Name]) {
+				t.Errorf("GroupTickets(%q) group %q = %v, want %v", tc.groupBy, group.Name, ids, tc.expected[group.Name])
+			}
+		}
+		if !reflect.DeepEqual(order, tc.order) {
+			t.Errorf("GroupTickets(%q) returned groups %v, want %v", tc.groupBy, order, tc.order)
+		}
+	}
+
+	_, err := GroupTickets(tickets, "nonexistent")
+	if err == nil {
+		t.Error("GroupTickets() expected an error for an unknown attribute but got none")
+	}
+}
+
+func TestPageTickets(t *testing.T) {
+	tickets := []Ticket{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+
+	testCases := []struct {
+		limit       int
+		offset      int
+		expectedIDs []int
+	}{
+		{limit: 0, offset: 0, expectedIDs: []int{1, 2, 3, 4, 5}},
+		{limit: 2, offset: 0, expectedIDs: []int{1, 2}},
+		{limit: 2, offset: 2, expectedIDs: []int{3, 4}},
+		{limit: 2, offset: 4, expectedIDs: []int{5}},
+		{limit: 0, offset: 3, expectedIDs: []int{4, 5}},
+		{limit: 2, offset: 10, expectedIDs: nil},
+	}
+
+	for _, tc := range testCases {
+		var ids []int
+		for _, ticket := range PageTickets(tickets, tc.limit, tc.offset) {
+			ids = append(ids, ticket.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.expectedIDs) {
+			t.Errorf("PageTickets(limit=%d, offset=%d) = %v, want %v", tc.limit, tc.offset, ids, tc.expectedIDs)
+		}
+	}
+}
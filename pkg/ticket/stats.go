@@ -0,0 +1,397 @@
+package ticket
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/debug"
+)
+
+// StatsFormats are the output formats accepted by WriteStats
+var StatsFormats = []string{"table", "json", "csv"}
+
+// StatsReports are the parts of the stats that can be written on their own
+var StatsReports = []string{"counts", "times", "burndown"}
+
+// statsGroups are the ways tickets are counted, in the order they're written
+var statsGroups = []string{"status", "label", "priority", "assignee"}
+
+// noneName is the name tickets without a label or assignee are counted under
+const noneName = "(none)"
+
+// Stats is the counts, cycle and lead times, and burndown of a set of tickets
+type Stats struct {
+	Counts    []StatsCount    `json:"counts,omitempty"`
+	CycleTime *Distribution   `json:"cycle_time,omitempty"`
+	LeadTime  *Distribution   `json:"lead_time,omitempty"`
+	Burndown  []BurndownPoint `json:"burndown,omitempty"`
+}
+
+// StatsCount is the number of open and closed tickets with one status, label,
+// priority, or assignee
+type StatsCount struct {
+	// Group is what the tickets are counted by, one of status, label,
+	// priority, or assignee
+	Group  string `json:"group"`
+	Name   string `json:"name"`
+	Open   int    `json:"open"`
+	Closed int    `json:"closed"`
+}
+
+// Distribution summarizes a set of durations, in seconds
+type Distribution struct {
+	Count  int   `json:"count"`
+	Min    int64 `json:"min"`
+	Median int64 `json:"median"`
+	P90    int64 `json:"p90"`
+	Mean   int64 `json:"mean"`
+	Max    int64 `json:"max"`
+}
+
+// BurndownPoint is the number of open and closed tickets at the end of a day
+type BurndownPoint struct {
+	// Date is the day, eg: 2026-10-19
+	Date   string `json:"date"`
+	Open   int    `json:"open"`
+	Closed int    `json:"closed"`
+}
+
+// HandleStats takes a writer, a branch name, the name of a filter or "" for
+// every ticket, the unix time the burndown starts at or 0 for the day of the
+// first ticket, the report to write from StatsReports or "" for all of them,
+// an output format from StatsFormats, and a debug flag. It writes the stats of
+// the tickets on branchName, see ComputeStats. Returns an error if there is
+// one.
+func HandleStats(w io.Writer, branchName string, filterName string, since int64, report string, outputFormat string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	history, err := TicketHistory(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	if filterName != "" {
+		filtered, err := FilterTickets(tickets, filterName, debugFlag)
+		if err != nil {
+			return err
+		}
+		tickets = *filtered
+		// Only replay the history of the tickets that matched
+		matched := make(map[int][]HistoryEntry)
+		for _, t := range tickets {
+			matched[t.ID] = history[t.ID]
+		}
+		history = matched
+	}
+
+	settings, err := LoadSettings(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	startStatus := settings.StartStatus
+	if startStatus == "" {
+		startStatus = DefaultStartStatus
+	}
+
+	stats := ComputeStats(tickets, history, startStatus, since, time.Now())
+	return WriteStats(w, stats, report, outputFormat)
+}
+
+// ComputeStats takes a list of tickets, their history from TicketHistory, the
+// status work on a ticket starts in, the unix time the burndown starts at or
+// 0 for the day of the first commit in history, and the current time, and
+// returns their stats.
+//
+// Lead time is from a closed ticket's creation until it was last closed, and
+// cycle time is from when it first moved to startStatus until then. Closed
+// tickets that never moved to startStatus have no cycle time. The burndown
+// replays history to count the tickets that were open and closed at the end of
+// each day, up to and including now's day. Deleted tickets aren't counted
+// from the day they were deleted.
+func ComputeStats(tickets []Ticket, history map[int][]HistoryEntry, startStatus string, since int64, now time.Time) Stats {
+	var stats Stats
+
+	counts := make(map[string]map[string]*StatsCount)
+	for _, group := range statsGroups {
+		counts[group] = make(map[string]*StatsCount)
+	}
+	count := func(group string, name string, closed bool) {
+		c, ok := counts[group][name]
+		if !ok {
+			c = &StatsCount{Group: group, Name: name}
+			counts[group][name] = c
+		}
+		if closed {
+			c.Closed++
+		} else {
+			c.Open++
+		}
+	}
+
+	var cycleTimes, leadTimes []int64
+	for _, t := range tickets {
+		closed := IsClosed(t)
+		count("status", t.Status, closed)
+		count("priority", strconv.Itoa(t.Priority), closed)
+		if len(t.Labels) == 0 {
+			count("label", noneName, closed)
+		}
+		for _, label := range t.Labels {
+			count("label", label, closed)
+		}
+		if len(t.Assignees) == 0 {
+			count("assignee", noneName, closed)
+		}
+		for _, assignee := range t.Assignees {
+			count("assignee", assignee, closed)
+		}
+
+		if !closed {
+			continue
+		}
+		var started, closedAt int64
+		wasClosed := false
+		for _, entry := range history[t.ID] {
+			entryClosed := !entry.Deleted && IsClosed(Ticket{Status: entry.Status})
+			if entry.Status == startStatus && started == 0 {
+				started = entry.When
+			}
+			if entryClosed && !wasClosed {
+				closedAt = entry.When
+			}
+			wasClosed = entryClosed
+		}
+		if closedAt == 0 {
+			continue
+		}
+		created := t.Created
+		if created == 0 && len(history[t.ID]) > 0 {
+			created = history[t.ID][0].When
+		}
+		leadTimes = append(leadTimes, max(closedAt-created, 0))
+		if started != 0 && started <= closedAt {
+			cycleTimes = append(cycleTimes, closedAt-started)
+		}
+	}
+
+	for _, group := range statsGroups {
+		var groupCounts []StatsCount
+		for _, c := range counts[group] {
+			groupCounts = append(groupCounts, *c)
+		}
+		slices.SortFunc(groupCounts, func(a, b StatsCount) int { return compareStatsNames(a.Name, b.Name) })
+		stats.Counts = append(stats.Counts, groupCounts...)
+	}
+	stats.CycleTime = distribution(cycleTimes)
+	stats.LeadTime = distribution(leadTimes)
+	stats.Burndown = burndown(history, since, now)
+	return stats
+}
+
+// compareStatsNames orders the names tickets are counted under, numerically
+// if both are numbers, with "(none)" last
+func compareStatsNames(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == noneName:
+		return 1
+	case b == noneName:
+		return -1
+	}
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return cmp.Compare(aNumber, bNumber)
+	}
+	return cmp.Compare(a, b)
+}
+
+// distribution returns the distribution of a list of durations, with a Count
+// of 0 if there are none
+func distribution(durations []int64) *Distribution {
+	d := &Distribution{Count: len(durations)}
+	if len(durations) == 0 {
+		return d
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	var sum int64
+	for _, duration := range sorted {
+		sum += duration
+	}
+	middle := len(sorted) / 2
+	d.Median = sorted[middle]
+	if len(sorted)%2 == 0 {
+		d.Median = (sorted[middle-1] + sorted[middle]) / 2
+	}
+	// The 90th percentile by the nearest rank method
+	d.P90 = sorted[int(math.Ceil(0.9*float64(len(sorted))))-1]
+	d.Min = sorted[0]
+	d.Max = sorted[len(sorted)-1]
+	d.Mean = sum / int64(len(sorted))
+	return d
+}
+
+// burndown replays history to count the open and closed tickets at the end of
+// each day from since's day, or the day of the first entry in history if since
+// is 0, to now's day
+func burndown(history map[int][]HistoryEntry, since int64, now time.Time) []BurndownPoint {
+	if since == 0 {
+		for _, entries := range history {
+			if len(entries) > 0 && (since == 0 || entries[0].When < since) {
+				since = entries[0].When
+			}
+		}
+		if since == 0 {
+			return nil
+		}
+	}
+
+	var points []BurndownPoint
+	day := time.Unix(since, 0).In(now.Location())
+	for dayEnd := endOfDay(day); dayEnd <= endOfDay(now); dayEnd = endOfDay(day) {
+		point := BurndownPoint{Date: day.Format("2006-01-02")}
+		for _, entries := range history {
+			// The ticket as it was after the last commit of the day
+			var last *HistoryEntry
+			for i := range entries {
+				if entries[i].When > dayEnd {
+					break
+				}
+				last = &entries[i]
+			}
+			switch {
+			case last == nil || last.Deleted:
+			case IsClosed(Ticket{Status: last.Status}):
+				point.Closed++
+			default:
+				point.Open++
+			}
+		}
+		points = append(points, point)
+		year, month, date := day.Date()
+		day = time.Date(year, month, date+1, 0, 0, 0, 0, day.Location())
+	}
+	return points
+}
+
+// WriteStats writes the report of stats from StatsReports, or all of them if
+// report is "", to w as a table, JSON, or CSV. In CSV, each report has its own
+// header, and reports are separated by an empty line. Durations are in seconds
+// in JSON and CSV. Returns an error if there is one.
+func WriteStats(w io.Writer, stats Stats, report string, outputFormat string) error {
+	if report != "" && !slices.Contains(StatsReports, report) {
+		return fmt.Errorf("unknown report '%s', valid reports are: %s", report, strings.Join(StatsReports, ", "))
+	}
+	if !slices.Contains(StatsFormats, outputFormat) {
+		return fmt.Errorf("unknown output format '%s', valid formats are: %s", outputFormat, strings.Join(StatsFormats, ", "))
+	}
+	if report != "" {
+		if report != "counts" {
+			stats.Counts = nil
+		}
+		if report != "times" {
+			stats.CycleTime = nil
+			stats.LeadTime = nil
+		}
+		if report != "burndown" {
+			stats.Burndown = nil
+		}
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	var tables [][][]string
+	if stats.Counts != nil {
+		rows := [][]string{{"Group", "Name", "Open", "Closed"}}
+		for _, c := range stats.Counts {
+			rows = append(rows, []string{c.Group, c.Name, strconv.Itoa(c.Open), strconv.Itoa(c.Closed)})
+		}
+		tables = append(tables, rows)
+	}
+	if stats.CycleTime != nil {
+		rows := [][]string{{"Time", "Count", "Min", "Median", "P90", "Mean", "Max"}}
+		for _, measure := range []struct {
+			name string
+			d    *Distribution
+		}{{"cycle", stats.CycleTime}, {"lead", stats.LeadTime}} {
+			row := []string{measure.name, strconv.Itoa(measure.d.Count)}
+			for _, seconds := range []int64{measure.d.Min, measure.d.Median, measure.d.P90, measure.d.Mean, measure.d.Max} {
+				switch {
+				case outputFormat == "csv":
+					row = append(row, strconv.FormatInt(seconds, 10))
+				case measure.d.Count == 0:
+					row = append(row, "-")
+				default:
+					row = append(row, formatElapsed(seconds))
+				}
+			}
+			rows = append(rows, row)
+		}
+		tables = append(tables, rows)
+	}
+	if stats.Burndown != nil {
+		rows := [][]string{{"Date", "Open", "Closed"}}
+		for _, point := range stats.Burndown {
+			rows = append(rows, []string{point.Date, strconv.Itoa(point.Open), strconv.Itoa(point.Closed)})
+		}
+		tables = append(tables, rows)
+	}
+
+	for i, rows := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if outputFormat == "table" {
+			writeTable(w, rows)
+			continue
+		}
+		writer := csv.NewWriter(w)
+		// CSV headers are lowercase, like the JSON keys
+		header := make([]string, len(rows[0]))
+		for j, cell := range rows[0] {
+			header[j] = strings.ToLower(cell)
+		}
+		err := writer.WriteAll(append([][]string{header}, rows[1:]...))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatElapsed formats a number of seconds as days and hours, such as
+// "3d4h" or "2d", or as hours and minutes if it's less than a day
+func formatElapsed(seconds int64) string {
+	days := seconds / (24 * 60 * 60)
+	if days == 0 {
+		return formatWork(seconds)
+	}
+	hours := seconds / (60 * 60) % 24
+	if hours == 0 {
+		return strconv.FormatInt(days, 10) + "d"
+	}
+	return strconv.FormatInt(days, 10) + "d" + strconv.FormatInt(hours, 10) + "h"
+}
@@ -0,0 +1,151 @@
+package ticket
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestComputeStats(t *testing.T) {
+	day := func(d int, hour int) int64 {
+		return time.Date(2026, 10, d, hour, 0, 0, 0, time.Local).Unix()
+	}
+	tickets := []Ticket{
+		{ID: 1, Status: "closed", Priority: 2, Labels: []string{"bug"}, Assignees: []string{"alice"}, Created: day(1, 9)},
+		{ID: 2, Status: "in progress", Priority: 10, Labels: []string{"bug", "ux"}, Created: day(1, 10)},
+		{ID: 3, Status: "done", Priority: 2, Created: day(2, 9)},
+	}
+	history := map[int][]HistoryEntry{
+		1: {{When: day(1, 9), Status: "new"}, {When: day(2, 9), Status: "in progress"}, {When: day(3, 9), Status: "closed"}},
+		2: {{When: day(1, 10), Status: "new"}, {When: day(3, 10), Status: "in progress"}},
+		3: {{When: day(2, 9), Status: "new"}, {When: day(2, 21), Status: "done"}},
+		4: {{When: day(1, 11), Status: "new"}, {When: day(2, 11), Deleted: true}},
+	}
+
+	stats := ComputeStats(tickets, history, "in progress", 0, time.Date(2026, 10, 4, 12, 0, 0, 0, time.Local))
+
+	wantCounts := []StatsCount{
+		{Group: "status", Name: "closed", Closed: 1},
+		{Group: "status", Name: "done", Closed: 1},
+		{Group: "status", Name: "in progress", Open: 1},
+		{Group: "label", Name: "bug", Open: 1, Closed: 1},
+		{Group: "label", Name: "ux", Open: 1},
+		{Group: "label", Name: "(none)", Closed: 1},
+		{Group: "priority", Name: "2", Closed: 2},
+		{Group: "priority", Name: "10", Open: 1},
+		{Group: "assignee", Name: "alice", Closed: 1},
+		{Group: "assignee", Name: "(none)", Open: 1, Closed: 1},
+	}
+	if !reflect.DeepEqual(stats.Counts, wantCounts) {
+		t.Errorf("Counts = %+v, want %+v", stats.Counts, wantCounts)
+	}
+
+	hour := int64(60 * 60)
+	wantCycle := &Distribution{Count: 1, Min: 24 * hour, Median: 24 * hour, P90: 24 * hour, Mean: 24 * hour, Max: 24 * hour}
+	if !reflect.DeepEqual(stats.CycleTime, wantCycle) {
+		t.Errorf("CycleTime = %+v, want %+v", stats.CycleTime, wantCycle)
+	}
+	wantLead := &Distribution{Count: 2, Min: 12 * hour, Median: 30 * hour, P90: 48 * hour, Mean: 30 * hour, Max: 48 * hour}
+	if !reflect.DeepEqual(stats.LeadTime, wantLead) {
+		t.Errorf("LeadTime = %+v, want %+v", stats.LeadTime, wantLead)
+	}
+
+	wantBurndown := []BurndownPoint{
+		{Date: "2026-10-01", Open: 3},
+		{Date: "2026-10-02", Open: 2, Closed: 1},
+		{Date: "2026-10-03", Open: 1, Closed: 2},
+		{Date: "2026-10-04", Open: 1, Closed: 2},
+	}
+	if !reflect.DeepEqual(stats.Burndown, wantBurndown) {
+		t.Errorf("Burndown = %+v, want %+v", stats.Burndown, wantBurndown)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	d := distribution([]int64{50, 10, 40, 20, 30, 60, 70, 80, 90, 100, 110})
+	want := &Distribution{Count: 11, Min: 10, Median: 60, P90: 100, Mean: 60, Max: 110}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("distribution() = %+v, want %+v", d, want)
+	}
+	if d := distribution(nil); d.Count != 0 {
+		t.Errorf("distribution(nil) = %+v, want a Count of 0", d)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	stats := Stats{
+		Counts:    []StatsCount{{Group: "status", Name: "new", Open: 2}},
+		CycleTime: &Distribution{},
+		LeadTime:  &Distribution{Count: 1, Min: 90000, Median: 90000, P90: 90000, Mean: 90000, Max: 90000},
+		Burndown:  []BurndownPoint{{Date: "2026-10-01", Open: 2}},
+	}
+
+	var out bytes.Buffer
+	err := WriteStats(&out, stats, "", "table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"status | new  | 2    | 0", "cycle | 0     | -    | -", "lead  | 1     | 1d1h", "2026-10-01 | 2    | 0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	err = WriteStats(&out, stats, "burndown", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "date,open,closed\n2026-10-01,2,0\n" {
+		t.Errorf("burndown csv = %q", out.String())
+	}
+
+	out.Reset()
+	err = WriteStats(&out, stats, "times", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "burndown") || !strings.Contains(out.String(), `"median": 90000`) {
+		t.Errorf("times json = %s", out.String())
+	}
+
+	if err := WriteStats(&out, stats, "velocity", "table"); err == nil {
+		t.Error("WriteStats() with an unknown report didn't return an error")
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	for seconds, want := range map[int64]string{45 * 60: "45m", 5 * 60 * 60: "5h", 2 * 24 * 60 * 60: "2d", 76 * 60 * 60: "3d4h"} {
+		if got := formatElapsed(seconds); got != want {
+			t.Errorf("formatElapsed(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestHandleStats(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleStatus("closed", 1, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = HandleStats(&out, common.BranchName, "", 0, "", "json", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"name": "closed"`, `"closed": 1`, `"lead_time"`, `"date": "` + time.Now().Format("2006-01-02") + `"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("stats don't contain %s:\n%s", want, out.String())
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package ticket
 
 import (
+	"slices"
 	"strconv"
+	"strings"
 
 	git "github.com/jeffwelling/git2go/v37"
 	"github.com/jeffwelling/giticket/pkg/common"
@@ -9,6 +11,16 @@ import (
 	"github.com/jeffwelling/giticket/pkg/repo"
 )
 
+// ClosedStatuses are the statuses that mean work on a ticket has finished.
+// A ticket blocked only by closed tickets is no longer blocked.
+var ClosedStatuses = []string{"closed", "done", "resolved", "wontfix", "duplicate"}
+
+// IsClosed returns true if the ticket's status is one of ClosedStatuses,
+// ignoring case
+func IsClosed(t Ticket) bool {
+	return slices.Contains(ClosedStatuses, strings.ToLower(strings.TrimSpace(t.Status)))
+}
+
 func HandleStatus(
 	status string,
 	ticketID int,
@@ -38,7 +50,19 @@ func HandleStatus(
 	t := FilterTicketsByID(tickets, ticketID)
 
 	t.Status = status
-	err = repo.Commit(&t, thisRepo, common.BranchName, author, "Setting status of ticket "+strconv.Itoa(t.ID)+" to "+status, debugFlag)
+	commitMessage := "Setting status of ticket " + strconv.Itoa(t.ID) + " to " + status
+
+	// Keep the checklists of parent tickets in step, in the same commit
+	parents := SyncPromotedChecklistItems(t, tickets)
+	if len(parents) > 0 {
+		toCommit := []common.TicketInterface{&t}
+		for i := range parents {
+			toCommit = append(toCommit, &parents[i])
+		}
+		return repo.CommitTickets(thisRepo, common.BranchName, toCommit, commitMessage, debugFlag)
+	}
+
+	err = repo.Commit(&t, thisRepo, common.BranchName, author, commitMessage, debugFlag)
 	if err != nil {
 		return err
 	}
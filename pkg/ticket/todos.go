@@ -0,0 +1,394 @@
+package ticket
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// DefaultTodoLabel is the label given to tickets created by HandleScanTodos
+// when no other label is given
+const DefaultTodoLabel = "todo"
+
+// maxTodoTitleLength is the longest the title of a ticket created from a TODO
+// comment can be, the whole comment is kept in the description
+const maxTodoTitleLength = 72
+
+// todoPattern matches a TODO, FIXME, XXX or HACK marker at the start of a
+// comment, eg: "// TODO: handle errors", "# FIXME(jeff) slow" or "/* XXX */"
+var todoPattern = regexp.MustCompile(`(?://+|#+|/\*+|<!--|--|;+|^\s*\*)\s*(TODO|FIXME|XXX|HACK)\b(?:\([^)]*\))?:?\s*(.*)$`)
+
+// Todo is a TODO comment found in a file
+type Todo struct {
+	Path   string
+	Line   int
+	Marker string
+	Text   string
+	// Comment is the whole line the comment was found on
+	Comment string
+}
+
+// HandleScanTodos takes a writer, a branch name, a path to scan, a revision,
+// a label, a dry run flag, and a debug flag. It finds the TODO comments in the
+// files under scanPath, an empty scanPath meaning every file, and creates a
+// ticket with the label and a code reference for each one. Files are read from
+// the working tree, or from the commit rev refers to if it isn't empty. Only
+// files tracked in the current commit are read from the working tree.
+//
+// Scanning again skips comments that already have a ticket, and closes the
+// tickets of comments that were removed from the scanned files. If dryRun is
+// true the changes are written to w but not made. Every change is made in a
+// single commit.
+func HandleScanTodos(w io.Writer, branchName string, scanPath string, rev string, label string, dryRun bool, debugFlag bool) error {
+	scope, err := normalizeScanPath(scanPath)
+	if err != nil {
+		return err
+	}
+	if label == "" {
+		label = DefaultTodoLabel
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	commit, err := lookupCodeCommit(thisRepo, branchName, rev)
+	if err != nil {
+		return err
+	}
+	defer commit.Free()
+	commitOID := commit.Id().String()
+
+	todos, err := findTodos(thisRepo, commit, scope, rev == "", debugFlag)
+	if err != nil {
+		return err
+	}
+	debug.DebugMessage(debugFlag, "Found "+strconv.Itoa(len(todos))+" TODO comments")
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	toCreate, toClose := ReconcileTodos(todos, tickets, label, scope)
+	if len(toCreate) == 0 && len(toClose) == 0 {
+		fmt.Fprintln(w, "No new or removed TODO comments")
+		return nil
+	}
+
+	if dryRun {
+		for _, todo := range toCreate {
+			fmt.Fprintf(w, "Would create ticket for %s:%d: %s\n", todo.Path, todo.Line, todoTitle(todo))
+		}
+		for _, t := range toClose {
+			fmt.Fprintf(w, "Would close ticket %d: %s\n", t.ID, t.Title)
+		}
+		return nil
+	}
+
+	parentCommit, err := repo.GetParentCommit(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	defer parentCommit.Free()
+	nextID, err := ReadNextTicketID(thisRepo, parentCommit)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	now := time.Now().Unix()
+	for _, todo := range toCreate {
+		t := Ticket{
+			ID:          nextID,
+			Title:       todoTitle(todo),
+			Description: "Found in " + todo.Path + ":" + strconv.Itoa(todo.Line) + ":\n\n```\n" + strings.TrimSpace(todo.Comment) + "\n```\n",
+			Labels:      []string{label},
+			Priority:    1,
+			Severity:    1,
+			Status:      "new",
+			CodeRefs: []CodeRef{{
+				Path:      todo.Path,
+				StartLine: todo.Line,
+				EndLine:   todo.Line,
+				Commit:    commitOID,
+				Author:    author.Name + " <" + author.Email + ">",
+				Created:   now,
+			}},
+			NextCommentID: 1,
+			Created:       now,
+		}
+		nextID++
+		files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+		fmt.Fprintf(w, "Created ticket %d for %s:%d: %s\n", t.ID, todo.Path, todo.Line, t.Title)
+	}
+	files["next_ticket_id"] = []byte(strconv.Itoa(nextID))
+
+	index := make(map[int]int)
+	for i, t := range tickets {
+		index[t.ID] = i
+	}
+	var closeIDs []int
+	for _, t := range toClose {
+		_, err = AddComment(&tickets[index[t.ID]], "The TODO comment was removed from "+t.CodeRefs[0].Path+" by commit "+shortOID(commitOID), thisRepo, branchName, debugFlag)
+		if err != nil {
+			return err
+		}
+		closeIDs = append(closeIDs, t.ID)
+		fmt.Fprintf(w, "Closed ticket %d: %s\n", t.ID, t.Title)
+	}
+	for _, id := range closeTickets(tickets, closeIDs) {
+		t := tickets[index[id]]
+		files[path.Join("tickets", t.TicketFilename())] = t.TicketToYaml()
+	}
+
+	commitMessage := fmt.Sprintf("Scanning for TODO comments, creating %d tickets and closing %d", len(toCreate), len(toClose))
+	return repo.CommitFiles(thisRepo, branchName, files, commitMessage, debugFlag)
+}
+
+// closeTickets takes every ticket and the IDs of the tickets to close. It
+// closes them and ticks the checklist items they were promoted from, updating
+// tickets in place so that each change sees the ones before it, and returns
+// the IDs of every ticket changed in order.
+func closeTickets(tickets []Ticket, ids []int) []int {
+	index := make(map[int]int)
+	for i, t := range tickets {
+		index[t.ID] = i
+	}
+	changed := make(map[int]bool)
+	for _, id := range ids {
+		i, ok := index[id]
+		if !ok {
+			continue
+		}
+		tickets[i].Status = "closed"
+		changed[id] = true
+		for _, parent := range SyncPromotedChecklistItems(tickets[i], tickets) {
+			tickets[index[parent.ID]] = parent
+			changed[parent.ID] = true
+		}
+	}
+	var changedIDs []int
+	for id := range changed {
+		changedIDs = append(changedIDs, id)
+	}
+	slices.Sort(changedIDs)
+	return changedIDs
+}
+
+// ParseTodos takes the path of a file and its contents, and returns the TODO
+// comments in it. Binary files have no TODO comments.
+func ParseTodos(filePath string, contents []byte) []Todo {
+	if bytes.IndexByte(contents[:min(len(contents), 8000)], 0) != -1 {
+		return nil
+	}
+
+	var todos []Todo
+	for i, line := range splitLines(contents) {
+		match := todoPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[2])
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+		todos = append(todos, Todo{
+			Path:    filePath,
+			Line:    i + 1,
+			Marker:  match[1],
+			Text:    text,
+			Comment: line,
+		})
+	}
+	return todos
+}
+
+// ReconcileTodos takes the TODO comments found by a scan, every ticket, the
+// label of tickets created from TODO comments, and the path that was scanned,
+// an empty path meaning every file. It returns the comments that need a new
+// ticket, and the open tickets whose comments were removed. Comments are
+// matched to tickets by file and title, so a comment that moves within its
+// file keeps its ticket. Tickets for files outside the scanned path are left
+// alone.
+func ReconcileTodos(todos []Todo, tickets []Ticket, label string, scope string) ([]Todo, []Ticket) {
+	key := func(filePath string, title string) string { return filePath + "\x00" + title }
+
+	found := make(map[string][]Todo)
+	var keys []string
+	for _, todo := range todos {
+		k := key(todo.Path, todoTitle(todo))
+		if _, ok := found[k]; !ok {
+			keys = append(keys, k)
+		}
+		found[k] = append(found[k], todo)
+	}
+
+	existing := make(map[string][]Ticket)
+	for _, t := range tickets {
+		if len(t.CodeRefs) == 0 || !slices.Contains(t.Labels, label) {
+			continue
+		}
+		k := key(t.CodeRefs[0].Path, t.Title)
+		existing[k] = append(existing[k], t)
+		if _, ok := found[k]; !ok && inScanScope(t.CodeRefs[0].Path, scope) {
+			keys = append(keys, k)
+			found[k] = nil
+		}
+	}
+
+	var toCreate []Todo
+	var toClose []Ticket
+	for _, k := range keys {
+		matches := existing[k]
+		// Open tickets are matched to comments first, so a closed ticket is
+		// only counted once every open one has its comment
+		slices.SortStableFunc(matches, func(a, b Ticket) int {
+			if IsClosed(a) != IsClosed(b) {
+				if IsClosed(a) {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Compare(a.ID, b.ID)
+		})
+		if len(found[k]) > len(matches) {
+			toCreate = append(toCreate, found[k][len(matches):]...)
+			continue
+		}
+		for _, t := range matches[len(found[k]):] {
+			if !IsClosed(t) {
+				toClose = append(toClose, t)
+			}
+		}
+	}
+	slices.SortStableFunc(toClose, func(a, b Ticket) int { return cmp.Compare(a.ID, b.ID) })
+	return toCreate, toClose
+}
+
+// todoTitle returns the title of the ticket for a TODO comment, eg:
+// "TODO: handle errors"
+func todoTitle(todo Todo) string {
+	text := todo.Text
+	if text == "" {
+		text = "in " + todo.Path
+	}
+	return truncate(todo.Marker+": "+text, maxTodoTitleLength)
+}
+
+// lookupCodeCommit returns the commit rev refers to, or the current commit if
+// rev is empty
+func lookupCodeCommit(thisRepo *git.Repository, branchName string, rev string) (*git.Commit, error) {
+	if rev == "" {
+		head, err := thisRepo.Head()
+		if err != nil {
+			return nil, err
+		}
+		defer head.Free()
+		if head.Shorthand() == branchName {
+			return nil, errors.New("the " + branchName + " branch holds tickets, check out a branch with code to scan")
+		}
+		return thisRepo.LookupCommit(head.Target())
+	}
+
+	object, err := thisRepo.RevparseSingle(rev)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find commit %s: %s", rev, err)
+	}
+	defer object.Free()
+	peeled, err := object.Peel(git.ObjectCommit)
+	if err != nil {
+		return nil, err
+	}
+	defer peeled.Free()
+	return peeled.AsCommit()
+}
+
+// findTodos returns the TODO comments in the files of commit under scope. If
+// fromWorkdir is true, the files are read from the working tree instead of the
+// commit.
+func findTodos(thisRepo *git.Repository, commit *git.Commit, scope string, fromWorkdir bool, debugFlag bool) ([]Todo, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	var todos []Todo
+	err = tree.Walk(func(dir string, entry *git.TreeEntry) error {
+		filePath := dir + entry.Name
+		if entry.Type == git.ObjectTree {
+			// Skip directories that can't contain the scanned path
+			if !inScanScope(filePath, scope) && !strings.HasPrefix(scope, filePath+"/") {
+				return git.TreeWalkSkip
+			}
+			return nil
+		}
+		if entry.Type != git.ObjectBlob || !inScanScope(filePath, scope) {
+			return nil
+		}
+
+		var contents []byte
+		if fromWorkdir {
+			contents, err = os.ReadFile(filepath.Join(thisRepo.Workdir(), filepath.FromSlash(filePath)))
+			if errors.Is(err, os.ErrNotExist) {
+				debug.DebugMessage(debugFlag, "Skipping "+filePath+", it was removed from the working tree")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		} else {
+			blob, err := thisRepo.LookupBlob(entry.Id)
+			if err != nil {
+				return err
+			}
+			contents = blob.Contents()
+			blob.Free()
+		}
+		todos = append(todos, ParseTodos(filePath, contents)...)
+		return nil
+	})
+	return todos, err
+}
+
+// normalizeScanPath takes a path to scan such as ./src/... and returns it
+// relative to the top of the repository, eg: "src", or an empty string for
+// every file
+func normalizeScanPath(scanPath string) (string, error) {
+	scanPath = filepath.ToSlash(strings.TrimSpace(scanPath))
+	scanPath = strings.TrimSuffix(strings.TrimSuffix(scanPath, "..."), "/")
+	scanPath = path.Clean(scanPath)
+	if scanPath == "." || scanPath == "" {
+		return "", nil
+	}
+	if path.IsAbs(scanPath) || scanPath == ".." || strings.HasPrefix(scanPath, "../") {
+		return "", errors.New("invalid path '" + scanPath + "', paths are relative to the top of the repository")
+	}
+	return scanPath, nil
+}
+
+// inScanScope returns true if filePath is scope or is under it
+func inScanScope(filePath string, scope string) bool {
+	return scope == "" || filePath == scope || strings.HasPrefix(filePath, scope+"/")
+}
@@ -0,0 +1,208 @@
+package ticket
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestParseTodos(t *testing.T) {
+	contents := []byte(`package foo
+
+// TODO: handle errors
+func Foo() {
+	x := "TODO: not a comment"
+	x-- // FIXME(jeff) off by one
+	/* XXX remove this */
+}
+# HACK
+// TODOS are not TODOs
+`)
+	expected := []Todo{
+		{Path: "foo.go", Line: 3, Marker: "TODO", Text: "handle errors", Comment: "// TODO: handle errors"},
+		{Path: "foo.go", Line: 6, Marker: "FIXME", Text: "off by one", Comment: "\tx-- // FIXME(jeff) off by one"},
+		{Path: "foo.go", Line: 7, Marker: "XXX", Text: "remove this", Comment: "\t/* XXX remove this */"},
+		{Path: "foo.go", Line: 9, Marker: "HACK", Text: "", Comment: "# HACK"},
+	}
+
+	todos := ParseTodos("foo.go", contents)
+	if !slices.Equal(todos, expected) {
+		t.Errorf("ParseTodos returned:\n%+v\nwant:\n%+v", todos, expected)
+	}
+
+	if todos := ParseTodos("foo.bin", []byte("// TODO: binary\x00")); len(todos) != 0 {
+		t.Errorf("Expected no TODO comments in a binary file, got %+v", todos)
+	}
+}
+
+func TestReconcileTodos(t *testing.T) {
+	todoTicket := func(id int, path string, title string, status string) Ticket {
+		return Ticket{ID: id, Title: title, Status: status, Labels: []string{"todo"}, CodeRefs: []CodeRef{{Path: path}}}
+	}
+	tickets := []Ticket{
+		todoTicket(1, "src/a.go", "TODO: kept", "new"),
+		todoTicket(2, "src/a.go", "TODO: removed", "new"),
+		todoTicket(3, "docs/b.md", "TODO: outside the scanned path", "new"),
+		todoTicket(4, "src/a.go", "TODO: closed by hand", "closed"),
+		{ID: 5, Title: "TODO: not from a scan", Status: "new", CodeRefs: []CodeRef{{Path: "src/a.go"}}},
+	}
+	todos := []Todo{
+		{Path: "src/a.go", Line: 30, Marker: "TODO", Text: "kept"},
+		{Path: "src/a.go", Line: 40, Marker: "TODO", Text: "closed by hand"},
+		{Path: "src/a.go", Line: 50, Marker: "TODO", Text: "new"},
+		{Path: "src/c.go", Line: 1, Marker: "TODO", Text: "kept"},
+		{Path: "src/c.go", Line: 2, Marker: "TODO", Text: "kept"},
+	}
+
+	toCreate, toClose := ReconcileTodos(todos, tickets, "todo", "src")
+	var created []string
+	for _, todo := range toCreate {
+		created = append(created, todo.Path+":"+todoTitle(todo))
+	}
+	if expected := []string{"src/a.go:TODO: new", "src/c.go:TODO: kept", "src/c.go:TODO: kept"}; !slices.Equal(created, expected) {
+		t.Errorf("Expected to create %v, got %v", expected, created)
+	}
+	if len(toClose) != 1 || toClose[0].ID != 2 {
+		t.Errorf("Expected to close ticket 2, got %+v", toClose)
+	}
+}
+
+func TestTodoTitle(t *testing.T) {
+	testCases := []struct {
+		todo     Todo
+		expected string
+	}{
+		{todo: Todo{Path: "a.go", Marker: "TODO", Text: "use a/b"}, expected: "TODO: use a/b"},
+		{todo: Todo{Path: "src/a.go", Marker: "FIXME"}, expected: "FIXME: in src/a.go"},
+		{todo: Todo{Marker: "TODO", Text: strings.Repeat("x", 100)}, expected: "TODO: " + strings.Repeat("x", 65) + "…"},
+	}
+
+	for _, tc := range testCases {
+		if got := todoTitle(tc.todo); got != tc.expected {
+			t.Errorf("todoTitle(%+v) = %q, want %q", tc.todo, got, tc.expected)
+		}
+	}
+}
+
+func TestNormalizeScanPath(t *testing.T) {
+	testCases := map[string]string{
+		"":           "",
+		"./...":      "",
+		".":          "",
+		"./src/...":  "src",
+		"src/":       "src",
+		"src/foo.go": "src/foo.go",
+	}
+	for scanPath, expected := range testCases {
+		got, err := normalizeScanPath(scanPath)
+		if err != nil || got != expected {
+			t.Errorf("normalizeScanPath(%q) = %q, %v, want %q", scanPath, got, err, expected)
+		}
+	}
+	if _, err := normalizeScanPath("../elsewhere"); err == nil {
+		t.Errorf("Expected an error for a path outside the repository")
+	}
+}
+
+func TestHandleScanTodos(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = thisRepo.SetHead("refs/heads/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFileOnMain(t, thisRepo, "foo.go", "package foo\n\n// TODO: handle errors\n// FIXME: slow\n")
+
+	// A dry run changes nothing
+	var b bytes.Buffer
+	err = HandleScanTodos(&b, common.BranchName, "", "main", "", true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Would create ticket for foo.go:3: TODO: handle errors") {
+		t.Errorf("Unexpected dry run output:\n%s", b.String())
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Fatalf("Expected the dry run not to create tickets, got %d tickets", len(tickets))
+	}
+
+	err = HandleScanTodos(&bytes.Buffer{}, common.BranchName, "", "main", "", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 3 {
+		t.Fatalf("Expected 2 tickets to be created, got %d tickets", len(tickets))
+	}
+	created := FilterTicketsByID(tickets, 2)
+	if created.Title != "TODO: handle errors" || !slices.Contains(created.Labels, DefaultTodoLabel) || len(created.CodeRefs) != 1 || created.CodeRefs[0].StartLine != 3 {
+		t.Errorf("Unexpected ticket created for the TODO comment: %+v", created)
+	}
+
+	// Scanning again after removing a comment closes its ticket, and doesn't
+	// create duplicates
+	commitFileOnMain(t, thisRepo, "foo.go", "package foo\n\nimport \"fmt\"\n\n// TODO: handle errors\n")
+	b.Reset()
+	err = HandleScanTodos(&b, common.BranchName, "./...", "main", "", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "Created") || !strings.Contains(b.String(), "Closed ticket 3: FIXME: slow") {
+		t.Errorf("Unexpected output scanning again:\n%s", b.String())
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 3 || !IsClosed(FilterTicketsByID(tickets, 3)) || IsClosed(FilterTicketsByID(tickets, 2)) {
+		t.Errorf("Unexpected tickets after scanning again: %+v", tickets)
+	}
+}
+
+func TestCloseTickets(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Status: "new", Checklist: []ChecklistItem{{Text: "a", TicketID: 2}, {Text: "b", TicketID: 3}}},
+		{ID: 2, Status: "new", Links: []Link{{Type: "child-of", ID: 1}}},
+		{ID: 3, Status: "new", Links: []Link{{Type: "child-of", ID: 1}}},
+		{ID: 4, Status: "new", Checklist: []ChecklistItem{{Text: "c", TicketID: 5}}},
+		{ID: 5, Status: "new", Links: []Link{{Type: "child-of", ID: 4}}},
+	}
+
+	// Two children of ticket 1, and ticket 4 closed before its child
+	changed := closeTickets(tickets, []int{2, 3, 4, 5})
+	if !slices.Equal(changed, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("closeTickets() changed %v, want [1 2 3 4 5]", changed)
+	}
+	if progress := ChecklistProgress(tickets[0]); progress != "2/2" {
+		t.Errorf("Ticket 1's progress is %s, want 2/2", progress)
+	}
+	if tickets[3].Status != "closed" || ChecklistProgress(tickets[3]) != "1/1" {
+		t.Errorf("Ticket 4 is %+v, want it closed with its item done", tickets[3])
+	}
+	for _, ticket := range tickets[1:] {
+		if ticket.Status != "closed" {
+			t.Errorf("Ticket %d wasn't closed", ticket.ID)
+		}
+	}
+}
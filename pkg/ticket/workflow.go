@@ -0,0 +1,246 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+const (
+	// DefaultStartStatus is the status giticket start moves tickets to when
+	// the startStatus setting is not set
+	DefaultStartStatus = "in progress"
+	// DefaultReviewStatus is the status giticket finish moves tickets to when
+	// the reviewStatus setting is not set
+	DefaultReviewStatus = "review"
+)
+
+// maxBranchSlugLength is the longest the title part of a work branch name
+// can be
+const maxBranchSlugLength = 40
+
+// HandleStart takes a writer, a branch name, a ticket ID, a prefix for the
+// work branch, and a debug flag. It checks out a work branch for the ticket
+// named from its ID and title, such as feature/12-fix-the-login-page with the
+// prefix "feature/", creating it from the current commit if it doesn't exist.
+// The ticket is moved to the start status and assigned to the current user in
+// a single commit. Returns an error if there was one.
+func HandleStart(w io.Writer, branchName string, ticketID int, prefix string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	settings, err := LoadSettings(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	status := settings.StartStatus
+	if status == "" {
+		status = DefaultStartStatus
+	}
+
+	workBranch := prefix + TicketBranchName(t)
+	if workBranch == branchName {
+		return errors.New("the work branch can't be named " + branchName)
+	}
+	created, err := checkoutWorkBranch(thisRepo, workBranch, debugFlag)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Fprintf(w, "Switched to a new branch '%s'\n", workBranch)
+	} else {
+		fmt.Fprintf(w, "Switched to branch '%s'\n", workBranch)
+	}
+
+	AssignTicket(&t, []string{author.Name + " <" + author.Email + ">"})
+	t.Status = status
+	err = commitStatusChange(thisRepo, branchName, t, tickets, "Starting work on ticket "+t.TicketFilename()+" on branch "+workBranch, debugFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Ticket %d is %s and assigned to %s\n", t.ID, status, author.Name)
+	return nil
+}
+
+// HandleFinish takes a writer, a branch name, a ticket ID, a close flag, and a
+// debug flag. It moves the ticket to the review status, or closes it if
+// closeFlag is true. A ticket ID of 0 means the ticket the current branch is
+// named after, see TicketIDFromBranch. Returns an error if the ticket can't be
+// found, or if there was another error.
+func HandleFinish(w io.Writer, branchName string, ticketID int, closeFlag bool, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	if ticketID == 0 {
+		head, err := thisRepo.Head()
+		if err != nil {
+			return err
+		}
+		ticketID = TicketIDFromBranch(head.Shorthand())
+		if ticketID == 0 {
+			head.Free()
+			return errors.New("the current branch " + head.Shorthand() + " isn't named after a ticket, give the ticket ID")
+		}
+		debug.DebugMessage(debugFlag, "Branch "+head.Shorthand()+" is for ticket "+strconv.Itoa(ticketID))
+		head.Free()
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	status := "closed"
+	if !closeFlag {
+		settings, err := LoadSettings(thisRepo, branchName, debugFlag)
+		if err != nil {
+			return err
+		}
+		status = settings.ReviewStatus
+		if status == "" {
+			status = DefaultReviewStatus
+		}
+	}
+
+	t.Status = status
+	err = commitStatusChange(thisRepo, branchName, t, tickets, "Finishing work on ticket "+t.TicketFilename(), debugFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Ticket %d is %s\n", t.ID, status)
+	return nil
+}
+
+// TicketBranchName takes a ticket and returns the name of its work branch,
+// the ticket ID followed by its title in lower case with words separated by
+// dashes, eg: "12-fix-the-login-page"
+func TicketBranchName(t Ticket) string {
+	name := slugify(t.Title)
+	if len(name) > maxBranchSlugLength {
+		// Cut at the end of a word if there is one
+		if i := strings.LastIndex(name[:maxBranchSlugLength+1], "-"); i > 0 {
+			name = name[:i]
+		} else {
+			name = name[:maxBranchSlugLength]
+		}
+	}
+	if name == "" {
+		return strconv.Itoa(t.ID)
+	}
+	return strconv.Itoa(t.ID) + "-" + name
+}
+
+// slugify returns s in lower case with each run of characters other than
+// letters and digits replaced by a dash, eg: "fix-the-login-page" from "Fix
+// the login page!"
+func slugify(s string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && slug.Len() > 0 {
+				slug.WriteRune('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+// checkoutWorkBranch checks out the branch named workBranch, creating it from
+// the current commit if it doesn't exist. Returns true if the branch was
+// created.
+func checkoutWorkBranch(thisRepo *git.Repository, workBranch string, debugFlag bool) (bool, error) {
+	branch, err := thisRepo.LookupBranch(workBranch, git.BranchLocal)
+	if err == nil {
+		defer branch.Free()
+		debug.DebugMessage(debugFlag, "Checking out existing branch "+workBranch)
+		commit, err := thisRepo.LookupCommit(branch.Target())
+		if err != nil {
+			return false, err
+		}
+		defer commit.Free()
+		tree, err := commit.Tree()
+		if err != nil {
+			return false, err
+		}
+		defer tree.Free()
+		// A safe checkout refuses to overwrite changes in the working tree
+		err = thisRepo.CheckoutTree(tree, &git.CheckoutOptions{Strategy: git.CheckoutSafe})
+		if err != nil {
+			return false, err
+		}
+		return false, thisRepo.SetHead("refs/heads/" + workBranch)
+	}
+	if !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return false, err
+	}
+
+	debug.DebugMessage(debugFlag, "Creating branch "+workBranch)
+	head, err := thisRepo.Head()
+	if err != nil {
+		return false, err
+	}
+	defer head.Free()
+	commit, err := thisRepo.LookupCommit(head.Target())
+	if err != nil {
+		return false, err
+	}
+	defer commit.Free()
+	branch, err = thisRepo.CreateBranch(workBranch, commit, false)
+	if err != nil {
+		return false, err
+	}
+	defer branch.Free()
+
+	// The new branch points at the current commit, so the working tree
+	// doesn't change
+	return true, thisRepo.SetHead("refs/heads/" + workBranch)
+}
+
+// commitStatusChange commits a ticket whose status has changed, along with the
+// checklists of its parent tickets kept in step, see
+// SyncPromotedChecklistItems
+func commitStatusChange(thisRepo *git.Repository, branchName string, t Ticket, tickets []Ticket, commitMessage string, debugFlag bool) error {
+	toCommit := []common.TicketInterface{&t}
+	parents := SyncPromotedChecklistItems(t, tickets)
+	for i := range parents {
+		toCommit = append(toCommit, &parents[i])
+	}
+	return repo.CommitTickets(thisRepo, branchName, toCommit, commitMessage, debugFlag)
+}
@@ -0,0 +1,112 @@
+package ticket
+
+import (
+	"bytes"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestTicketBranchName(t *testing.T) {
+	testCases := []struct {
+		ticket   Ticket
+		expected string
+	}{
+		{ticket: Ticket{ID: 12, Title: "Fix the login page"}, expected: "12-fix-the-login-page"},
+		{ticket: Ticket{ID: 3, Title: "  Crash when saving: \"file.txt\" (v2.0)!  "}, expected: "3-crash-when-saving-file-txt-v2-0"},
+		{ticket: Ticket{ID: 7, Title: "Élan über café"}, expected: "7-lan-ber-caf"},
+		{ticket: Ticket{ID: 9, Title: "???"}, expected: "9"},
+		{
+			ticket:   Ticket{ID: 1, Title: "Invert the polarity of the neutron flow before the tardis explodes"},
+			expected: "1-invert-the-polarity-of-the-neutron-flow",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := TicketBranchName(tc.ticket); got != tc.expected {
+			t.Errorf("TicketBranchName(%q) = %q, want %q", tc.ticket.Title, got, tc.expected)
+		}
+	}
+}
+
+func TestHandleStart(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Work starts from main, where InitGit made its commit
+	err = thisRepo.SetHead("refs/heads/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workBranch := "feature/" + TicketBranchName(FilterTicketsByID(tickets, 1))
+
+	err = HandleStart(&bytes.Buffer{}, common.BranchName, 1, "feature/", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := thisRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Shorthand() != workBranch {
+		t.Errorf("Expected %s to be checked out, got %s", workBranch, head.Shorthand())
+	}
+
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := FilterTicketsByID(tickets, 1)
+	if started.Status != DefaultStartStatus {
+		t.Errorf("Expected status %q after starting, got %q", DefaultStartStatus, started.Status)
+	}
+	if !IsAssignedTo(started, "jsmith@example.com") {
+		t.Errorf("Expected the ticket to be assigned to the current user, got %v", started.Assignees)
+	}
+
+	// Starting again checks out the existing branch
+	err = HandleStart(&bytes.Buffer{}, common.BranchName, 1, "feature/", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ticket is found from the branch name
+	err = HandleFinish(&bytes.Buffer{}, common.BranchName, 0, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := FilterTicketsByID(tickets, 1).Status; status != DefaultReviewStatus {
+		t.Errorf("Expected status %q after finishing, got %q", DefaultReviewStatus, status)
+	}
+
+	err = HandleFinish(&bytes.Buffer{}, common.BranchName, 1, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err = GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := FilterTicketsByID(tickets, 1).Status; status != "closed" {
+		t.Errorf("Expected status closed after finishing with close, got %q", status)
+	}
+}
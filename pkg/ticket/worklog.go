@@ -0,0 +1,267 @@
+package ticket
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+// TimeActions are the actions accepted by the time subcommand
+var TimeActions = []string{"log", "estimate", "report"}
+
+// TimeReportGroups are the ways HandleTimeReport can total logged work
+var TimeReportGroups = []string{"author", "ticket", "label"}
+
+// WorkEntry is time spent working on a ticket
+type WorkEntry struct {
+	// Duration is the time spent, in seconds
+	Duration int64
+	Author   string
+	Note     string
+	Created  int64
+}
+
+// TimeTotal is the total work logged for one author, ticket, or label in a
+// time report
+type TimeTotal struct {
+	Name string
+	// Duration is the total time logged, in seconds
+	Duration int64
+	Entries  int
+}
+
+// HandleTimeLog takes a branch name, a ticket ID, the time spent, a note, and
+// a debug flag, and logs the work on the ticket as the current user. Returns
+// an error if the ticket doesn't exist, or if there was another error.
+func HandleTimeLog(branchName string, ticketID int, spent time.Duration, note string, debugFlag bool) error {
+	if spent < time.Minute {
+		return errors.New("at least one minute of work must be logged")
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	t.WorkLog = append(t.WorkLog, WorkEntry{
+		Duration: int64(spent / time.Second),
+		Author:   author.Name + " <" + author.Email + ">",
+		Note:     note,
+		Created:  time.Now().Unix(),
+	})
+	return repo.Commit(&t, thisRepo, branchName, author, "Logging "+formatWork(int64(spent/time.Second))+" on ticket "+t.TicketFilename(), debugFlag)
+}
+
+// HandleTimeEstimate takes a branch name, a ticket ID, an estimate of the
+// work the ticket needs, and a debug flag, and sets the ticket's estimate. An
+// estimate of 0 removes it. Returns an error if the ticket doesn't exist, or if
+// there was another error.
+func HandleTimeEstimate(branchName string, ticketID int, estimate time.Duration, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	// Get author
+	author, err := common.GetAuthor(thisRepo)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+	t := FilterTicketsByID(tickets, ticketID)
+	if t.ID == 0 {
+		return fmt.Errorf("ticket %d not found", ticketID)
+	}
+
+	t.Estimate = int64(estimate / time.Second)
+	commitMessage := "Estimating ticket " + t.TicketFilename() + " at " + formatWork(t.Estimate)
+	if estimate == 0 {
+		commitMessage = "Removing estimate from ticket " + t.TicketFilename()
+	}
+	return repo.Commit(&t, thisRepo, branchName, author, commitMessage, debugFlag)
+}
+
+// HandleTimeReport takes a writer, a branch name, a unix time, how to group
+// the work, and a debug flag, and writes the total work logged since the unix
+// time to w, grouped by author, ticket, or label. A since of 0 includes all
+// logged work.
+func HandleTimeReport(w io.Writer, branchName string, since int64, by string, debugFlag bool) error {
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+
+	tickets, err := GetListOfTickets(thisRepo, branchName, debugFlag)
+	if err != nil {
+		return err
+	}
+
+	totals, err := TimeReport(tickets, since, by)
+	if err != nil {
+		return err
+	}
+	if len(totals) == 0 {
+		fmt.Fprintln(w, "No work has been logged")
+		return nil
+	}
+	WriteTimeReport(w, totals, by)
+	return nil
+}
+
+// TimeReport takes a list of tickets, a unix time, and how to group the work,
+// one of TimeReportGroups, and returns the total work logged since the unix
+// time for each group, most work first. Work on a ticket with more than one
+// label counts towards each of its labels. Returns an error if by is not
+// recognized.
+func TimeReport(tickets []Ticket, since int64, by string) ([]TimeTotal, error) {
+	if !slices.Contains(TimeReportGroups, by) {
+		return nil, fmt.Errorf("unknown time report grouping '%s', valid groupings are: %s", by, strings.Join(TimeReportGroups, ", "))
+	}
+
+	byName := make(map[string]*TimeTotal)
+	var totals []*TimeTotal
+	add := func(name string, entry WorkEntry) {
+		total, ok := byName[name]
+		if !ok {
+			total = &TimeTotal{Name: name}
+			byName[name] = total
+			totals = append(totals, total)
+		}
+		total.Duration += entry.Duration
+		total.Entries++
+	}
+
+	for _, t := range tickets {
+		for _, entry := range t.WorkLog {
+			if entry.Created < since {
+				continue
+			}
+			switch by {
+			case "author":
+				add(entry.Author, entry)
+			case "ticket":
+				add(strconv.Itoa(t.ID)+": "+t.Title, entry)
+			case "label":
+				if len(t.Labels) == 0 {
+					add("(none)", entry)
+				}
+				for _, label := range t.Labels {
+					add(label, entry)
+				}
+			}
+		}
+	}
+
+	report := []TimeTotal{}
+	for _, total := range totals {
+		report = append(report, *total)
+	}
+	slices.SortStableFunc(report, func(a, b TimeTotal) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return report, nil
+}
+
+// WriteTimeReport writes a table of time totals to w, followed by the total of
+// all of them
+func WriteTimeReport(w io.Writer, totals []TimeTotal, by string) {
+	header := strings.ToUpper(by[:1]) + by[1:]
+	rows := [][]string{{header, "Logged", "Entries"}}
+	var sum int64
+	for _, total := range totals {
+		rows = append(rows, []string{total.Name, formatWork(total.Duration), strconv.Itoa(total.Entries)})
+		sum += total.Duration
+	}
+
+	separator := writeTable(w, rows)
+	fmt.Fprintln(w, separator)
+	fmt.Fprintln(w, "Total: "+formatWork(sum))
+}
+
+// LoggedWork returns the total work logged on a ticket, in seconds
+func LoggedWork(t Ticket) int64 {
+	var logged int64
+	for _, entry := range t.WorkLog {
+		logged += entry.Duration
+	}
+	return logged
+}
+
+// WorkSummary returns the work logged on a ticket and its estimate, such as
+// "1h30m of 4h (38%)", or an empty string if neither has been recorded
+func WorkSummary(t Ticket) string {
+	logged := LoggedWork(t)
+	switch {
+	case t.Estimate == 0 && logged == 0:
+		return ""
+	case t.Estimate == 0:
+		return formatWork(logged)
+	}
+	return fmt.Sprintf("%s of %s (%d%%)", formatWork(logged), formatWork(t.Estimate), logged*100/t.Estimate)
+}
+
+// ParseSince takes the start of a report, as a date such as 2026-10-01 or a
+// duration before now such as 7d, and the current time, and returns it as a
+// unix time. Dates start at the beginning of the day in the local time zone.
+func ParseSince(since string, now time.Time) (int64, error) {
+	since = strings.TrimSpace(since)
+	day, err := time.ParseInLocation("2006-01-02", since, time.Local)
+	if err == nil {
+		return day.Unix(), nil
+	}
+	d, err := ParseDuration(since)
+	if err != nil {
+		return 0, errors.New("invalid start '" + since + "', expected a date such as 2026-10-01 or a duration such as 7d")
+	}
+	return now.Add(-d).Unix(), nil
+}
+
+// formatWork formats a number of seconds of work as hours and minutes, such as
+// "1h30m", "45m" or "0m"
+func formatWork(seconds int64) string {
+	minutes := seconds / 60
+	hours := minutes / 60
+	minutes %= 60
+	switch {
+	case hours == 0:
+		return strconv.FormatInt(minutes, 10) + "m"
+	case minutes == 0:
+		return strconv.FormatInt(hours, 10) + "h"
+	}
+	return strconv.FormatInt(hours, 10) + "h" + strconv.FormatInt(minutes, 10) + "m"
+}
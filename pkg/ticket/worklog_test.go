@@ -0,0 +1,153 @@
+package ticket
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+)
+
+func TestTimeReport(t *testing.T) {
+	alice := "Alice Smith <alice@example.com>"
+	bob := "Bob Jones <bob@example.com>"
+	tickets := []Ticket{
+		{ID: 1, Title: "First", Labels: []string{"bug", "ux"}, WorkLog: []WorkEntry{
+			{Duration: 3600, Author: alice, Created: 100},
+			{Duration: 1800, Author: bob, Created: 200},
+		}},
+		{ID: 2, Title: "Second", WorkLog: []WorkEntry{
+			{Duration: 7200, Author: bob, Created: 300},
+		}},
+		{ID: 3, Title: "Third"},
+	}
+
+	testCases := []struct {
+		by          string
+		since       int64
+		expected    []TimeTotal
+		expectedErr bool
+	}{
+		{by: "author", expected: []TimeTotal{{Name: bob, Duration: 9000, Entries: 2}, {Name: alice, Duration: 3600, Entries: 1}}},
+		{by: "ticket", expected: []TimeTotal{{Name: "2: Second", Duration: 7200, Entries: 1}, {Name: "1: First", Duration: 5400, Entries: 2}}},
+		{by: "label", expected: []TimeTotal{{Name: "(none)", Duration: 7200, Entries: 1}, {Name: "bug", Duration: 5400, Entries: 2}, {Name: "ux", Duration: 5400, Entries: 2}}},
+		{by: "author", since: 150, expected: []TimeTotal{{Name: bob, Duration: 9000, Entries: 2}}},
+		{by: "author", since: 400, expected: []TimeTotal{}},
+		{by: "status", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		totals, err := TimeReport(tickets, tc.since, tc.by)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("TimeReport(%d, %s) returned error %v, expected an error: %t", tc.since, tc.by, err, tc.expectedErr)
+		}
+		if !tc.expectedErr && !reflect.DeepEqual(totals, tc.expected) {
+			t.Errorf("TimeReport(%d, %s) = %v, want %v", tc.since, tc.by, totals, tc.expected)
+		}
+	}
+
+	var b bytes.Buffer
+	totals, _ := TimeReport(tickets, 0, "author")
+	WriteTimeReport(&b, totals, "author")
+	expected := `Author                          | Logged | Entries
+--------------------------------------------------
+Bob Jones <bob@example.com>     | 2h30m  | 2
+Alice Smith <alice@example.com> | 1h     | 1
+--------------------------------------------------
+Total: 3h30m
+`
+	if b.String() != expected {
+		t.Errorf("WriteTimeReport wrote:\n%s\nwant:\n%s", b.String(), expected)
+	}
+}
+
+func TestWorkSummary(t *testing.T) {
+	testCases := []struct {
+		ticket   Ticket
+		expected string
+	}{
+		{ticket: Ticket{}, expected: ""},
+		{ticket: Ticket{Estimate: 4 * 3600}, expected: "0m of 4h (0%)"},
+		{ticket: Ticket{WorkLog: []WorkEntry{{Duration: 45 * 60}}}, expected: "45m"},
+		{ticket: Ticket{Estimate: 4 * 3600, WorkLog: []WorkEntry{{Duration: 3600}, {Duration: 30 * 60}}}, expected: "1h30m of 4h (37%)"},
+	}
+
+	for _, tc := range testCases {
+		if got := WorkSummary(tc.ticket); got != tc.expected {
+			t.Errorf("WorkSummary(%+v) = %q, want %q", tc.ticket, got, tc.expected)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 21, 9, 30, 0, 0, time.Local)
+
+	since, err := ParseSince("2026-10-01", now)
+	if err != nil || since != time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local).Unix() {
+		t.Errorf("ParseSince(\"2026-10-01\") = %d, %v, want the start of the day", since, err)
+	}
+	since, err = ParseSince("7d", now)
+	if err != nil || since != now.AddDate(0, 0, -7).Unix() {
+		t.Errorf("ParseSince(\"7d\") = %d, %v, want a week before now", since, err)
+	}
+	_, err = ParseSince("last week", now)
+	if err == nil {
+		t.Errorf("Expected an error parsing \"last week\"")
+	}
+}
+
+func TestHandleTimeLog(t *testing.T) {
+	common.UseTempDir(t)
+
+	// Initialize git and giticket
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = HandleTimeEstimate(common.BranchName, 1, 4*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleTimeLog(common.BranchName, 1, 90*time.Minute, "investigating", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = HandleTimeLog(common.BranchName, 1, 30*time.Second, "", true)
+	if err == nil {
+		t.Errorf("Expected an error logging less than a minute of work")
+	}
+	err = HandleTimeLog(common.BranchName, 2, time.Hour, "", true)
+	if err == nil {
+		t.Errorf("Expected an error logging work on a ticket that doesn't exist")
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket := FilterTicketsByID(tickets, 1)
+	if len(ticket.WorkLog) != 1 || ticket.WorkLog[0].Author != "John Smith <jsmith@example.com>" || ticket.WorkLog[0].Note != "investigating" {
+		t.Errorf("Unexpected work log %+v", ticket.WorkLog)
+	}
+	if summary := WorkSummary(ticket); summary != "1h30m of 4h (37%)" {
+		t.Errorf("WorkSummary = %q, want \"1h30m of 4h (37%%)\"", summary)
+	}
+
+	var b bytes.Buffer
+	err = HandleTimeReport(&b, common.BranchName, 0, "ticket", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Total: 1h30m") {
+		t.Errorf("Unexpected time report:\n%s", b.String())
+	}
+}
@@ -0,0 +1,368 @@
+package tui
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// The views the TUI can show
+const (
+	ViewList  = "list"
+	ViewBoard = "board"
+)
+
+// The kinds of Action returned by Model.HandleKey
+const (
+	ActionNone        = ""
+	ActionQuit        = "quit"
+	ActionReload      = "reload"
+	ActionStatus      = "status"
+	ActionPriority    = "priority"
+	ActionSeverity    = "severity"
+	ActionAddLabel    = "add label"
+	ActionRemoveLabel = "remove label"
+	ActionComment     = "comment"
+)
+
+// Action is a change to make to a ticket, or something else the TUI needs to
+// do, in response to a key
+type Action struct {
+	Kind     string
+	TicketID int
+	Value    string
+}
+
+// Key is a key pressed by the user. Name is "rune" for a printable
+// character, which is in Rune, or the name of another key such as "up",
+// "enter", or "esc".
+type Key struct {
+	Name string
+	Rune rune
+}
+
+// prompt is a line of text being typed by the user, such as a new status,
+// which becomes an Action of the given kind when enter is pressed
+type prompt struct {
+	label    string
+	input    string
+	kind     string
+	ticketID int
+}
+
+// Model is the state of the TUI: the tickets, the filter and view chosen, the
+// selected ticket, and any prompt or message being shown. It does nothing
+// itself, changes to tickets are returned as Actions by HandleKey.
+type Model struct {
+	tickets     []ticket.Ticket
+	visible     []ticket.Ticket
+	filters     []ticket.Filter
+	filterIndex int
+	workflow    []string
+	view        string
+	selectedID  int
+	prompt      *prompt
+	message     string
+}
+
+// NewModel takes every ticket, the saved filters, the name of the filter to
+// start with or "" for all tickets, and the statuses of the workflow in order,
+// which are always shown as columns of the board. It returns a Model showing
+// the list view.
+func NewModel(tickets []ticket.Ticket, filters []ticket.Filter, currentFilter string, workflow []string) *Model {
+	filters = slices.Clone(filters)
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Name < filters[j].Name })
+	m := &Model{filters: filters, workflow: workflow, view: ViewList}
+	for i, f := range filters {
+		if f.Name == currentFilter {
+			m.filterIndex = i + 1
+		}
+	}
+	m.SetTickets(tickets)
+	return m
+}
+
+// SetTickets replaces the tickets, such as after a change has been committed,
+// keeping the same ticket selected if it is still shown
+func (m *Model) SetTickets(tickets []ticket.Ticket) {
+	m.tickets = slices.Clone(tickets)
+	sort.Slice(m.tickets, func(i, j int) bool { return m.tickets[i].ID < m.tickets[j].ID })
+	m.applyFilter()
+}
+
+// SetMessage sets the message shown at the bottom of the screen
+func (m *Model) SetMessage(message string) {
+	m.message = message
+}
+
+// Selected returns the selected ticket, or false if no ticket is shown
+func (m *Model) Selected() (ticket.Ticket, bool) {
+	for _, t := range m.visible {
+		if t.ID == m.selectedID {
+			return t, true
+		}
+	}
+	return ticket.Ticket{}, false
+}
+
+// FilterName returns the name of the filter being used, or "" if every ticket
+// is shown
+func (m *Model) FilterName() string {
+	if m.filterIndex == 0 {
+		return ""
+	}
+	return m.filters[m.filterIndex-1].Name
+}
+
+// applyFilter chooses the tickets to show with the current filter, and makes
+// sure one of them is selected
+func (m *Model) applyFilter() {
+	m.visible = m.tickets
+	if m.filterIndex > 0 {
+		filter := m.filters[m.filterIndex-1]
+		filtered, err := ticket.QueryTickets(m.tickets, filter.Filter, false)
+		if err != nil {
+			m.message = "Filter " + filter.Name + ": " + err.Error()
+		} else {
+			m.visible = filtered
+		}
+	}
+	if _, ok := m.Selected(); !ok {
+		m.selectedID = 0
+		if len(m.visible) > 0 {
+			m.selectedID = m.visible[0].ID
+		}
+	}
+}
+
+// HandleKey updates the model for a key pressed by the user, and returns the
+// Action the key asks for, which has the kind ActionNone if there is nothing
+// more to do
+func (m *Model) HandleKey(key Key) Action {
+	if m.prompt != nil {
+		return m.handlePromptKey(key)
+	}
+	m.message = ""
+
+	switch key.Name {
+	case "ctrl-c":
+		return Action{Kind: ActionQuit}
+	case "up":
+		m.moveVertical(-1)
+	case "down":
+		m.moveVertical(1)
+	case "left":
+		m.moveColumn(-1)
+	case "right":
+		m.moveColumn(1)
+	case "tab":
+		m.toggleView()
+	case "enter":
+		if m.view == ViewBoard {
+			m.view = ViewList
+		}
+	case "rune":
+		return m.handleRune(key.Rune)
+	}
+	return Action{}
+}
+
+// handleRune handles a printable key outside of a prompt
+func (m *Model) handleRune(r rune) Action {
+	switch r {
+	case 'q':
+		return Action{Kind: ActionQuit}
+	case 'k':
+		m.moveVertical(-1)
+	case 'j':
+		m.moveVertical(1)
+	case 'h':
+		m.moveColumn(-1)
+	case 'l':
+		m.moveColumn(1)
+	case 'b':
+		m.toggleView()
+	case 'f':
+		m.filterIndex = (m.filterIndex + 1) % (len(m.filters) + 1)
+		m.applyFilter()
+	case 'F':
+		m.filterIndex = (m.filterIndex + len(m.filters)) % (len(m.filters) + 1)
+		m.applyFilter()
+	case 'r':
+		return Action{Kind: ActionReload}
+	case '[', ']':
+		return m.moveToColumn(r)
+	}
+
+	t, ok := m.Selected()
+	if !ok {
+		return Action{}
+	}
+	switch r {
+	case 's':
+		m.prompt = &prompt{label: "Status", input: t.Status, kind: ActionStatus, ticketID: t.ID}
+	case 'p':
+		m.prompt = &prompt{label: "Priority", input: strconv.Itoa(t.Priority), kind: ActionPriority, ticketID: t.ID}
+	case 'v':
+		m.prompt = &prompt{label: "Severity", input: strconv.Itoa(t.Severity), kind: ActionSeverity, ticketID: t.ID}
+	case 'a':
+		m.prompt = &prompt{label: "Add label", kind: ActionAddLabel, ticketID: t.ID}
+	case 'd':
+		m.prompt = &prompt{label: "Remove label (" + strings.Join(t.Labels, ", ") + ")", kind: ActionRemoveLabel, ticketID: t.ID}
+		if len(t.Labels) == 1 {
+			m.prompt.input = t.Labels[0]
+		}
+	case 'c':
+		return Action{Kind: ActionComment, TicketID: t.ID}
+	}
+	return Action{}
+}
+
+// handlePromptKey handles a key while a prompt is shown
+func (m *Model) handlePromptKey(key Key) Action {
+	switch key.Name {
+	case "ctrl-c", "esc":
+		m.prompt = nil
+	case "backspace":
+		runes := []rune(m.prompt.input)
+		if len(runes) > 0 {
+			m.prompt.input = string(runes[:len(runes)-1])
+		}
+	case "rune":
+		m.prompt.input += string(key.Rune)
+	case "enter":
+		p := m.prompt
+		m.prompt = nil
+		value := strings.TrimSpace(p.input)
+		if value == "" {
+			return Action{}
+		}
+		return Action{Kind: p.kind, TicketID: p.ticketID, Value: value}
+	}
+	return Action{}
+}
+
+// toggleView switches between the list and board views
+func (m *Model) toggleView() {
+	if m.view == ViewList {
+		m.view = ViewBoard
+	} else {
+		m.view = ViewList
+	}
+}
+
+// moveVertical selects the ticket above or below the selected one, in the
+// list or in the selected ticket's column of the board
+func (m *Model) moveVertical(delta int) {
+	tickets := m.visible
+	if m.view == ViewBoard {
+		columns := m.Columns()
+		column, _ := m.selectedPosition(columns)
+		if column < 0 {
+			return
+		}
+		tickets = columns[column].Tickets
+	}
+	for i, t := range tickets {
+		if t.ID == m.selectedID {
+			i = min(max(i+delta, 0), len(tickets)-1)
+			m.selectedID = tickets[i].ID
+			return
+		}
+	}
+}
+
+// moveColumn selects a ticket in the next column of the board with a ticket
+// in it, in the direction of delta, staying as close to the same row as
+// possible
+func (m *Model) moveColumn(delta int) {
+	if m.view != ViewBoard {
+		return
+	}
+	columns := m.Columns()
+	column, row := m.selectedPosition(columns)
+	if column < 0 {
+		return
+	}
+	for c := column + delta; c >= 0 && c < len(columns); c += delta {
+		if len(columns[c].Tickets) > 0 {
+			m.selectedID = columns[c].Tickets[min(row, len(columns[c].Tickets)-1)].ID
+			return
+		}
+	}
+}
+
+// moveToColumn returns an Action changing the selected ticket's status to that
+// of the board column before it for '[', or after it for ']'
+func (m *Model) moveToColumn(r rune) Action {
+	if m.view != ViewBoard {
+		return Action{}
+	}
+	columns := m.Columns()
+	column, _ := m.selectedPosition(columns)
+	if column < 0 {
+		return Action{}
+	}
+	target := column + 1
+	if r == '[' {
+		target = column - 1
+	}
+	if target < 0 || target >= len(columns) {
+		return Action{}
+	}
+	return Action{Kind: ActionStatus, TicketID: m.selectedID, Value: columns[target].Status}
+}
+
+// Column is a column of the board, the tickets with the same status
+type Column struct {
+	Status  string
+	Tickets []ticket.Ticket
+}
+
+// Columns returns the columns of the board: the statuses of the workflow in
+// order, then any other open statuses of the shown tickets in alphabetical
+// order, then any closed statuses not in the workflow
+func (m *Model) Columns() []Column {
+	statuses := slices.Clone(m.workflow)
+	var others, closed []string
+	for _, t := range m.visible {
+		if slices.Contains(statuses, t.Status) || slices.Contains(others, t.Status) || slices.Contains(closed, t.Status) {
+			continue
+		}
+		if ticket.IsClosed(t) {
+			closed = append(closed, t.Status)
+		} else {
+			others = append(others, t.Status)
+		}
+	}
+	sort.Strings(others)
+	sort.Strings(closed)
+	statuses = append(append(statuses, others...), closed...)
+
+	columns := make([]Column, len(statuses))
+	for i, status := range statuses {
+		columns[i].Status = status
+		for _, t := range m.visible {
+			if t.Status == status {
+				columns[i].Tickets = append(columns[i].Tickets, t)
+			}
+		}
+	}
+	return columns
+}
+
+// selectedPosition returns the column and row of the selected ticket on the
+// board, or -1 and -1 if it isn't shown
+func (m *Model) selectedPosition(columns []Column) (int, int) {
+	for c, column := range columns {
+		for r, t := range column.Tickets {
+			if t.ID == m.selectedID {
+				return c, r
+			}
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+// testModel returns a Model with tickets in several statuses and a filter for
+// the open ones
+func testModel() *Model {
+	tickets := []ticket.Ticket{
+		{ID: 3, Title: "Write the docs", Status: "in progress", Priority: 2, Labels: []string{"docs"}},
+		{ID: 1, Title: "Reverse the polarity", Status: "new", Priority: 1},
+		{ID: 2, Title: "Fix the build", Status: "closed", Priority: 1},
+		{ID: 4, Title: "Tidy up", Status: "blocked", Priority: 3},
+		{ID: 5, Title: "Invert the flow", Status: "new", Priority: 1},
+	}
+	filters := []ticket.Filter{{Name: "open", Filter: `.[] | select(.Status != "closed")`}}
+	return NewModel(tickets, filters, "", []string{"new", "in progress", "review", "closed"})
+}
+
+// press sends keys to the model, given as a string of printable characters,
+// and returns the last Action
+func press(m *Model, keys string) Action {
+	var action Action
+	for _, r := range keys {
+		action = m.HandleKey(Key{Name: "rune", Rune: r})
+	}
+	return action
+}
+
+func TestModelNavigation(t *testing.T) {
+	m := testModel()
+	if selected, _ := m.Selected(); selected.ID != 1 {
+		t.Fatalf("expected ticket 1 to be selected first, got %d", selected.ID)
+	}
+	press(m, "jjj")
+	if selected, _ := m.Selected(); selected.ID != 4 {
+		t.Errorf("expected ticket 4 after moving down 3 times, got %d", selected.ID)
+	}
+	press(m, "jjjjk")
+	if selected, _ := m.Selected(); selected.ID != 4 {
+		t.Errorf("expected moving down to stop at the last ticket, got %d", selected.ID)
+	}
+
+	// Filters cycle through every saved filter and back to all tickets
+	press(m, "f")
+	if m.FilterName() != "open" || len(m.visible) != 4 {
+		t.Errorf("expected the open filter with 4 tickets, got %q with %d", m.FilterName(), len(m.visible))
+	}
+	press(m, "f")
+	if m.FilterName() != "" || len(m.visible) != 5 {
+		t.Errorf("expected every ticket, got %q with %d", m.FilterName(), len(m.visible))
+	}
+
+	// The selected ticket is kept if it's still shown after the tickets are
+	// reloaded, and otherwise the first ticket is selected
+	m.SetTickets(m.tickets[1:])
+	if selected, _ := m.Selected(); selected.ID != 4 {
+		t.Errorf("expected ticket 4 to stay selected, got %d", selected.ID)
+	}
+	m.SetTickets(m.tickets[:2])
+	if selected, _ := m.Selected(); selected.ID != 2 {
+		t.Errorf("expected ticket 2 to be selected once ticket 4 is gone, got %d", selected.ID)
+	}
+}
+
+func TestModelColumns(t *testing.T) {
+	m := testModel()
+	var got []string
+	for _, column := range m.Columns() {
+		var ids []string
+		for _, tk := range column.Tickets {
+			ids = append(ids, strconv.Itoa(tk.ID))
+		}
+		got = append(got, column.Status+":"+strings.Join(ids, ","))
+	}
+	want := []string{"new:1,5", "in progress:3", "review:", "closed:2", "blocked:4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns() = %v, want %v", got, want)
+	}
+}
+
+func TestModelBoard(t *testing.T) {
+	m := testModel()
+	m.HandleKey(Key{Name: "tab"})
+	if m.view != ViewBoard {
+		t.Fatal("expected tab to show the board")
+	}
+
+	// Moving right skips the empty review column
+	press(m, "j")
+	press(m, "ll")
+	if selected, _ := m.Selected(); selected.ID != 2 {
+		t.Errorf("expected ticket 2 in the closed column, got %d", selected.ID)
+	}
+	press(m, "hh")
+	if selected, _ := m.Selected(); selected.ID != 1 {
+		t.Errorf("expected ticket 1 back in the new column, got %d", selected.ID)
+	}
+
+	action := press(m, "]")
+	want := Action{Kind: ActionStatus, TicketID: 1, Value: "in progress"}
+	if action != want {
+		t.Errorf("] returned %+v, want %+v", action, want)
+	}
+	if action := press(m, "["); action.Kind != ActionNone {
+		t.Errorf("[ in the first column returned %+v", action)
+	}
+
+	m.HandleKey(Key{Name: "enter"})
+	if m.view != ViewList {
+		t.Error("expected enter to show the selected ticket in the list")
+	}
+}
+
+func TestModelPrompts(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []Key
+		want Action
+	}{
+		{"status", append(runes("s"), append(backspaces(3), runes("review")...)...), Action{Kind: ActionStatus, TicketID: 1, Value: "review"}},
+		{"priority", append(runes("p"), append(backspaces(1), runes("5")...)...), Action{Kind: ActionPriority, TicketID: 1, Value: "5"}},
+		{"severity", append(runes("v"), append(backspaces(1), runes("2")...)...), Action{Kind: ActionSeverity, TicketID: 1, Value: "2"}},
+		{"add label", runes("abug"), Action{Kind: ActionAddLabel, TicketID: 1, Value: "bug"}},
+		{"empty", runes("a"), Action{}},
+	}
+	for _, test := range tests {
+		m := testModel()
+		for _, key := range test.keys {
+			m.HandleKey(key)
+		}
+		got := m.HandleKey(Key{Name: "enter"})
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+		if m.prompt != nil {
+			t.Errorf("%s: the prompt is still shown", test.name)
+		}
+	}
+
+	// Escape cancels a prompt, and the only label is suggested for removal
+	m := testModel()
+	press(m, "jj")
+	press(m, "d")
+	if m.prompt == nil || m.prompt.input != "docs" {
+		t.Fatalf("expected the docs label to be suggested, got %+v", m.prompt)
+	}
+	m.HandleKey(Key{Name: "esc"})
+	if m.prompt != nil {
+		t.Error("expected escape to cancel the prompt")
+	}
+	if action := press(m, "c"); action != (Action{Kind: ActionComment, TicketID: 3}) {
+		t.Errorf("c returned %+v", action)
+	}
+	if action := press(m, "q"); action.Kind != ActionQuit {
+		t.Errorf("q returned %+v", action)
+	}
+}
+
+// runes returns a key for each character of s
+func runes(s string) []Key {
+	var keys []Key
+	for _, r := range s {
+		keys = append(keys, Key{Name: "rune", Rune: r})
+	}
+	return keys
+}
+
+// backspaces returns n backspace keys
+func backspaces(n int) []Key {
+	var keys []Key
+	for i := 0; i < n; i++ {
+		keys = append(keys, Key{Name: "backspace"})
+	}
+	return keys
+}
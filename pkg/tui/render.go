@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+const (
+	ansiReverse = "\033[7m"
+	ansiReset   = "\033[0m"
+)
+
+// listPaneWidth is the widest the ticket list is next to the detail pane
+const listPaneWidth = 50
+
+// minColumnWidth is the narrowest a column of the board is drawn, the board
+// scrolls sideways when there isn't room for every column
+const minColumnWidth = 18
+
+// helpLine lists the keys that can be used, shown at the bottom of the screen
+const helpLine = "j/k move  h/l column  tab board  f filter  s status  p priority  v severity  a/d label  c comment  [/] move ticket  r reload  q quit"
+
+// Render returns the screen as width by height lines of text, each exactly
+// width characters wide not counting the escape codes highlighting the
+// selected ticket
+func (m *Model) Render(width int, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	view := "List"
+	if m.view == ViewBoard {
+		view = "Board"
+	}
+	filter := m.FilterName()
+	if filter == "" {
+		filter = "all tickets"
+	}
+	lines := []string{highlight(fit(" giticket | "+view+" | Filter: "+filter+" | "+strconv.Itoa(len(m.visible))+" tickets", width))}
+
+	bodyHeight := height - 3
+	if m.view == ViewBoard {
+		lines = append(lines, m.renderBoard(width, bodyHeight)...)
+	} else {
+		lines = append(lines, m.renderList(width, bodyHeight)...)
+	}
+
+	lines = append(lines, fit(" "+m.message, width))
+	if m.prompt != nil {
+		lines = append(lines, fit(" "+m.prompt.label+": "+m.prompt.input+"_", width))
+	} else {
+		lines = append(lines, fit(" "+helpLine, width))
+	}
+	return lines[:height]
+}
+
+// renderList returns the list of tickets next to the details of the selected
+// one
+func (m *Model) renderList(width int, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	listWidth := min(listPaneWidth, width*2/5)
+	detailWidth := width - listWidth - 1
+
+	selected := 0
+	for i, t := range m.visible {
+		if t.ID == m.selectedID {
+			selected = i
+		}
+	}
+	offset := max(0, selected-height+1)
+
+	var detail []string
+	if t, ok := m.Selected(); ok {
+		var b bytes.Buffer
+		ticket.ShowTicketText(&b, t, m.tickets, true, false)
+		for _, line := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+			detail = append(detail, wrap(line, detailWidth)...)
+		}
+	}
+
+	lines := make([]string, height)
+	for row := range lines {
+		left := ""
+		if i := offset + row; i < len(m.visible) {
+			t := m.visible[i]
+			left = fit(fmt.Sprintf(" %4d %-12s %s", t.ID, fit(t.Status, 12), t.Title), listWidth)
+			if t.ID == m.selectedID {
+				left = highlight(left)
+			}
+		} else {
+			left = fit("", listWidth)
+		}
+		right := ""
+		if row < len(detail) {
+			right = detail[row]
+		}
+		lines[row] = left + "|" + fit(right, detailWidth)
+	}
+	if len(m.visible) == 0 {
+		lines[0] = fit(" No tickets", width)
+	}
+	return lines
+}
+
+// renderBoard returns the board, a column of tickets for each status. When
+// the columns don't fit they scroll sideways to keep the selected ticket's
+// column shown.
+func (m *Model) renderBoard(width int, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	columns := m.Columns()
+	selectedColumn, selectedRow := m.selectedPosition(columns)
+
+	shown := max(1, min(len(columns), width/minColumnWidth))
+	first := 0
+	if selectedColumn >= shown {
+		first = selectedColumn - shown + 1
+	}
+	columns = columns[first:min(first+shown, len(columns))]
+	columnWidth := width / len(columns)
+
+	lines := make([]string, height)
+	for c, column := range columns {
+		cellWidth := columnWidth - 1
+		if c == len(columns)-1 {
+			// The last column takes up whatever width is left over
+			cellWidth = width - columnWidth*(len(columns)-1)
+		}
+		offset := 0
+		if c+first == selectedColumn {
+			offset = max(0, selectedRow-(height-2)+1)
+		}
+		for row := range lines {
+			var cell string
+			switch {
+			case row == 0:
+				cell = fit(" "+column.Status+" ("+strconv.Itoa(len(column.Tickets))+")", cellWidth)
+			case row == 1:
+				cell = strings.Repeat("-", cellWidth)
+			case offset+row-2 < len(column.Tickets):
+				t := column.Tickets[offset+row-2]
+				cell = fit(" #"+strconv.Itoa(t.ID)+" "+t.Title, cellWidth)
+				if t.ID == m.selectedID {
+					cell = highlight(cell)
+				}
+			default:
+				cell = fit("", cellWidth)
+			}
+			if c < len(columns)-1 {
+				cell += "|"
+			}
+			lines[row] += cell
+		}
+	}
+	return lines
+}
+
+// fit shortens or pads s with spaces to exactly width characters
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// wrap splits a line of text into lines of at most width characters, keeping
+// the line's indentation on each line it is split into
+func wrap(line string, width int) []string {
+	runes := []rune(strings.ReplaceAll(line, "\t", "    "))
+	if width <= 0 || len(runes) <= width {
+		return []string{string(runes)}
+	}
+	indent := len(runes) - len([]rune(strings.TrimLeft(string(runes), " ")))
+	if indent > width/2 {
+		indent = 0
+	}
+	lines := []string{string(runes[:width])}
+	for rest := runes[width:]; len(rest) > 0; {
+		n := min(len(rest), width-indent)
+		lines = append(lines, strings.Repeat(" ", indent)+string(rest[:n]))
+		rest = rest[n:]
+	}
+	return lines
+}
+
+// highlight shows s in reverse video
+func highlight(s string) string {
+	return ansiReverse + s + ansiReset
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"héllo", 5, "héllo"},
+		{"abc", 0, ""},
+	}
+	for _, test := range tests {
+		if got := fit(test.s, test.width); got != test.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", test.s, test.width, got, test.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := wrap("    abcdefghij", 8)
+	want := []string{"    abcd", "    efgh", "    ij"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrap() = %q, want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	m := testModel()
+	for _, view := range []string{ViewList, ViewBoard} {
+		m.view = view
+		for _, size := range [][2]int{{100, 20}, {40, 6}, {20, 2}} {
+			lines := m.Render(size[0], size[1])
+			if len(lines) != size[1] {
+				t.Errorf("%s %v: got %d lines, want %d", view, size, len(lines), size[1])
+			}
+			for i, line := range lines {
+				plain := strings.NewReplacer(ansiReverse, "", ansiReset, "").Replace(line)
+				if n := utf8.RuneCountInString(plain); n != size[0] {
+					t.Errorf("%s %v: line %d is %d wide, want %d: %q", view, size, i, n, size[0], plain)
+				}
+			}
+		}
+	}
+
+	m.view = ViewList
+	screen := strings.Join(m.Render(100, 20), "\n")
+	for _, want := range []string{"Filter: all tickets | 5 tickets", "Title: Reverse the polarity", highlight(fit("    1 new          Reverse the polarity", 40))} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("the list doesn't contain %q:\n%s", want, screen)
+		}
+	}
+
+	m.view = ViewBoard
+	screen = strings.Join(m.Render(100, 20), "\n")
+	for _, want := range []string{" new (2)", " in progress (1)", " #5 Invert the flow"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("the board doesn't contain %q:\n%s", want, screen)
+		}
+	}
+
+	press(m, "s")
+	lines := m.Render(100, 20)
+	if !strings.HasPrefix(lines[19], " Status: new_") {
+		t.Errorf("expected the prompt on the last line, got %q", lines[19])
+	}
+}
@@ -0,0 +1,212 @@
+// Package tui implements giticket's interactive terminal UI: a list of
+// tickets next to the details of the selected one, and a board with a column
+// for each status. Changes are made with the same handlers the CLI uses, so
+// every action is the same commit the equivalent subcommand would make.
+package tui
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/debug"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+	"golang.org/x/term"
+)
+
+// Escape codes to switch to the terminal's alternate screen, so the UI
+// doesn't scroll away what was on the screen before it started, and to hide
+// the cursor
+const (
+	enterScreen = "\033[?1049h\033[?25l"
+	leaveScreen = "\033[?25h\033[?1049l"
+	homeCursor  = "\033[H"
+)
+
+// Run takes a debug flag and runs the terminal UI until the user quits.
+// Debug messages are printed over the UI, so are only useful for finding out
+// why it fails to start. Returns an error if there is one.
+func Run(debugFlag bool) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return errors.New("giticket tui needs to be run in a terminal")
+	}
+
+	m, err := loadModel(debugFlag)
+	if err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	os.Stdout.WriteString(enterScreen)
+	defer func() {
+		os.Stdout.WriteString(leaveScreen)
+		term.Restore(in, state)
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			return err
+		}
+		os.Stdout.WriteString(homeCursor + strings.Join(m.Render(width, height), "\r\n"))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			action := m.HandleKey(key)
+			switch action.Kind {
+			case ActionNone:
+				continue
+			case ActionQuit:
+				return nil
+			case ActionComment:
+				// The editor needs the terminal as it was before the UI
+				// started
+				os.Stdout.WriteString(leaveScreen)
+				term.Restore(in, state)
+				action.Value, err = ticket.EditText("", "Write your comment for ticket "+strconv.Itoa(action.TicketID)+" above.\nAn empty comment aborts.", debugFlag)
+				state, _ = term.MakeRaw(in)
+				os.Stdout.WriteString(enterScreen)
+				if err != nil {
+					m.SetMessage(err.Error())
+					continue
+				}
+			}
+
+			message, err := Execute(action, debugFlag)
+			if err != nil {
+				message = err.Error()
+			}
+			tickets, err := loadTickets(debugFlag)
+			if err != nil {
+				return err
+			}
+			m.SetTickets(tickets)
+			m.SetMessage(message)
+		}
+	}
+}
+
+// Execute makes the change to a ticket an Action asks for with the same
+// handler as the equivalent subcommand, and returns a message describing what
+// was done. Returns an error if the change couldn't be made.
+func Execute(action Action, debugFlag bool) (string, error) {
+	id := strconv.Itoa(action.TicketID)
+	switch action.Kind {
+	case ActionReload:
+		return "Reloaded the tickets", nil
+	case ActionStatus:
+		return "Set the status of ticket " + id + " to " + action.Value, ticket.HandleStatus(action.Value, action.TicketID, false, debugFlag)
+	case ActionPriority, ActionSeverity:
+		value, err := strconv.Atoi(action.Value)
+		if err != nil {
+			return "", errors.New("the " + action.Kind + " must be a number, not '" + action.Value + "'")
+		}
+		if action.Kind == ActionPriority {
+			return "Set the priority of ticket " + id + " to " + action.Value, ticket.HandlePriority(action.TicketID, value, debugFlag)
+		}
+		return "Set the severity of ticket " + id + " to " + action.Value, ticket.HandleSeverity(action.TicketID, value, debugFlag)
+	case ActionAddLabel:
+		return "Added the label " + action.Value + " to ticket " + id, ticket.HandleLabel(common.BranchName, action.Value, false, action.TicketID, debugFlag)
+	case ActionRemoveLabel:
+		return "Removed the label " + action.Value + " from ticket " + id, ticket.HandleLabel(common.BranchName, action.Value, true, action.TicketID, debugFlag)
+	case ActionComment:
+		commentID, err := ticket.HandleComment(common.BranchName, action.Value, 0, action.TicketID, false, debugFlag)
+		return "Added comment " + commentID + " to ticket " + id, err
+	}
+	return "", errors.New("unknown action '" + action.Kind + "'")
+}
+
+// loadModel reads the tickets, the saved filters, the current filter, and the
+// workflow statuses from the settings, and returns a Model for them
+func loadModel(debugFlag bool) (*Model, error) {
+	tickets, err := loadTickets(debugFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []ticket.Filter
+	filterList, err := ticket.GetFilters(common.BranchName, debugFlag)
+	if err != nil && !git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		for _, f := range filterList.Filters {
+			filters = append(filters, f)
+		}
+	}
+
+	debug.DebugMessage(debugFlag, "Opening git repository")
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return nil, err
+	}
+	settings, err := ticket.LoadSettings(thisRepo, common.BranchName, debugFlag)
+	if err != nil {
+		return nil, err
+	}
+	workflow := []string{"new", ticket.DefaultStartStatus, ticket.DefaultReviewStatus, "closed"}
+	if settings.StartStatus != "" {
+		workflow[1] = settings.StartStatus
+	}
+	if settings.ReviewStatus != "" {
+		workflow[2] = settings.ReviewStatus
+	}
+
+	return NewModel(tickets, filters, settings.CurrentFilter, workflow), nil
+}
+
+// loadTickets reads every ticket
+func loadTickets(debugFlag bool) ([]ticket.Ticket, error) {
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		return nil, err
+	}
+	return ticket.GetListOfTickets(thisRepo, common.BranchName, debugFlag)
+}
+
+// ParseKeys takes bytes read from a terminal in raw mode and returns the keys
+// they are for. Arrow keys are sent as escape sequences, which are recognized
+// when a whole sequence is read at once.
+func ParseKeys(b []byte) []Key {
+	arrows := map[string]string{"\033[A": "up", "\033[B": "down", "\033[C": "right", "\033[D": "left", "\033OA": "up", "\033OB": "down", "\033OC": "right", "\033OD": "left"}
+	controls := map[byte]string{3: "ctrl-c", '\t': "tab", '\r': "enter", '\n': "enter", 127: "backspace", 8: "backspace"}
+
+	var keys []Key
+	for len(b) > 0 {
+		if b[0] == 033 {
+			if len(b) >= 3 {
+				if name, ok := arrows[string(b[:3])]; ok {
+					keys = append(keys, Key{Name: name})
+					b = b[3:]
+					continue
+				}
+			}
+			keys = append(keys, Key{Name: "esc"})
+			b = b[1:]
+			continue
+		}
+		if name, ok := controls[b[0]]; ok {
+			keys = append(keys, Key{Name: name})
+			b = b[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(b)
+		if r >= ' ' && r != utf8.RuneError {
+			keys = append(keys, Key{Name: "rune", Rune: r})
+		}
+		b = b[size:]
+	}
+	return keys
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	git "github.com/jeffwelling/git2go/v37"
+	"github.com/jeffwelling/giticket/pkg/common"
+	"github.com/jeffwelling/giticket/pkg/repo"
+	"github.com/jeffwelling/giticket/pkg/ticket"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := ParseKeys([]byte("j\033[A\033[D\033x\r\t\x7f\x03é"))
+	want := []Key{
+		{Name: "rune", Rune: 'j'},
+		{Name: "up"},
+		{Name: "left"},
+		{Name: "esc"},
+		{Name: "rune", Rune: 'x'},
+		{Name: "enter"},
+		{Name: "tab"},
+		{Name: "backspace"},
+		{Name: "ctrl-c"},
+		{Name: "rune", Rune: 'é'},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeys() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHandleExecute(t *testing.T) {
+	common.UseTempDir(t)
+	err := repo.InitGitAndInitGiticket(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range []Action{
+		{Kind: ActionStatus, TicketID: 1, Value: "in progress"},
+		{Kind: ActionPriority, TicketID: 1, Value: "3"},
+		{Kind: ActionSeverity, TicketID: 1, Value: "4"},
+		{Kind: ActionAddLabel, TicketID: 1, Value: "tardis"},
+		{Kind: ActionComment, TicketID: 1, Value: "Polarity reversed"},
+	} {
+		_, err = Execute(action, true)
+		if err != nil {
+			t.Fatalf("%+v: %s", action, err)
+		}
+	}
+	_, err = Execute(Action{Kind: ActionPriority, TicketID: 1, Value: "high"}, true)
+	if err == nil {
+		t.Error("expected an error for a priority that isn't a number")
+	}
+
+	thisRepo, err := git.OpenRepository(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets, err := ticket.GetListOfTickets(thisRepo, common.BranchName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ticket.FilterTicketsByID(tickets, 1)
+	if got.Status != "in progress" || got.Priority != 3 || got.Severity != 4 || len(got.Comments) != 1 {
+		t.Errorf("unexpected ticket after the actions: %+v", got)
+	}
+	found := false
+	for _, label := range got.Labels {
+		found = found || label == "tardis"
+	}
+	if !found {
+		t.Errorf("expected the tardis label, got %v", got.Labels)
+	}
+}